@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// breakerThreshold 同一账号连续因登录失效、安全验证失败的任务数，达到后熔断
+const breakerThreshold = 3
+
+// breakerCategories 计入熔断的失败分类：连续登录失效或出现安全验证时继续操作更像异常行为，容易使账号被风控
+var breakerCategories = []string{ErrorCategoryLoginExpired, ErrorCategoryCaptcha}
+
+// accountBreaker 账号熔断：同一账号连续 breakerThreshold 个任务因登录失效、安全验证失败时，
+// 不再执行该账号剩余的任务并发送通知，其他账号不受影响；为 nil 时不熔断
+type accountBreaker struct {
+	mu       sync.Mutex
+	failures map[string][]TaskResult // 账号 -> 连续计入熔断的失败
+	open     map[string]bool         // 已熔断的账号
+}
+
+// newAccountBreaker 创建熔断器，队列消费模式下在各消息之间共用
+func newAccountBreaker() *accountBreaker {
+	return &accountBreaker{failures: make(map[string][]TaskResult), open: make(map[string]bool)}
+}
+
+// Record 记录任务结果：成功或其他分类的失败时清零，连续失败达到次数时熔断该账号、记录日志并发送通知，
+// 返回本次是否熔断
+func (b *accountBreaker) Record(result TaskResult, notifier *Notifications) bool {
+	if b == nil {
+		return false
+	}
+	account := resultAccount(result)
+	b.mu.Lock()
+	if b.open[account] {
+		b.mu.Unlock()
+		return false
+	}
+	if result.Success || !slices.Contains(breakerCategories, result.ErrorCategory) {
+		delete(b.failures, account)
+		b.mu.Unlock()
+		return false
+	}
+	b.failures[account] = append(b.failures[account], result)
+	failures := b.failures[account]
+	if len(failures) < breakerThreshold {
+		b.mu.Unlock()
+		return false
+	}
+	b.open[account] = true
+	b.mu.Unlock()
+
+	log.Printf("🔌 账号 %s 连续 %d 个任务登录失效或出现安全验证，已熔断，跳过该账号剩余的任务", account, len(failures))
+	notifier.Notify(breakerEvent(account, failures))
+	return true
+}
+
+// Err 账号已熔断时返回跳过任务的错误
+func (b *accountBreaker) Err(account string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open[account] {
+		return nil
+	}
+	return fmt.Errorf("账号 %s 已熔断（连续 %d 个任务失败），跳过该账号剩余的任务", account, breakerThreshold)
+}
+
+// breakerEvent 账号熔断通知，附带导致熔断的失败任务
+func breakerEvent(account string, failures []TaskResult) NotifyEvent {
+	var lines []string
+	for _, result := range failures {
+		lines = append(lines, fmt.Sprintf("第%d行 [%s]: %s", result.Task.RowIndex, result.ErrorCategory, result.Error))
+	}
+	return NotifyEvent{
+		Type:    EventOnAccountBreaker,
+		Title:   fmt.Sprintf("账号 %s 已熔断，剩余任务不再执行", account),
+		Message: strings.Join(lines, "\n"),
+		Results: failures,
+	}
+}
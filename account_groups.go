@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// accountColumn 账号列的表头，放在J列之后：填写视频号名称，一次运行为多个账号上传；
+// 为空时使用 -login 登录的账号
+const accountColumn = "账号"
+
+// defaultAccountAuthDir 各账号认证状态文件的默认目录
+const defaultAccountAuthDir = "auth"
+
+// taskAccount 任务指定的账号，没有填写账号列时为空
+func taskAccount(task VideoCreateTask) string {
+	return strings.TrimSpace(task.Account)
+}
+
+// hasTaskAccounts 是否有任务填写了账号列
+func hasTaskAccounts(tasks []VideoCreateTask) bool {
+	for _, task := range tasks {
+		if taskAccount(task) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// needsDefaultLogin 是否有任务没有填写账号列，需要 -login 登录的账号
+func needsDefaultLogin(tasks []VideoCreateTask) bool {
+	for _, task := range tasks {
+		if taskAccount(task) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTasksByAccount 按账号列分组后对每个账号的任务执行 filter（如对比该账号的上传历史和发表配额），
+// 未填写账号的任务归入 defaultAccount；返回的任务按账号首次出现的顺序排列
+func filterTasksByAccount(tasks []VideoCreateTask, defaultAccount string, filter func(account string, tasks []VideoCreateTask) []VideoCreateTask) []VideoCreateTask {
+	var accounts []string
+	byAccount := make(map[string][]VideoCreateTask)
+	for _, task := range tasks {
+		account := taskAccount(task)
+		if account == "" {
+			account = defaultAccount
+		}
+		if _, ok := byAccount[account]; !ok {
+			accounts = append(accounts, account)
+		}
+		byAccount[account] = append(byAccount[account], task)
+	}
+	var kept []VideoCreateTask
+	for _, account := range accounts {
+		kept = append(kept, filter(account, byAccount[account])...)
+	}
+	return kept
+}
+
+// accountGroup 同一账号的任务在 results 中的序号，Account 为空时为 -login 登录的账号
+type accountGroup struct {
+	Account string
+	Indexes []int
+}
+
+// groupResultsByTaskAccount 按账号列把任务序号分组，账号按首次出现的顺序返回
+func groupResultsByTaskAccount(results []TaskResult) []accountGroup {
+	var groups []accountGroup
+	groupOf := make(map[string]int)
+	for i, result := range results {
+		account := taskAccount(result.Task)
+		if g, ok := groupOf[account]; ok {
+			groups[g].Indexes = append(groups[g].Indexes, i)
+			continue
+		}
+		groupOf[account] = len(groups)
+		groups = append(groups, accountGroup{Account: account, Indexes: []int{i}})
+	}
+	return groups
+}
+
+// AccountLogins 按账号列分组上传时各账号的登录：认证状态保存在 Dir/<账号>.json（按 auth.key 加密，或 auth.plaintext 明文），
+// 未过期时直接使用，否则扫码登录后保存，下次运行不再扫码
+type AccountLogins struct {
+	Dir     string       // 各账号认证状态文件的目录
+	Options LoginOptions // 扫码方式、通知、加密密钥等，认证状态文件按账号设置
+	Proxies *ProxyPool   // 按账号固定代理时每个账号登录和上传使用自己的代理
+}
+
+// Proxy 按账号固定代理时该账号使用的代理，按任务轮换或没有代理池时为空
+func (a *AccountLogins) Proxy(account string) (*playwright.Proxy, error) {
+	if a.Proxies.PerTask() {
+		return nil, nil
+	}
+	return a.Proxies.ForAccount(account)
+}
+
+// options 登录该账号的选项，proxy 不为空时登录浏览器使用该代理；-login=auth-file 时只读取该账号的认证状态文件，不扫码
+func (a *AccountLogins) options(account string, proxy *playwright.Proxy) LoginOptions {
+	options := a.Options
+	options.Account = account
+	if proxy != nil {
+		options.Browser.Proxy = proxy
+	}
+	options.AuthFile = filepath.Join(a.Dir, accountDirName(account)+".json")
+	if options.Mode != LoginModeAuthFile {
+		options.Mode = LoginModeQR
+	}
+	return options
+}
+
+// Login 登录该账号，扫码登录的视频号与账号列不一致时返回错误（扫错了微信），不保存该认证状态
+func (a *AccountLogins) Login(account string, proxy *playwright.Proxy) (*PageState, error) {
+	return a.login(account, a.options(account, proxy))
+}
+
+// Relogin 运行中该账号登录失效时重新扫码
+func (a *AccountLogins) Relogin(account string, proxy *playwright.Proxy) ReloginFunc {
+	return func() (*PageState, error) {
+		return a.login(account, a.options(account, proxy).relogin())
+	}
+}
+
+func (a *AccountLogins) login(account string, options LoginOptions) (*PageState, error) {
+	if err := os.MkdirAll(a.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建认证状态目录失败: %v", err)
+	}
+	log.Printf("🔑 登录账号 %s（认证状态文件: %s）", account, options.AuthFile)
+	authState, err := processUserLogin(options)
+	if err != nil {
+		return nil, fmt.Errorf("账号 %s 登录失败: %v", account, err)
+	}
+	if err := checkTaskAccount(account, authState.ChannelName); err != nil {
+		if options.Mode == LoginModeQR {
+			os.Remove(options.AuthFile)
+		}
+		return nil, err
+	}
+	return authState, nil
+}
+
+// checkTaskAccount 任务指定的账号与当前登录的视频号是否一致，没有读取到视频号名称时不检查
+func checkTaskAccount(account string, channelName string) error {
+	if account == "" || channelName == "" || account == strings.TrimSpace(channelName) {
+		return nil
+	}
+	return fmt.Errorf("任务的账号 %s 与当前登录的视频号 %s 不一致", account, channelName)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// unknownAccount 未获取到视频号名称时归入的账号
+const unknownAccount = "未知账号"
+
+// resultAccount 结果所属的账号（视频号名称），未获取到视频号名称时为账号列
+func resultAccount(result TaskResult) string {
+	if name := strings.TrimSpace(result.ChannelName); name != "" {
+		return name
+	}
+	if account := taskAccount(result.Task); account != "" {
+		return account
+	}
+	return unknownAccount
+}
+
+// groupResultsByAccount 按账号分组结果，账号按首次出现的顺序返回
+func groupResultsByAccount(results []TaskResult) ([]string, map[string][]TaskResult) {
+	var accounts []string
+	byAccount := make(map[string][]TaskResult)
+	for _, result := range results {
+		account := resultAccount(result)
+		if _, ok := byAccount[account]; !ok {
+			accounts = append(accounts, account)
+		}
+		byAccount[account] = append(byAccount[account], result)
+	}
+	return accounts, byAccount
+}
+
+// accountDirName 将账号名转换为可用的目录名
+func accountDirName(account string) string {
+	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+	name := strings.Trim(replacer.Replace(account), ". ")
+	if name == "" {
+		return "account"
+	}
+	return name
+}
+
+// WriteAccountReports 按账号拆分报告：每个账号在 outputDir/accounts/<账号>/ 下得到
+// 只包含自己任务的结果Excel、日志和附件（截图等），便于分别交付给各客户
+func WriteAccountReports(source *TaskSource, outputDir string, results []TaskResult) error {
+	accounts, byAccount := groupResultsByAccount(results)
+	for _, account := range accounts {
+		accountResults := byAccount[account]
+		dir := filepath.Join(outputDir, "accounts", accountDirName(account))
+
+		if _, err := writeResultsWorkbook(source, dir, accountResults, true); err != nil {
+			return fmt.Errorf("账号 %s: %v", account, err)
+		}
+		if err := writeAccountLog(dir, accountResults); err != nil {
+			return fmt.Errorf("账号 %s: %v", account, err)
+		}
+		copyAccountArtifacts(dir, accountResults)
+		log.Printf("📂 账号 %s 的报告已写入: %s", account, dir)
+	}
+	return nil
+}
+
+// writeAccountLog 写入账号自己的任务日志和汇总
+func writeAccountLog(dir string, results []TaskResult) error {
+	logDir := filepath.Join(dir, "log")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %v", err)
+	}
+	logFile, err := os.Create(filepath.Join(logDir, fmt.Sprintf("wechat_channel_uploader_%s.log",
+		time.Now().Format("20060102_150405"))))
+	if err != nil {
+		return fmt.Errorf("创建日志文件失败: %v", err)
+	}
+	defer logFile.Close()
+	defer logs.Flush(logFile)
+
+	for _, result := range results {
+		writeLogFile(logs.Writer(logFile), result)
+	}
+	writeLogSummary(logs.Writer(logFile), summarizeResults(results))
+	return nil
+}
+
+// copyAccountArtifacts 将任务附件复制到账号目录的 artifacts 下，单个附件失败只记录警告
+func copyAccountArtifacts(dir string, results []TaskResult) {
+	for _, result := range results {
+		for _, artifact := range result.Artifacts {
+			target := filepath.Join(dir, "artifacts", fmt.Sprintf("row%d_%s", result.Task.RowIndex, filepath.Base(artifact)))
+			if err := copyFile(artifact, target); err != nil {
+				log.Printf("⚠️ 复制附件失败: %v", err)
+			}
+		}
+	}
+}
+
+// copyFile 复制文件，自动创建目标目录
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// printAccountSummaries 多个账号时分别打印每个账号的汇总
+func printAccountSummaries(results []TaskResult) {
+	accounts, byAccount := groupResultsByAccount(results)
+	if len(accounts) < 2 {
+		return
+	}
+	for _, account := range accounts {
+		log.Printf("👤 ===== 账号: %s =====", account)
+		for _, line := range summarizeResults(byAccount[account]).Lines() {
+			log.Printf("📈 %s", line)
+		}
+	}
+}
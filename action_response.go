@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// actionResponseTimeout 等待发表、保存草稿接口响应的时间，与等待页面提示的时间相同
+const actionResponseTimeout = 300 * time.Second
+
+// actionAPIPatterns 最终操作调用的接口（URL 中包含的路径），手机预览没有对应的接口，只按页面提示判断
+var actionAPIPatterns = map[string][]string{
+	"publish":    {"/post/post_create"},
+	"save_draft": {"/post/post_draft_create", "/post/post_draft_update", "/post/draft_create"},
+}
+
+// actionResponse 最终操作接口的结果
+type actionResponse struct {
+	known   bool   // 接口返回了可识别的结果，为 false 时按页面提示判断
+	ok      bool   // 接口返回成功
+	message string // 失败时接口返回的错误
+}
+
+// expectActionResponse 在点击按钮前开始监听最终操作的接口响应，返回接收结果的通道
+func expectActionResponse(page playwright.Page, action string) <-chan actionResponse {
+	return expectAPIResponse(page, actionAPIPatterns[action], actionResponseTimeout)
+}
+
+// expectAPIResponse 开始监听 URL 包含 patterns 中任一路径的接口响应（page.ExpectResponse），
+// 返回接收结果的通道；没有路径、超时或页面关闭时通道关闭而不发送结果
+func expectAPIResponse(page playwright.Page, patterns []string, timeout time.Duration) <-chan actionResponse {
+	results := make(chan actionResponse, 1)
+	if len(patterns) == 0 {
+		close(results)
+		return results
+	}
+
+	// 等监听注册后再返回，避免操作后接口响应太快而错过
+	registered := make(chan struct{})
+	ready := sync.OnceFunc(func() { close(registered) })
+	go func() {
+		defer close(results)
+		defer ready()
+		response, err := page.ExpectResponse(func(url string) bool {
+			return containsAny(url, patterns)
+		}, func() error {
+			ready()
+			return nil
+		}, playwright.PageExpectResponseOptions{Timeout: playwright.Float(float64(timeout.Milliseconds()))})
+		if err != nil {
+			return
+		}
+		results <- parseActionResponse(response)
+	}()
+	<-registered
+	return results
+}
+
+// checkActionResponse 最多等待 wait 检查是否已收到接口结果（为 0 时不等待），收到可识别的结果时返回 true 和操作的错误
+func checkActionResponse(responses <-chan actionResponse, actionName string, wait time.Duration) (bool, error) {
+	var response actionResponse
+	if wait == 0 {
+		select {
+		case response = <-responses:
+		default:
+			return false, nil
+		}
+	} else {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case response = <-responses:
+		case <-timer.C:
+			return false, nil
+		}
+	}
+	if !response.known {
+		return false, nil
+	}
+	if !response.ok {
+		return true, fmt.Errorf("%s 操作失败: 接口返回 %s", actionName, response.message)
+	}
+	log.Printf("✅ %s 接口返回成功", actionName)
+	return true, nil
+}
+
+// containsAny url 是否包含任意一个路径
+func containsAny(url string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(url, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseActionResponse 按 HTTP 状态码和返回内容中的错误码（errCode、errcode、ret）判断接口是否成功，
+// 无法识别的返回内容按页面提示判断
+func parseActionResponse(response playwright.Response) actionResponse {
+	// 服务端错误时请求可能已处理，按页面提示判断
+	if status := response.Status(); status >= 500 {
+		log.Printf("⚠️ 接口返回 HTTP %d，按页面提示判断", status)
+		return actionResponse{}
+	} else if status >= 400 {
+		return actionResponse{known: true, message: fmt.Sprintf("HTTP %d", status)}
+	}
+	var body map[string]interface{}
+	if err := response.JSON(&body); err != nil {
+		log.Printf("⚠️ 无法解析接口返回内容，按页面提示判断: %v", err)
+		return actionResponse{}
+	}
+	for _, key := range []string{"errCode", "errcode", "ret"} {
+		code, ok := body[key].(float64)
+		if !ok {
+			continue
+		}
+		if code == 0 {
+			return actionResponse{known: true, ok: true}
+		}
+		message := fmt.Sprintf("%s=%v", key, code)
+		for _, field := range []string{"errMsg", "errmsg", "msg"} {
+			if text, ok := body[field].(string); ok && text != "" {
+				message += " " + text
+				break
+			}
+		}
+		return actionResponse{known: true, message: message}
+	}
+	log.Println("⚠️ 接口返回内容中没有错误码，按页面提示判断")
+	return actionResponse{}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// runAuthCommand 处理 auth 子命令：login 扫码登录并保存认证状态，check 检查已保存的认证状态是否可用
+func runAuthCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: auth login|check [-auth-file=auth.json]")
+	}
+	switch args[0] {
+	case "login":
+		return runAuthLogin(args[1:])
+	case "check":
+		return runAuthCheck(args[1:])
+	default:
+		return fmt.Errorf("未知的 auth 操作: %s (可选 login/check)", args[0])
+	}
+}
+
+// runAuthLogin 扫码登录并把认证状态保存到文件
+func runAuthLogin(args []string) error {
+	fs := flag.NewFlagSet("auth login", flag.ExitOnError)
+	authFile := fs.String("auth-file", "auth.json", "认证状态文件路径")
+	container := fs.Bool("container", false, "容器模式运行(使用镜像内置浏览器, 通过HTTP扫码)")
+	qrAddr := fs.String("qr-addr", ":8080", "容器模式或 -headless-login 时扫码页面的监听地址")
+	qrFile := fs.String("qr-file", "", "把二维码写入该 PNG 文件")
+	headlessLogin := fs.Bool("headless-login", false, "无头模式扫码登录, 二维码通过 -qr-addr 扫码页面和 -qr-file 提供")
+	configPath := fs.String("config", "", "配置文件路径，auth.key 为加密认证状态的密钥，不加密时需设置 auth.plaintext: true")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	// 扫码之前检查，避免扫码后才发现无法保存
+	if err := config.Auth.checkSave(); err != nil {
+		return err
+	}
+
+	options := LoginOptions{Browser: BrowserOptions{Headless: false}, QRFile: *qrFile}
+	if *headlessLogin {
+		options.Browser.Headless = true
+		options.QRAddr = *qrAddr
+	}
+	if *container {
+		options.Browser = BrowserOptions{Headless: true, Container: true}
+		options.QRAddr = *qrAddr
+	} else if err := isPlaywrightInstalled(); err != nil {
+		return fmt.Errorf("环境初始化失败: %v", err)
+	}
+	authState, err := processUserLogin(options)
+	if err != nil {
+		return err
+	}
+	if err := SaveAuthStateFile(*authFile, authState, config.Auth); err != nil {
+		return err
+	}
+	log.Printf("✅ 认证状态已保存到: %s", *authFile)
+	return nil
+}
+
+// runAuthCheck 加载已保存的认证状态，打开视频号首页和创建页面，报告视频号名称、cookie 剩余有效期和创建页面是否可用；
+// 需要重新登录时返回错误（退出码非0），可作为定时任务的运行前检查
+func runAuthCheck(args []string) error {
+	fs := flag.NewFlagSet("auth check", flag.ExitOnError)
+	authFile := fs.String("auth-file", "auth.json", "认证状态文件路径")
+	minValid := fs.Duration("min-valid", 0, "cookie 剩余有效期低于该时长时视为需要重新登录(例如 12h)")
+	headless := fs.Bool("headless", true, "无头模式运行浏览器")
+	container := fs.Bool("container", false, "容器模式运行(使用镜像内置浏览器)")
+	configPath := fs.String("config", "", "配置文件路径，认证状态文件已加密时读取 auth.key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	authState, err := LoadAuthStateFile(*authFile, config.Auth.Key)
+	if err != nil {
+		return err
+	}
+	log.Printf("📁 认证状态文件: %s (Cookies=%d个)", *authFile, len(authState.Cookies))
+
+	// 1. cookie 剩余有效期（会话 cookie 没有过期时间，不参与计算）
+	name, expiresAt, ok := earliestCookieExpiry(authState.Cookies)
+	if ok {
+		remaining := time.Until(expiresAt)
+		if remaining <= 0 {
+			return fmt.Errorf("需要重新登录: cookie %s 已于 %s 过期", name, expiresAt.Format("2006/01/02 15:04"))
+		}
+		log.Printf("🍪 最早过期的 cookie: %s，过期时间 %s，剩余 %v",
+			name, expiresAt.Format("2006/01/02 15:04"), remaining.Round(time.Minute))
+		if remaining < *minValid {
+			return fmt.Errorf("需要重新登录: cookie %s 剩余有效期 %v 低于 %v", name, remaining.Round(time.Minute), *minValid)
+		}
+	} else {
+		log.Println("🍪 认证状态中没有带过期时间的 cookie")
+	}
+
+	// 2. 打开视频号首页，确认登录状态并读取视频号名称
+	if !*container {
+		if err := isPlaywrightInstalled(); err != nil {
+			return fmt.Errorf("环境初始化失败: %v", err)
+		}
+	}
+	pw, browser, context, err := GenerateBrowser(BrowserOptions{Headless: *headless, Container: *container})
+	if err != nil {
+		return err
+	}
+	defer pw.Stop()
+	defer (*browser).Close()
+	defer (*context).Close()
+	restoreAuthState(*context, authState)
+
+	channelName, err := checkHomePage(*context)
+	if err != nil {
+		return err
+	}
+	if channelName != "" {
+		log.Printf("✅ 已登录视频号: %s", channelName)
+	}
+	if authState.ChannelName != "" && channelName != "" && channelName != authState.ChannelName {
+		log.Printf("⚠️ 当前视频号(%s)与保存时的视频号(%s)不一致", channelName, authState.ChannelName)
+	}
+
+	// 3. 创建页面是否可用
+	page, _, err := GeneratePage(context, PageOptions{})
+	if page != nil {
+		defer (*page).Close()
+	}
+	if err != nil {
+		if classifyError(err.Error()) == ErrorCategoryLoginExpired {
+			return fmt.Errorf("需要重新登录: %v", err)
+		}
+		return fmt.Errorf("创建页面不可用: %v", err)
+	}
+	log.Println("✅ 创建页面可用，认证状态有效")
+	return nil
+}
+
+// checkHomePage 打开视频号首页，跳转到登录页说明登录已失效，否则返回视频号名称
+func checkHomePage(context playwright.BrowserContext) (string, error) {
+	page, err := context.NewPage()
+	if err != nil {
+		return "", fmt.Errorf("创建页面失败: %v", err)
+	}
+	defer page.Close()
+
+	if err := navigateWithRetry(page, platformURLs.HomePage()); err != nil {
+		return "", fmt.Errorf("打开视频号首页失败: %v", err)
+	}
+	// 登录失效时页面加载后才会跳转到登录页
+	time.Sleep(3 * time.Second)
+	if isLoginURL(page.URL()) {
+		return "", fmt.Errorf("需要重新登录: 打开首页后跳转到了登录页 %s", page.URL())
+	}
+	return getCurrentChannelName(page), nil
+}
+
+// earliestCookieExpiry 返回最早过期的持久 cookie 的名称和过期时间
+func earliestCookieExpiry(cookies []playwright.Cookie) (string, time.Time, bool) {
+	var (
+		name     string
+		earliest time.Time
+		found    bool
+	)
+	for _, cookie := range cookies {
+		if cookie.Expires <= 0 {
+			continue
+		}
+		expiresAt := time.Unix(int64(cookie.Expires), 0)
+		if !found || expiresAt.Before(earliest) {
+			name, earliest, found = cookie.Name, expiresAt, true
+		}
+	}
+	return name, earliest, found
+}
+
+// SaveAuthStateFile 将认证状态写入文件，配置了 auth.key 时加密，否则需要 auth.plaintext 允许明文保存；
+// 文件包含登录凭据，仅当前用户可读写
+func SaveAuthStateFile(path string, authState *PageState, auth AuthConfig) error {
+	if err := auth.checkSave(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(authState, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化认证状态失败: %v", err)
+	}
+	if auth.Key != "" {
+		if data, err = encryptAuthState(data, auth.Key); err != nil {
+			return fmt.Errorf("加密认证状态失败: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("保存认证状态文件失败: %v", err)
+	}
+	return nil
+}
+
+// LoadAuthStateFile 读取认证状态文件，文件已加密时用 key 解密
+func LoadAuthStateFile(path string, key string) (*PageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取认证状态文件失败: %v", err)
+	}
+	if data, err = decryptAuthState(data, key); err != nil {
+		return nil, err
+	}
+	var authState PageState
+	if err := json.Unmarshal(data, &authState); err != nil {
+		return nil, fmt.Errorf("认证状态文件格式错误: %v", err)
+	}
+	if len(authState.Cookies) == 0 {
+		return nil, fmt.Errorf("需要重新登录: 认证状态文件中没有 cookie")
+	}
+	return &authState, nil
+}
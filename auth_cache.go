@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// 认证状态文件的加密方式：密钥经 PBKDF2-SHA256 派生为 AES-256-GCM 密钥
+const (
+	authEncryption     = "aes-256-gcm"
+	authKDF            = "pbkdf2-sha256"
+	authKDFIterations  = 200000
+	authKDFMinimum     = 100000   // 读取文件时接受的迭代次数范围，过小的次数削弱密钥派生，
+	authKDFMaximum     = 10000000 // 过大的次数会让解密长时间占用 CPU
+	authSaltSize       = 16
+	defaultAuthMinimum = time.Hour
+)
+
+// AuthConfig 认证状态文件的加密和有效期（配置文件 auth）
+type AuthConfig struct {
+	Key       string        `yaml:"key"`       // 加密认证状态文件的密钥，建议写 ${UPLOADER_AUTH_KEY} 从环境变量或 env_file 读取
+	Plaintext bool          `yaml:"plaintext"` // 不设置密钥、明文保存认证状态文件，需要显式开启
+	MinValid  time.Duration `yaml:"min_valid"` // 扫码登录时 cookie 剩余有效期低于该时长视为过期、重新扫码，默认 1h
+}
+
+// Validate 检查认证状态文件配置
+func (c AuthConfig) Validate() error {
+	if c.Key != "" && c.Plaintext {
+		return fmt.Errorf("key 和 plaintext 不能同时设置")
+	}
+	if c.MinValid < 0 {
+		return fmt.Errorf("min_valid 不能为负数")
+	}
+	return nil
+}
+
+// checkSave 保存认证状态文件前检查：文件包含登录凭据，没有密钥时需要 plaintext 显式允许明文保存
+func (c AuthConfig) checkSave() error {
+	if c.Key == "" && !c.Plaintext {
+		return fmt.Errorf("认证状态文件包含登录凭据，需要在配置文件 auth.key 中设置密钥（-config 指定配置文件），或设置 auth.plaintext: true 明文保存")
+	}
+	return nil
+}
+
+// minValid cookie 至少还需有效的时长
+func (c AuthConfig) minValid() time.Duration {
+	if c.MinValid > 0 {
+		return c.MinValid
+	}
+	return defaultAuthMinimum
+}
+
+// encryptedAuthState 加密后的认证状态文件，Data 为 PageState 的 JSON 密文
+type encryptedAuthState struct {
+	Encryption string `json:"encryption"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// authCipher 由密钥和盐派生 AES-GCM
+func authCipher(key string, salt []byte, iterations int) (cipher.AEAD, error) {
+	derived, err := pbkdf2.Key(sha256.New, key, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %v", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptAuthState 加密认证状态的 JSON
+func encryptAuthState(plain []byte, key string) ([]byte, error) {
+	salt := make([]byte, authSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %v", err)
+	}
+	aead, err := authCipher(key, salt, authKDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %v", err)
+	}
+	return json.MarshalIndent(encryptedAuthState{
+		Encryption: authEncryption,
+		KDF:        authKDF,
+		Iterations: authKDFIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Data:       aead.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+}
+
+// decryptAuthState 文件已加密时用密钥解密，返回 PageState 的 JSON；未加密时原样返回
+func decryptAuthState(data []byte, key string) ([]byte, error) {
+	var sealed encryptedAuthState
+	if err := json.Unmarshal(data, &sealed); err != nil || sealed.Encryption == "" {
+		if key != "" {
+			log.Println("⚠️ 认证状态文件未加密，下次保存时将加密")
+		}
+		return data, nil
+	}
+	if sealed.Encryption != authEncryption || sealed.KDF != authKDF {
+		return nil, fmt.Errorf("不支持的认证状态文件加密方式: %s/%s", sealed.Encryption, sealed.KDF)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("认证状态文件已加密，需要在配置文件 auth.key 中设置密钥")
+	}
+	if sealed.Iterations < authKDFMinimum || sealed.Iterations > authKDFMaximum {
+		return nil, fmt.Errorf("认证状态文件格式错误: 迭代次数 %d 不在 %d-%d 之间", sealed.Iterations, authKDFMinimum, authKDFMaximum)
+	}
+	aead, err := authCipher(key, sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("认证状态文件格式错误: nonce 长度不正确")
+	}
+	plain, err := aead.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解密认证状态文件失败（密钥不正确或文件已损坏）")
+	}
+	return plain, nil
+}
+
+// loadLoginCache 扫码登录前读取 -auth-file 保存的认证状态：文件存在、可解密且 cookie 未过期时直接使用，不再扫码；
+// 没有指定文件、需要重新扫码（运行中登录失效）、文件不存在或已过期时返回 nil，由调用方扫码登录
+func loadLoginCache(options LoginOptions) *PageState {
+	if options.AuthFile == "" || options.Refresh {
+		return nil
+	}
+	if _, err := os.Stat(options.AuthFile); os.IsNotExist(err) {
+		log.Printf("📁 认证状态文件 %s 不存在，扫码登录后保存", options.AuthFile)
+		return nil
+	}
+	authState, err := LoadAuthStateFile(options.AuthFile, options.Auth.Key)
+	if err != nil {
+		log.Printf("⚠️ %v，重新扫码登录", err)
+		return nil
+	}
+	if name, expiresAt, ok := earliestCookieExpiry(authState.Cookies); ok {
+		if remaining := time.Until(expiresAt); remaining < options.Auth.minValid() {
+			log.Printf("🍪 已保存的认证状态即将过期（cookie %s 剩余 %v），重新扫码登录", name, remaining.Round(time.Minute))
+			return nil
+		}
+	}
+	log.Printf("✅ 使用已保存的认证状态，跳过扫码登录: %s (视频号: %s, Cookies=%d个)",
+		options.AuthFile, authState.ChannelName, len(authState.Cookies))
+	return authState
+}
+
+// saveLoginCache 扫码登录后把认证状态保存到 -auth-file，下次运行时直接使用；保存失败只记录警告
+func saveLoginCache(options LoginOptions, authState *PageState) {
+	if options.AuthFile == "" || authState == nil {
+		return
+	}
+	if err := SaveAuthStateFile(options.AuthFile, authState, options.Auth); err != nil {
+		log.Printf("⚠️ %v", err)
+		return
+	}
+	if options.Auth.Key == "" {
+		log.Printf("💾 认证状态已保存到: %s（未配置 auth.key，明文保存）", options.AuthFile)
+	} else {
+		log.Printf("🔒 认证状态已加密保存到: %s", options.AuthFile)
+	}
+}
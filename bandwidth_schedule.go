@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bandwidthWindow 服务按时段设置的上传带宽上限，End 早于 Start 时跨过零点
+type bandwidthWindow struct {
+	Start time.Duration // 距零点的时间
+	End   time.Duration
+	Mbps  float64 // 0 表示不限制
+}
+
+// contains 时刻 t 是否在时段内
+func (w bandwidthWindow) contains(t time.Time) bool {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.Start <= w.End {
+		return clock >= w.Start && clock < w.End
+	}
+	return clock >= w.Start || clock < w.End
+}
+
+// parseBandwidthSchedule 解析 -bandwidth："09:00-19:00=5,19:00-09:00=0"，逗号分隔多个时段，
+// 每个时段为 开始-结束=上传带宽上限(Mbps)
+func parseBandwidthSchedule(value string) ([]bandwidthWindow, error) {
+	var windows []bandwidthWindow
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		span, rate, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("带宽时段格式错误(应为 09:00-19:00=5): %s", item)
+		}
+		from, to, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("带宽时段格式错误(应为 09:00-19:00=5): %s", item)
+		}
+		start, err := parseTimeOfDay(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimeOfDay(to)
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("带宽时段的开始和结束时间相同: %s", item)
+		}
+		mbps, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || mbps < 0 {
+			return nil, fmt.Errorf("带宽上限必须是不小于 0 的数字(Mbps): %s", item)
+		}
+		windows = append(windows, bandwidthWindow{Start: start, End: end, Mbps: mbps})
+	}
+	return windows, nil
+}
+
+// parseTimeOfDay 解析 HH:MM，返回距零点的时间
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("时间格式错误(应为 HH:MM): %s", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// bandwidthAt 时刻 t 所在时段的带宽上限，按书写顺序取第一个匹配的时段；没有匹配时返回 false
+func bandwidthAt(windows []bandwidthWindow, t time.Time) (float64, bool) {
+	for _, w := range windows {
+		if w.contains(t) {
+			return w.Mbps, true
+		}
+	}
+	return 0, false
+}
+
+// bandwidthLabel 带宽上限的日志文字
+func bandwidthLabel(mbps float64) string {
+	if mbps == 0 {
+		return "不限制"
+	}
+	return fmt.Sprintf("%gMbps", mbps)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 默认窗口大小
+const (
+	defaultWindowWidth  = 1920
+	defaultWindowHeight = 1080
+)
+
+// blockableResourceTypes 可以拦截的资源类型（Playwright 的 ResourceType）
+var blockableResourceTypes = map[string]bool{
+	"image":      true,
+	"media":      true,
+	"font":       true,
+	"stylesheet": true,
+}
+
+// BrowserConfig 登录和上传两个阶段分别使用的浏览器设置
+type BrowserConfig struct {
+	Login   BrowserPhaseConfig `yaml:"login"`
+	Upload  BrowserPhaseConfig `yaml:"upload"`
+	Context ContextConfig      `yaml:"context"` // 两个阶段共用的上下文模拟设置
+}
+
+// BrowserPhaseConfig 单个阶段的浏览器设置，未填写的项使用命令行参数或默认值
+type BrowserPhaseConfig struct {
+	Headless       *bool         `yaml:"headless"`
+	Window         string        `yaml:"window"`          // 窗口和页面大小，如 1280x800
+	SlowMo         time.Duration `yaml:"slow_mo"`         // 每个浏览器操作之间的延迟，如 200ms，便于观察或规避操作过快
+	BlockResources []string      `yaml:"block_resources"` // 拦截的资源类型：image、media、font、stylesheet
+	Stealth        *bool         `yaml:"stealth"`         // 是否注入反检测脚本，为空时注入
+	StealthSkip    []string      `yaml:"stealth_skip"`    // 不注入的反检测脚本，如 webgl
+}
+
+// Validate 校验窗口大小和拦截的资源类型
+func (c BrowserPhaseConfig) Validate() error {
+	if c.Window != "" {
+		if _, _, err := parseWindowSize(c.Window); err != nil {
+			return err
+		}
+	}
+	if c.SlowMo < 0 {
+		return fmt.Errorf("slow_mo 不能为负数: %v", c.SlowMo)
+	}
+	for _, resourceType := range c.BlockResources {
+		if !blockableResourceTypes[resourceType] {
+			return fmt.Errorf("不支持拦截的资源类型: %s(可选 image、media、font、stylesheet)", resourceType)
+		}
+	}
+	return validateStealthSkip(c.StealthSkip)
+}
+
+// Apply 将阶段设置合并到浏览器启动选项，headlessFixed 为 true 时保留命令行指定的无头模式
+func (c BrowserPhaseConfig) Apply(options BrowserOptions, headlessFixed bool) BrowserOptions {
+	if c.Headless != nil && !headlessFixed && !options.Container {
+		options.Headless = *c.Headless
+	}
+	if c.Window != "" {
+		options.WindowWidth, options.WindowHeight, _ = parseWindowSize(c.Window)
+	}
+	if c.SlowMo > 0 {
+		options.SlowMo = c.SlowMo
+	}
+	if len(c.BlockResources) > 0 {
+		options.BlockResources = c.BlockResources
+	}
+	if c.Stealth != nil {
+		options.NoStealth = !*c.Stealth
+	}
+	options.StealthSkip = c.StealthSkip
+	return options
+}
+
+// parseWindowSize 解析 1280x800 形式的窗口大小
+func parseWindowSize(value string) (int, int, error) {
+	widthText, heightText, ok := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "x")
+	width, widthErr := strconv.Atoi(strings.TrimSpace(widthText))
+	height, heightErr := strconv.Atoi(strings.TrimSpace(heightText))
+	if !ok || widthErr != nil || heightErr != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("窗口大小格式错误: %s(例如 1280x800)", value)
+	}
+	return width, height, nil
+}
+
+// windowSize 返回窗口大小，未设置时为 1920x1080
+func (o BrowserOptions) windowSize() (int, int) {
+	if o.WindowWidth > 0 && o.WindowHeight > 0 {
+		return o.WindowWidth, o.WindowHeight
+	}
+	return defaultWindowWidth, defaultWindowHeight
+}
+
+// blockResources 在上下文中拦截指定类型的资源请求
+func blockResources(context playwright.BrowserContext, resourceTypes []string) error {
+	if len(resourceTypes) == 0 {
+		return nil
+	}
+	blocked := make(map[string]bool, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		blocked[resourceType] = true
+	}
+	log.Printf("🚫 拦截资源类型: %s", strings.Join(resourceTypes, ", "))
+	return context.Route("**/*", func(route playwright.Route) {
+		if blocked[route.Request().ResourceType()] {
+			route.Abort()
+			return
+		}
+		route.Continue()
+	})
+}
+
+// 默认的页面语言
+const defaultLocale = "zh-CN"
+
+// colorSchemes 可选的配色方案
+var colorSchemes = map[string]*playwright.ColorScheme{
+	"light":         playwright.ColorSchemeLight,
+	"dark":          playwright.ColorSchemeDark,
+	"no-preference": playwright.ColorSchemeNoPreference,
+}
+
+// ContextConfig 浏览器上下文的模拟设置，登录和上传阶段共用
+type ContextConfig struct {
+	Viewport          string  `yaml:"viewport"`            // 页面大小，如 1366x768，为空时与窗口大小相同
+	Locale            string  `yaml:"locale"`              // 页面语言，为空时为 zh-CN
+	TimezoneID        string  `yaml:"timezone_id"`         // 时区，如 Asia/Shanghai，为空时使用系统时区
+	DeviceScaleFactor float64 `yaml:"device_scale_factor"` // 设备像素比，如 1、1.25、2
+	ColorScheme       string  `yaml:"color_scheme"`        // light | dark | no-preference
+	UserAgent         string  `yaml:"user_agent"`          // 为空时按浏览器实际版本生成完整的 Chrome UA
+	PinUserAgent      bool    `yaml:"pin_user_agent"`      // 使用认证状态中保存的 UA 和 UA-CH，同一账号每次运行的指纹相同
+}
+
+// Validate 校验页面大小、像素比和配色方案
+func (c ContextConfig) Validate() error {
+	if c.Viewport != "" {
+		if _, _, err := parseWindowSize(c.Viewport); err != nil {
+			return err
+		}
+	}
+	if c.DeviceScaleFactor < 0 {
+		return fmt.Errorf("device_scale_factor 不能为负数: %v", c.DeviceScaleFactor)
+	}
+	if c.ColorScheme != "" && colorSchemes[c.ColorScheme] == nil {
+		return fmt.Errorf("不支持的配色方案: %s(可选 light、dark、no-preference)", c.ColorScheme)
+	}
+	return nil
+}
+
+// locale 页面语言，未设置时为 zh-CN
+func (o BrowserOptions) locale() string {
+	if o.Context.Locale != "" {
+		return o.Context.Locale
+	}
+	return defaultLocale
+}
+
+// contextOptions 生成创建上下文的选项，UA 和 UA-CH 请求头来自 profile
+func contextOptions(options BrowserOptions, profile *UserAgentProfile) playwright.BrowserNewContextOptions {
+	emulation := options.Context
+	width, height := options.windowSize()
+	if emulation.Viewport != "" {
+		width, height, _ = parseWindowSize(emulation.Viewport)
+	}
+	locale := options.locale()
+
+	contextOptions := playwright.BrowserNewContextOptions{
+		Viewport:         &playwright.Size{Width: width, Height: height},
+		Locale:           playwright.String(locale),
+		UserAgent:        playwright.String(profile.UserAgent),
+		ExtraHttpHeaders: profile.headers(),
+		Proxy:            options.Proxy,
+	}
+	if emulation.TimezoneID != "" {
+		contextOptions.TimezoneId = playwright.String(emulation.TimezoneID)
+	}
+	if emulation.DeviceScaleFactor > 0 {
+		contextOptions.DeviceScaleFactor = playwright.Float(emulation.DeviceScaleFactor)
+	}
+	if emulation.ColorScheme != "" {
+		contextOptions.ColorScheme = colorSchemes[emulation.ColorScheme]
+	}
+	return contextOptions
+}
@@ -11,30 +11,51 @@ import (
 	"github.com/playwright-community/playwright-go"
 )
 
-const WechatChannelsUploadPage string = "https://channels.weixin.qq.com/platform/post/create"
-
 // PageState 保存页面状态的结构体
 type PageState struct {
 	Cookies      []playwright.Cookie    `json:"cookies"`
 	LocalStorage map[string]interface{} `json:"local_storage"`
 	URL          string                 `json:"url"`
+	ChannelName  string                 `json:"channel_name,omitempty"` // 登录的视频号名称
+	UserAgent    *UserAgentProfile      `json:"user_agent,omitempty"`   // 登录时的 UA 和 UA-CH，固定指纹时上传阶段沿用
 }
 
 // VideoUploadOptions 视频上传选项
 type VideoUploadOptions struct {
-	Description  string
-	Location     string
-	Collection   string
-	Link         string
-	Activity     string
-	Schedule     bool
-	ScheduleTime string
-	ShortTitle   string
-	Action       string
-}
+	Description        string
+	Location           string
+	Collection         string
+	Collections        *createdCollections // 本次运行中已创建的合集，避免重复创建，可为空
+	Link               string
+	Activity           string
+	Schedule           bool
+	ScheduleTime       string
+	ShortTitle         string
+	Action             string
+	Music              string            // 为空时不添加音乐
+	CoverCrop          string            // 封面裁剪（比例和偏移），为空时使用平台默认的居中裁剪
+	CoverImage         string            // 封面图片，为空时使用平台从视频中截取的封面
+	Fields             FormFieldsConfig  // 可选字段的执行顺序和不执行的字段
+	Absent             map[string]bool   // 当前账号上传页面没有的控件，直接跳过，可为空
+	LocationStrict     bool              // 位置选择失败或校验不一致时任务失败
+	Strict             bool              // 严格模式：位置、合集、链接、活动、音乐等可选字段失败或控件不存在时任务失败
+	OnLocation         func(string)      // 接收最终显示的位置，可为空
+	Minutes            minuteSelection   // 定时发表的分钟不可选时的调整方式
+	ScheduleFallback   string            // 设置定时发表失败时的处理方式，draft 时改为保存草稿
+	OnScheduleFallback func(error)       // 改为保存草稿时接收定时失败的原因，可为空
+	Extra              map[string]string // 自定义表单步骤使用的列
+	OnStep             StepFunc          // 记录各步骤耗时，可为空
+
+	// CheckPublished 重试时发表前查找已发表的相同作品（参数为定时时间，不定时为零值），
+	// 返回找到的作品的说明，找到时不再点击发表；为空时不检查
+	CheckPublished func(scheduledAt time.Time) (string, error)
+}
+
+// QRCodeHandler 接收登录二维码截图（PNG）的回调
+type QRCodeHandler func(png []byte)
 
 // userLogin 等待用户扫码登录
-func waitUserLogin(page playwright.Page) error {
+func waitUserLogin(page playwright.Page, onQRCode QRCodeHandler) error {
 	log.Println("⏳ 等待用户扫码登录...")
 	startTime := time.Now()
 	maxWait := 600 // 10分钟超时
@@ -43,11 +64,20 @@ func waitUserLogin(page playwright.Page) error {
 		time.Sleep(2 * time.Second)
 		elapsed := time.Since(startTime)
 
-		if strings.Contains(page.URL(), "https://channels.weixin.qq.com/platform/") {
+		if platformURLs.IsPlatformPage(page.URL()) {
 			log.Println("✅ 登录跳转至上传页面，登录成功")
 			return nil
 		}
 
+		// 无界面环境下把二维码交给调用方展示
+		if onQRCode != nil {
+			if png, err := captureLoginQRCode(page); err == nil {
+				onQRCode(png)
+			} else {
+				log.Printf("⚠️ 截取登录二维码失败: %v", err)
+			}
+		}
+
 		remaining := maxWait - int(elapsed.Seconds())
 		if remaining > 0 && i%2 == 0 {
 			log.Printf("⏰ 剩余扫码时间: %d秒", remaining)
@@ -60,6 +90,23 @@ func waitUserLogin(page playwright.Page) error {
 	return fmt.Errorf("登录超时")
 }
 
+// qrCodeChain 登录二维码
+var qrCodeChain = SelectorChain{Name: "login_qrcode", Selectors: []string{
+	".qrcode img",
+	".qrcode",
+	"img[class*='qrcode']",
+}}
+
+// captureLoginQRCode 截取登录二维码，找不到二维码元素时截取整个页面
+func captureLoginQRCode(page playwright.Page) ([]byte, error) {
+	if locator, err := qrCodeChain.FindVisible(page); err == nil {
+		return locator.Screenshot(playwright.LocatorScreenshotOptions{
+			Timeout: playwright.Float(5000),
+		})
+	}
+	return page.Screenshot()
+}
+
 // SaveAuthState 保存认证状态
 func SaveAuthState(page playwright.Page, context playwright.BrowserContext) (*PageState, error) {
 	cookies, err := context.Cookies()
@@ -89,6 +136,8 @@ func SaveAuthState(page playwright.Page, context playwright.BrowserContext) (*Pa
 		Cookies:      cookies,
 		LocalStorage: convertToMap(authStorage),
 		URL:          page.URL(),
+		ChannelName:  getCurrentChannelName(page),
+		UserAgent:    readUserAgentProfile(page),
 	}
 
 	log.Printf("✅ 认证状态保存完成: Cookies=%d个", len(pageState.Cookies))
@@ -132,101 +181,195 @@ func ConvertToOptionalCookies(cookies []playwright.Cookie) []playwright.Optional
 	return optionalCookies
 }
 
+// BrowserOptions 浏览器启动选项
+type BrowserOptions struct {
+	Headless       bool
+	Container      bool              // 容器模式：使用镜像内置的浏览器及容器所需的启动参数
+	Downloads      string            // 浏览器下载文件目录，为空时使用 Playwright 默认临时目录
+	WindowWidth    int               // 窗口和页面宽度，0 时为 1920
+	WindowHeight   int               // 窗口和页面高度，0 时为 1080
+	SlowMo         time.Duration     // 每个浏览器操作之间的延迟
+	BlockResources []string          // 拦截的资源类型，如 image、font
+	Context        ContextConfig     // 页面大小、语言、时区等上下文模拟设置
+	UserAgent      *UserAgentProfile // 固定使用的 UA 和 UA-CH，为空时按浏览器版本生成
+	NoStealth      bool              // 不注入反检测脚本
+	StealthSkip    []string          // 不注入的反检测脚本
+	Proxy          *playwright.Proxy // 上下文使用的代理，为空时直连
+}
+
+// browserLaunchArgs 根据运行环境生成浏览器启动参数
+func browserLaunchArgs(options BrowserOptions) []string {
+	width, height := options.windowSize()
+	args := []string{
+		fmt.Sprintf("--window-size=%d,%d", width, height),
+		"--disable-gpu",
+		"--disable-dev-shm-usage",
+		"--no-sandbox",
+	}
+	if options.Container {
+		// 容器内通常以root运行且没有setuid沙箱、/dev/shm很小
+		args = append(args,
+			"--disable-setuid-sandbox",
+			"--no-zygote",
+		)
+	}
+	return args
+}
+
 // GenerateBrowser 生成浏览器信息
-func GenerateBrowser(headlessMode bool) (*playwright.Playwright, *playwright.Browser, *playwright.BrowserContext, error) {
+func GenerateBrowser(options BrowserOptions) (*playwright.Playwright, *playwright.Browser, *playwright.BrowserContext, error) {
 	pw, err := playwright.Run()
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("启动Playwright失败: %v", err)
 	}
 
+	launchOptions := playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(options.Headless),
+		Args:     browserLaunchArgs(options),
+	}
+	if options.Downloads != "" {
+		launchOptions.DownloadsPath = playwright.String(options.Downloads)
+	}
+	if options.SlowMo > 0 {
+		launchOptions.SlowMo = playwright.Float(float64(options.SlowMo.Milliseconds()))
+	}
+	// 容器中使用镜像内置的 Chromium，否则使用本机安装的 Chrome
+	if !options.Container {
+		launchOptions.Channel = playwright.String("chrome")
+	}
+
 	// 启动无头浏览器
-	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
-		Channel:  playwright.String("chrome"),
-		Headless: playwright.Bool(headlessMode),
-		Args: []string{
-			"--window-size=1920,1080",
-			"--disable-gpu",
-			"--disable-dev-shm-usage",
-			"--no-sandbox",
-		},
-	})
+	browser, err := pw.Chromium.Launch(launchOptions)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("启动浏览器失败: %v", err)
 	}
 
-	// 创建上下文
-	context, err := browser.NewContext(playwright.BrowserNewContextOptions{
-		Viewport:  &playwright.Size{Width: 1920, Height: 1080},
-		UserAgent: playwright.String("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"),
-	})
+	context, err := newBrowserContext(browser, options)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("创建上下文失败: %v", err)
+		return nil, nil, nil, err
 	}
+	return pw, &browser, &context, nil
+}
 
-	// 反自动化脚本
-	scriptContent := `Object.defineProperty(navigator, 'webdriver', { get: () => false });`
-	err = context.AddInitScript(playwright.Script{Content: &scriptContent})
+// newBrowserContext 创建上下文，设置 UA、代理、反检测脚本和资源拦截
+func newBrowserContext(browser playwright.Browser, options BrowserOptions) (playwright.BrowserContext, error) {
+	profile := options.UserAgent
+	if profile == nil {
+		profile = newUserAgentProfile(browser.Version())
+		if options.Context.UserAgent != "" {
+			profile.UserAgent = options.Context.UserAgent
+		}
+	}
+	context, err := browser.NewContext(contextOptions(options, profile))
+	if err != nil {
+		return nil, fmt.Errorf("创建上下文失败: %v", err)
+	}
 
-	return pw, &browser, &context, nil
+	// 反检测脚本
+	if !options.NoStealth {
+		scriptContent := stealthScript(options.locale(), options.StealthSkip)
+		if err := context.AddInitScript(playwright.Script{Content: &scriptContent}); err != nil {
+			log.Printf("⚠️ 注入反检测脚本失败: %v", err)
+		}
+	}
+	userAgentScript := profile.initScript()
+	if err := context.AddInitScript(playwright.Script{Content: &userAgentScript}); err != nil {
+		log.Printf("⚠️ 设置UA-CH失败: %v", err)
+	}
+	if err := blockResources(context, options.BlockResources); err != nil {
+		log.Printf("⚠️ 设置资源拦截失败: %v", err)
+	}
+	return context, nil
 }
 
-// GeneratePage 生成页面信息
-func GeneratePage(context *playwright.BrowserContext, isLogin bool) (*playwright.Page, string, error) {
+// openCreatePage 新建页面并导航到视频号创建页面
+func openCreatePage(context *playwright.BrowserContext) (playwright.Page, error) {
 	page, err := (*context).NewPage()
 	if err != nil {
-		return nil, "", fmt.Errorf("创建页面失败: %v", err)
+		return nil, fmt.Errorf("创建页面失败: %v", err)
 	}
 
-	// 防止超时
-	for i := 0; i < 3; i++ {
-		log.Printf("🌐 导航尝试 %d/%d: %s", i+1, 3, WechatChannelsUploadPage)
-		_, err = page.Goto(WechatChannelsUploadPage, playwright.PageGotoOptions{
-			Timeout:   playwright.Float(60000),                   // 减少超时到60秒
-			WaitUntil: playwright.WaitUntilStateDomcontentloaded, // 改为DOMContentLoaded，不等待所有资源
-		})
-		if err == nil {
-			log.Println("✅ 页面导航成功")
-			break
-		}
-		log.Printf("⚠️ 导航失败 (尝试 %d): %v", i+1, err)
-		if i <= 3 {
-			waitTime := time.Duration(i+1) * 10 * time.Second
-			log.Printf("⏳ 等待 %v 后重试...", waitTime)
-			time.Sleep(waitTime)
-			// 刷新页面重试
-			page.Reload()
-		}
+	// 失败时按指数退避重试
+	if err := navigateCreatePage(page); err != nil {
+		page.Close()
+		return nil, fmt.Errorf("页面创建失败: %v", err)
 	}
+	return page, nil
+}
+
+// GenerateLoginPage 生成扫码登录页面并等待用户扫码，onQRCode 不为空时会持续回传二维码截图
+func GenerateLoginPage(context *playwright.BrowserContext, onQRCode QRCodeHandler) (*playwright.Page, error) {
+	page, err := openCreatePage(context)
 	if err != nil {
-		return nil, "", fmt.Errorf("页面创建失败: %v", err)
+		return nil, err
 	}
 
 	// 用户扫码时需要等待扫码
-	if isLogin {
-		if err = waitUserLogin(page); err != nil {
-			return nil, "", fmt.Errorf("登录失败: %v", err)
-		}
-	} else {
-		// 上传视频时需要检查页面是否就绪
-		time.Sleep(5 * time.Second)
-		if err := waitForPageReady(page); err != nil {
-			return nil, "", fmt.Errorf("页面加载失败: %v", err)
-		}
-		if !isLoggedIn(page) {
-			return &page, "", fmt.Errorf("登录信息失效: %v", err)
+	if err = waitUserLogin(page, onQRCode); err != nil {
+		return nil, fmt.Errorf("登录失败: %v", err)
+	}
+	return &page, nil
+}
+
+// PageOptions 上传页面选项
+type PageOptions struct {
+	MaxUploadMbps float64 // 上传带宽上限(Mbps)，0表示不限制
+}
+
+// GeneratePage 生成上传页面信息
+func GeneratePage(context *playwright.BrowserContext, options PageOptions) (*playwright.Page, string, error) {
+	page, err := openCreatePage(context)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 限制上传带宽，避免占满出口带宽
+	if options.MaxUploadMbps > 0 {
+		if err := limitUploadBandwidth(*context, page, options.MaxUploadMbps); err != nil {
+			log.Printf("⚠️ 设置上传带宽限制失败: %v", err)
 		}
-		// 获取视频号名称
-		channnelName := getCurrentChannelName(page)
-		return &page, channnelName, nil
 	}
-	return &page, "", nil
+
+	// 上传视频时需要检查页面是否就绪
+	time.Sleep(5 * time.Second)
+	if err := waitForPageReady(page); err != nil {
+		return nil, "", fmt.Errorf("页面加载失败: %v", err)
+	}
+	if !isLoggedIn(page) {
+		return &page, "", fmt.Errorf("登录信息失效: %v", err)
+	}
+	// 获取视频号名称
+	channnelName := getCurrentChannelName(page)
+	return &page, channnelName, nil
+}
+
+// limitUploadBandwidth 通过CDP网络模拟限制页面的上传带宽
+func limitUploadBandwidth(context playwright.BrowserContext, page playwright.Page, mbps float64) error {
+	session, err := context.NewCDPSession(page)
+	if err != nil {
+		return fmt.Errorf("创建CDP会话失败: %v", err)
+	}
+
+	// CDP 吞吐量单位为 字节/秒，-1 表示不限制
+	bytesPerSecond := mbps * 1000 * 1000 / 8
+	if _, err := session.Send("Network.emulateNetworkConditions", map[string]interface{}{
+		"offline":            false,
+		"latency":            0,
+		"downloadThroughput": -1,
+		"uploadThroughput":   bytesPerSecond,
+	}); err != nil {
+		return fmt.Errorf("设置网络限速失败: %v", err)
+	}
+
+	log.Printf("🐢 上传带宽限制: %.1f Mbps", mbps)
+	return nil
 }
 
 // waitForPageReady 等待页面完全就绪
 func waitForPageReady(page playwright.Page) error {
 	log.Println("🔍 检查页面状态...")
 
-	maxWait := 30
+	maxWait := navigationRules.readyChecks()
 	for i := 0; i < maxWait; i++ {
 		currentURL := page.URL()
 		title, _ := page.Title()
@@ -245,30 +388,28 @@ func waitForPageReady(page playwright.Page) error {
 		}
 
 		log.Printf("⏳ 等待页面元素加载... (%d/%d)", i+1, maxWait)
-		time.Sleep(2 * time.Second)
+		time.Sleep(pageReadyInterval)
 	}
 
 	return fmt.Errorf("页面加载超时")
 }
 
+// channelNameChain 页面左侧的视频号名称
+var channelNameChain = SelectorChain{Name: "channel_name", Selectors: []string{
+	".common-menu-item.account-info .account-info .name",
+	".account-info .name",
+	"[class*='account-info'] .name",
+	".left-part .name",
+	// 直接使用数据属性
+	"[data-v-5271c1f2] .name",
+	"[data-v-02b98fb1] .name",
+}}
+
 // 获取当前登录的视频号名称
 func getCurrentChannelName(page playwright.Page) string {
-	// 专门针对您提供的HTML结构的选择器
-	specificSelectors := []string{
-		".common-menu-item.account-info .account-info .name",
-		".account-info .name",
-		"[class*='account-info'] .name",
-		".left-part .name",
-		// 直接使用数据属性
-		"[data-v-5271c1f2] .name",
-		"[data-v-02b98fb1] .name",
-	}
-
-	for _, selector := range specificSelectors {
-		if name, found := getTextFromSelector(page, selector); found {
-			log.Printf("✅ 通过选择器找到视频号名称: %s -> %s", selector, name)
-			return name
-		}
+	if name, err := channelNameChain.Text(page); err == nil {
+		log.Printf("✅ 通过选择器找到视频号名称: %s", name)
+		return name
 	}
 
 	// 如果上面的选择器不行，尝试更精确的定位
@@ -318,6 +459,22 @@ func getTextFromSelector(page playwright.Page, selector string) (string, bool) {
 	return strings.TrimSpace(text), true
 }
 
+// 表单中各字段的输入框或入口
+const (
+	descriptionSelector = ".input-editor[contenteditable][data-placeholder='添加描述']"
+	locationSelector    = ".post-position-wrap .position-display"
+	collectionSelector  = ".post-album-display"
+	linkSelector        = ".post-link-wrap .link-display-wrap"
+	activitySelector    = ".post-activity-wrap .activity-display"
+)
+
+// formField 可选表单字段：任务中是否填写了该字段、失败时任务是否失败（否则只记录警告）和填写方法
+type formField struct {
+	set      bool
+	required bool
+	fill     func() error
+}
+
 // completeVideoUploadForm 完整的表单填写方法
 func completeVideoUploadForm(page playwright.Page, options VideoUploadOptions) error {
 	log.Println("=== 开始自动填写视频上传表单 ===")
@@ -325,77 +482,191 @@ func completeVideoUploadForm(page playwright.Page, options VideoUploadOptions) e
 	// 1. 填写视频描述
 	if options.Description != "" {
 		log.Println("📝 填写视频描述...")
-		descSelector := ".input-editor[contenteditable][data-placeholder='添加描述']"
-		if err := page.Locator(descSelector).First().Click(); err != nil {
-			return fmt.Errorf("点击描述输入框失败: %v", err)
-		}
-		time.Sleep(500 * time.Millisecond)
+		err := runStep(options.OnStep, StepDescription, func() error {
+			if err := page.Locator(descriptionSelector).First().Click(); err != nil {
+				return fmt.Errorf("点击描述输入框失败: %v", err)
+			}
+			time.Sleep(500 * time.Millisecond)
 
-		if err := page.Locator(descSelector).First().Fill(options.Description); err != nil {
-			return fmt.Errorf("填写描述失败: %v", err)
+			if err := page.Locator(descriptionSelector).First().Fill(options.Description); err != nil {
+				return fmt.Errorf("填写描述失败: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 		log.Println("✅ 视频描述填写成功")
 	}
 
-	// 2. 选择位置
-	if options.Location != "" {
-		log.Printf("📍 选择位置: %s", options.Location)
-		if err := selectLocation(page, options.Location); err != nil {
-			log.Printf("⚠️ 选择位置失败: %v", err)
-		}
+	// 2. 按配置的顺序填写可选字段，配置为不执行的字段和当前账号没有的控件忽略任务中填写的内容
+	var scheduledAt time.Time
+	fields := map[string]formField{
+		// 选择位置，并校验最终显示的位置
+		StepLocation: {options.Location != "", options.Fields.required(StepLocation, options.LocationStrict || options.Strict), func() error {
+			log.Printf("📍 选择位置: %s", options.Location)
+			err := runStep(options.OnStep, StepLocation, func() error {
+				if err := selectLocation(page, options.Location); err != nil {
+					return err
+				}
+				displayed, err := verifyLocation(page, options.Location)
+				if options.OnLocation != nil && displayed != "" {
+					options.OnLocation(displayed)
+				}
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("选择位置失败: %v", err)
+			}
+			return nil
+		}},
+		// 选择或创建合集
+		StepCollection: {options.Collection != "", options.Fields.required(StepCollection, options.Strict), func() error {
+			log.Printf("📚 处理合集: %s", options.Collection)
+			if err := runStep(options.OnStep, StepCollection, func() error { return handleCollection(page, options.Collection, options.Collections) }); err != nil {
+				return fmt.Errorf("处理合集失败: %v", err)
+			}
+			return nil
+		}},
+		// 选择链接
+		StepLink: {options.Link != "", options.Fields.required(StepLink, options.Strict), func() error {
+			log.Printf("🔗 选择链接类型: %s", options.Link)
+			if err := runStep(options.OnStep, StepLink, func() error { return selectLink(page, options.Link) }); err != nil {
+				return fmt.Errorf("选择链接失败: %v", err)
+			}
+			return nil
+		}},
+		// 选择活动
+		StepActivity: {options.Activity != "", options.Fields.required(StepActivity, options.Strict), func() error {
+			log.Printf("🎯 选择活动: %s", options.Activity)
+			if err := runStep(options.OnStep, StepActivity, func() error { return selectActivity(page, options.Activity) }); err != nil {
+				return fmt.Errorf("选择活动失败: %v", err)
+			}
+			return nil
+		}},
+		// 设置定时发表，并校验日期输入框中最终显示的时间
+		StepSchedule: {options.Schedule, true, func() error {
+			log.Println("⏰ 设置定时发表...")
+			err := runStep(options.OnStep, StepSchedule, func() error {
+				var err error
+				scheduledAt, err = setScheduledPublish(page, options.ScheduleTime, options.Minutes)
+				return err
+			})
+			switch {
+			case err == nil:
+				log.Println("✅ 定时发表设置成功")
+			case options.ScheduleFallback == ScheduleFallbackDraft:
+				// 保留已上传的视频，取消定时后保存草稿，由人工设置定时
+				log.Printf("⚠️ 设置定时发表失败，改为保存草稿，需手动设置定时: %v", err)
+				if cancelErr := cancelScheduledPublish(page); cancelErr != nil {
+					return fmt.Errorf("设置定时发表失败: %v; 取消定时发表失败: %v", err, cancelErr)
+				}
+				options.Schedule = false
+				options.Action = "save_draft"
+				scheduledAt = time.Time{}
+				if options.OnScheduleFallback != nil {
+					options.OnScheduleFallback(err)
+				}
+			default:
+				return fmt.Errorf("设置定时发表失败: %v", err)
+			}
+			return nil
+		}},
+		// 填写短标题
+		StepShortTitle: {options.ShortTitle != "", true, func() error {
+			log.Println("🏷️ 填写短标题...")
+			if err := runStep(options.OnStep, StepShortTitle, func() error { return fillShortTitle(page, options.ShortTitle) }); err != nil {
+				return fmt.Errorf("填写短标题失败: %v", err)
+			}
+			log.Println("✅ 短标题填写成功")
+			return nil
+		}},
+		// 选择音乐
+		StepMusic: {options.Music != "", options.Fields.required(StepMusic, options.Strict), func() error {
+			log.Printf("🎵 选择音乐: %s", options.Music)
+			if err := runStep(options.OnStep, StepMusic, func() error { return selectMusic(page, options.Music) }); err != nil {
+				return fmt.Errorf("选择音乐失败: %v", err)
+			}
+			return nil
+		}},
+		// 按封面图片列更换封面，按封面裁剪列调整封面
+		StepCover: {options.CoverCrop != "" || options.CoverImage != "", true, func() error {
+			crop, err := parseCoverCrop(options.CoverCrop)
+			if err != nil {
+				return err
+			}
+			if crop != nil {
+				log.Printf("🖼️ 裁剪封面: %s", crop)
+			}
+			if err := runStep(options.OnStep, StepCover, func() error { return applyCover(page, options.CoverImage, crop) }); err != nil {
+				return fmt.Errorf("设置封面失败: %v", err)
+			}
+			return nil
+		}},
 	}
-
-	// 3. 选择或创建合集
-	if options.Collection != "" {
-		log.Printf("📚 处理合集: %s", options.Collection)
-		if err := handleCollection(page, options.Collection); err != nil {
-			log.Printf("⚠️ 处理合集失败: %v", err)
+	for _, name := range options.Fields.order() {
+		field := fields[name]
+		if !field.set {
+			continue
 		}
-	}
-
-	// 4. 选择链接
-	if options.Link != "" {
-		log.Printf("🔗 选择链接类型: %s", options.Link)
-		if err := selectLink(page, options.Link); err != nil {
-			log.Printf("⚠️ 选择链接失败: %v", err)
+		if options.Fields.skipped(name) {
+			log.Printf("⏭️ 表单字段 %s 已配置为不执行，忽略任务中填写的内容", name)
+			continue
 		}
-	}
-
-	// 5. 选择活动
-	if options.Activity != "" {
-		log.Printf("🎯 选择活动: %s", options.Activity)
-		if err := selectActivity(page, options.Activity); err != nil {
-			log.Printf("⚠️ 选择活动失败: %v", err)
+		if options.Absent[name] && field.required {
+			return fmt.Errorf("当前账号的上传页面没有%s控件", capabilityLabel(name))
+		}
+		if options.Absent[name] {
+			log.Printf("⏭️ 当前账号的上传页面没有%s控件，跳过", capabilityLabel(name))
+			continue
+		}
+		if err := field.fill(); err != nil && field.required {
+			return err
+		} else if err != nil {
+			log.Printf("⚠️ %v", err)
 		}
 	}
 
-	// 6. 设置定时发表
-	if options.Schedule {
-		log.Println("⏰ 设置定时发表...")
-		if err := setScheduledPublish(page, options.ScheduleTime); err != nil {
-			return fmt.Errorf("设置定时发表失败: %v", err)
-		}
-		log.Println("✅ 定时发表设置成功")
+	// 3. 执行自定义表单步骤
+	if err := runFormSteps(page, options.Extra, options.OnStep); err != nil {
+		return err
 	}
 
-	// 7. 填写短标题
-	if options.ShortTitle != "" {
-		log.Println("🏷️ 填写短标题...")
-		if err := fillShortTitle(page, options.ShortTitle); err != nil {
-			return fmt.Errorf("填写短标题失败: %v", err)
+	// 4. 重试时先确认作品没有在上一次执行中发表，避免重复发表
+	if options.Action == "publish" && options.CheckPublished != nil {
+		var published string
+		err := runStep(options.OnStep, StepPublishCheck, func() error {
+			var err error
+			published, err = options.CheckPublished(scheduledAt)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("检查是否已发表失败: %v", err)
+		}
+		if published != "" {
+			log.Printf("⏭️ %s，不再发表", published)
+			return fmt.Errorf("%w: %s", errAlreadyPublished, published)
 		}
-		log.Println("✅ 短标题填写成功")
 	}
 
-	// 8. 执行最终操作
-	if options.Action != "" {
+	// 5. 执行最终操作，仅上传不提交时不点击，由操作人员检查后手动发表
+	if options.Action == actionStage {
+		log.Println("⏸️ 仅上传不提交，不点击最终按钮")
+	} else if options.Action != "" {
 		log.Printf("🚀 执行最终操作: %s", options.Action)
-		if err := performFinalAction(page, options.Action, options.Schedule); err != nil {
+		if err := performFinalAction(page, options.Action, options.Schedule, options.OnStep); err != nil {
 			return fmt.Errorf("执行最终操作失败: %v", err)
 		}
 		log.Printf("✅ %s 操作成功", getActionName(options.Action))
 	}
 
+	// 6. 定时发表后在作品管理中核对定时时间
+	if options.Action == "publish" && !scheduledAt.IsZero() {
+		if err := runStep(options.OnStep, StepScheduleCheck, func() error { return verifyScheduledPost(page, options.Description, scheduledAt) }); err != nil {
+			return err
+		}
+	}
+
 	log.Println("🎉 表单自动填写完成！")
 	return nil
 }
@@ -403,15 +674,14 @@ func completeVideoUploadForm(page playwright.Page, options VideoUploadOptions) e
 // selectLocation 选择位置
 func selectLocation(page playwright.Page, location string) error {
 	// 点击位置选择器
-	locationSelector := ".post-position-wrap .position-display"
 	if err := page.Locator(locationSelector).First().Click(); err != nil {
 		return err
 	}
 	time.Sleep(1 * time.Second)
 
-	if location == "不显示位置" {
-		// 选择"不显示位置"
-		if err := page.Locator(".location-filter-wrap .option-item.active").First().Click(); err != nil {
+	if location == locationHidden {
+		// 按文字选择"不显示位置"，不依赖当前高亮的选项
+		if err := page.Locator(".location-filter-wrap .option-item:has-text('不显示位置')").First().Click(); err != nil {
 			return err
 		}
 	} else {
@@ -429,8 +699,8 @@ func selectLocation(page playwright.Page, location string) error {
 				return err
 			}
 		} else {
-			// 如果没有精确匹配，选择第一个结果
-			if err := page.Locator(".location-filter-wrap .option-item:not(.active)").First().Click(); err != nil {
+			// 如果没有精确匹配，选择第一个搜索结果（跳过"不显示位置"）
+			if err := page.Locator(".location-filter-wrap .option-item:not(:has-text('不显示位置'))").First().Click(); err != nil {
 				return err
 			}
 		}
@@ -440,51 +710,35 @@ func selectLocation(page playwright.Page, location string) error {
 	return nil
 }
 
-// handleCollection 处理合集
-func handleCollection(page playwright.Page, collection string) error {
+// handleCollection 处理合集：选择标题相同的合集；填写创建新合集时合集不存在才创建，
+// 本次运行中已创建的合集不再创建（created 为 nil 时不记录）
+func handleCollection(page playwright.Page, collection string, created *createdCollections) error {
 	// 点击合集选择器
-	collectionSelector := ".post-album-display"
 	if err := page.Locator(collectionSelector).First().Click(); err != nil {
 		return err
 	}
 	time.Sleep(1 * time.Second)
 
-	if collection == "创建新合集" {
-		// 点击创建新合集
-		if err := page.Locator(".filter-wrap .create a").First().Click(); err != nil {
-			return err
-		}
-		time.Sleep(1 * time.Second)
-
-		// 填写合集标题
-		titleInput := ".weui-desktop-dialog__wrp input[placeholder='有趣的合集标题更容易吸引粉丝']"
-		if err := page.Locator(titleInput).First().Fill("我的视频合集"); err != nil {
-			return err
-		}
-
-		// 点击创建按钮（等待按钮可用）
-		createBtn := ".weui-desktop-dialog__ft .weui-desktop-btn_primary:not(.weui-desktop-btn_disabled)"
-		if err := page.Locator(createBtn).First().WaitFor(playwright.LocatorWaitForOptions{
-			State:   playwright.WaitForSelectorStateVisible,
-			Timeout: playwright.Float(5000),
-		}); err != nil {
-			return err
-		}
-
-		if err := page.Locator(createBtn).First().Click(); err != nil {
-			return err
+	name, create := parseCollection(collection)
+	selected, err := selectCollectionOption(page, name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case selected:
+		if create {
+			log.Printf("✅ 合集已存在，直接加入: %s", name)
 		}
-
-		// 等待创建成功并关闭对话框
-		time.Sleep(2 * time.Second)
-		confirmBtn := ".create-success-dialog .weui-desktop-btn_primary"
-		if err := page.Locator(confirmBtn).First().Click(); err != nil {
+	case !create:
+		return fmt.Errorf("合集列表中未找到合集: %s", name)
+	case created.has(name):
+		// 本次运行中已创建但列表中还没有，不再重复创建
+		return fmt.Errorf("合集 %s 已在本次运行中创建，但合集列表中未找到", name)
+	default:
+		if err := createCollection(page, name); err != nil {
 			return err
 		}
-	} else {
-		// 选择现有合集（这里需要根据实际合集列表调整）
-		log.Printf("⚠️ 选择现有合集: %s (需要根据实际页面调整)", collection)
-		// 这里可以添加选择现有合集的逻辑
+		created.add(name)
 	}
 
 	time.Sleep(1 * time.Second)
@@ -494,7 +748,6 @@ func handleCollection(page playwright.Page, collection string) error {
 // selectLink 选择链接类型
 func selectLink(page playwright.Page, linkType string) error {
 	// 点击链接选择器
-	linkSelector := ".post-link-wrap .link-display-wrap"
 	if err := page.Locator(linkSelector).First().Click(); err != nil {
 		return err
 	}
@@ -522,7 +775,6 @@ func selectLink(page playwright.Page, linkType string) error {
 // selectActivity 选择活动
 func selectActivity(page playwright.Page, activity string) error {
 	// 点击活动选择器
-	activitySelector := ".post-activity-wrap .activity-display"
 	if err := page.Locator(activitySelector).First().Click(); err != nil {
 		return err
 	}
@@ -543,10 +795,11 @@ func selectActivity(page playwright.Page, activity string) error {
 
 		// 选择活动（这里需要根据实际搜索结果调整）
 		activityItem := fmt.Sprintf(".activity-item:has-text('%s')", activity)
-		if count, _ := page.Locator(activityItem).Count(); count > 0 {
-			if err := page.Locator(activityItem).First().Click(); err != nil {
-				return err
-			}
+		if count, _ := page.Locator(activityItem).Count(); count == 0 {
+			return fmt.Errorf("未找到活动: %s", activity)
+		}
+		if err := page.Locator(activityItem).First().Click(); err != nil {
+			return err
 		}
 	}
 
@@ -554,50 +807,56 @@ func selectActivity(page playwright.Page, activity string) error {
 	return nil
 }
 
+// shortTitleChain 短标题输入框
+var shortTitleChain = SelectorChain{Name: "short_title", Selectors: []string{
+	".short-title-wrap input.weui-desktop-form__input",
+	"input[placeholder*='概括视频主要内容']",
+	".post-short-title-wrap input",
+}}
+
 // fillShortTitle 填写短标题
 func fillShortTitle(page playwright.Page, title string) error {
-	shortTitleSelectors := []string{
-		".short-title-wrap input.weui-desktop-form__input",
-		"input[placeholder*='概括视频主要内容']",
-		".post-short-title-wrap input",
-	}
-
-	for _, selector := range shortTitleSelectors {
-		if count, _ := page.Locator(selector).Count(); count > 0 {
-			// 等待元素可见
-			if err := page.Locator(selector).First().WaitFor(playwright.LocatorWaitForOptions{
-				State:   playwright.WaitForSelectorStateVisible,
-				Timeout: playwright.Float(5000),
-			}); err != nil {
-				continue
-			}
+	err := shortTitleChain.Try(page, func(locator playwright.Locator) error {
+		input := locator.First()
+		// 等待元素可见
+		if err := input.WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: playwright.Float(5000),
+		}); err != nil {
+			return err
+		}
 
-			// 点击确保焦点
-			if err := page.Locator(selector).First().Click(); err != nil {
-				continue
-			}
-			time.Sleep(500 * time.Millisecond)
+		// 点击确保焦点
+		if err := input.Click(); err != nil {
+			return err
+		}
+		time.Sleep(500 * time.Millisecond)
 
-			// 清空并填写
-			if err := page.Locator(selector).First().Fill(""); err != nil {
-				continue
-			}
-			time.Sleep(300 * time.Millisecond)
+		// 清空并填写
+		if err := input.Fill(""); err != nil {
+			return err
+		}
+		time.Sleep(300 * time.Millisecond)
 
-			if err := page.Locator(selector).First().Fill(title); err != nil {
-				continue
-			}
+		if err := input.Fill(title); err != nil {
+			return err
+		}
 
-			// 验证填写成功
-			time.Sleep(500 * time.Millisecond)
-			value, err := page.Locator(selector).First().InputValue()
-			if err == nil && value == title {
-				return nil
-			}
+		// 验证填写成功
+		time.Sleep(500 * time.Millisecond)
+		value, err := input.InputValue()
+		if err != nil {
+			return err
 		}
+		if value != title {
+			return fmt.Errorf("填写后的值不一致: %s", value)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("无法填写短标题: %v", err)
 	}
-
-	return fmt.Errorf("无法填写短标题")
+	return nil
 }
 
 // getActionName 获取操作名称
@@ -609,52 +868,17 @@ func getActionName(action string) string {
 		return "手机预览"
 	case "publish":
 		return "发表"
+	case actionStage:
+		return "仅上传不提交"
 	default:
 		return action
 	}
 }
 
-// isCorrectPage 检查是否在正确的页面
-func isCorrectPage(page playwright.Page) bool {
-	currentURL := page.URL()
-
-	// 检查URL是否包含上传页面的特征
-	if strings.Contains(currentURL, "channels.weixin.qq.com") &&
-		(strings.Contains(currentURL, "platform/post/create") || strings.Contains(currentURL, "create")) {
-		return true
-	}
-
-	// 检查页面内容
-	bodyLocator := page.Locator("body")
-	bodyText, err := bodyLocator.TextContent()
-	if err == nil {
-		// 检查页面是否包含上传相关文本
-		uploadTexts := []string{"上传视频", "保存草稿", "发表", "创作"}
-		for _, text := range uploadTexts {
-			if strings.Contains(bodyText, text) {
-				log.Printf("✅ 页面包含上传文本: %s", text)
-				return true
-			}
-		}
-	}
-
-	log.Printf("❌ 不在正确的上传页面，当前URL: %s", currentURL)
-	return false
-}
-
 // isUploadPageReady 检查上传页面是否就绪
 func isUploadPageReady(page playwright.Page) bool {
-	// 放宽检查条件，只要找到任何上传相关元素即可
-	uploadSelectors := []string{
-		"input[type='file']",
-		".ant-upload",
-		"button:has-text('保存草稿')",
-		"button:has-text('发表')",
-		"text=上传视频",
-		"[class*='upload']",
-	}
-
-	for _, selector := range uploadSelectors {
+	// 放宽检查条件，只要找到任何上传相关元素即可（配置文件 navigation.ready_selectors）
+	for _, selector := range navigationRules.readySelectors {
 		count, _ := page.Locator(selector).Count()
 		if count > 0 {
 			log.Printf("✅ 找到上传元素: %s (数量: %d)", selector, count)
@@ -666,41 +890,6 @@ func isUploadPageReady(page playwright.Page) bool {
 	return false
 }
 
-// isLoggedIn 检查是否已登录
-func isLoggedIn(page playwright.Page) bool {
-	// 检查登录状态指示器
-	loggedInSelectors := []string{
-		".ant-upload", // 上传组件
-		"text=保存草稿",   // 保存按钮
-		"text=发表",     // 发表按钮
-		"text=上传视频",   // 上传文字
-	}
-
-	for _, selector := range loggedInSelectors {
-		locator := page.Locator(selector)
-		if visible, _ := locator.First().IsVisible(); visible {
-			return true
-		}
-	}
-
-	// 检查是否在登录页面
-	loginSelectors := []string{
-		".qrcode",        // 二维码
-		"text=扫码登录",      // 登录文字
-		"text=请使用微信扫码登录", // 登录提示
-	}
-
-	for _, selector := range loginSelectors {
-		locator := page.Locator(selector)
-		if visible, _ := locator.First().IsVisible(); visible {
-			return false
-		}
-	}
-
-	// 默认认为已登录（避免误判）
-	return true
-}
-
 // 🔥 优化：uploadVideo 方法，添加重试机制
 func uploadVideo(page playwright.Page, videoPath string) error {
 	log.Println("=== 开始上传文件 ===")
@@ -727,6 +916,18 @@ func uploadVideo(page playwright.Page, videoPath string) error {
 	return fmt.Errorf("所有上传方法都失败")
 }
 
+// fileInputChain 视频文件输入框
+var fileInputChain = SelectorChain{Name: "file_input", Selectors: []string{
+	"input[type='file']",
+	"input[accept*='video']",
+	"input[accept*='mp4']",
+	"input[name='file']",
+	".ant-upload input",
+	"input.ant-upload",
+	"[class*='upload'] input[type='file']",
+	"input[type='file'][accept*='video']",
+}}
+
 // 🔥 优化：uploadVideoBySelector 方法，通过选择器进行上传视频
 func uploadVideoBySelector(page playwright.Page, videoPath string) error {
 
@@ -755,98 +956,114 @@ func uploadVideoBySelector(page playwright.Page, videoPath string) error {
 	}
 
 	// 更多选择器尝试
-	selectors := []string{
-		"input[type='file']",
-		"input[accept*='video']",
-		"input[accept*='mp4']",
-		"input[name='file']",
-		".ant-upload input",
-		"input.ant-upload",
-		"[class*='upload'] input[type='file']",
-		"input[type='file'][accept*='video']",
-	}
-
-	var fileInput playwright.Locator
-	foundSelector := ""
-
-	for _, selector := range selectors {
-		fileInput = page.Locator(selector)
-		if count, _ := fileInput.Count(); count > 0 {
-			foundSelector = selector
-			log.Printf("✅ 找到文件输入框: %s (数量: %d)", selector, count)
-			break
-		}
-	}
-
-	if foundSelector == "" {
+	fileInput, err := fileInputChain.Find(page)
+	if err != nil {
 		return fmt.Errorf("未找到任何文件输入框")
 	}
+	log.Println("✅ 找到文件输入框")
+
+	// 页面上可能还留着之前暂存文件的删除按钮，此时删除按钮不能说明本次上传已完成
+	staleDelete := hasDeleteButton(page)
+	// 设置文件前开始监听上传完成的接口
+	responses := expectAPIResponse(page, uploadFinishAPIPatterns, uploadTimeout)
 
 	// 设置文件
 	log.Printf("📁 设置文件: %s", videoPath)
-	if err := fileInput.SetInputFiles([]string{videoPath}); err != nil {
+	if err := fileInput.SetInputFiles([]string{resolvePath(videoPath)}); err != nil {
 		return fmt.Errorf("设置文件失败: %v", err)
 	}
 
 	log.Println("✅ 文件设置成功，等待上传开始...")
 
 	// 检查上传状态
-	return checkVideoUploadStatus(page)
+	return checkVideoUploadStatus(page, responses, staleDelete)
 
 }
 
-// 检查上传状态
-func checkVideoUploadStatus(page playwright.Page) error {
-	log.Println("=== 监控上传状态 ===")
+// uploadTimeout 等待视频上传完成的时间
+const uploadTimeout = 5 * time.Minute
 
-	// 方法1: 等待删除按钮出现（最可靠）
-	if err := waitForDeleteButton(page); err == nil {
-		log.Println("✅ 基于删除按钮检测，上传完成")
-		return nil
-	} else {
-		log.Printf("⚠️ 删除按钮检测失败: %v", err)
-		return err
-	}
+// uploadFinishAPIPatterns 视频分片上传完成时调用的接口
+var uploadFinishAPIPatterns = []string{"/completepartuploaddfs", "/completepartupload"}
 
-	return fmt.Errorf("上传超时")
+// uploadProgressSelectors 上传进度（百分比文字）
+var uploadProgressSelectors = []string{
+	".ant-progress-text",
+	"[class*='progress'] [class*='percent']",
+	"[class*='progress-text']",
+	"[class*='upload'] [class*='progress']",
 }
 
-// waitForDeleteButton 等待删除按钮出现
-func waitForDeleteButton(page playwright.Page) error {
-	log.Println("⏳ 等待删除按钮出现...")
+// uploadPercentPattern 进度文字中的百分比
+var uploadPercentPattern = regexp.MustCompile(`(\d{1,3})\s*%`)
 
-	startTime := time.Now()
-	maxWait := 120 // 2分钟
+// checkVideoUploadStatus 检查上传状态：以上传完成接口的响应为准，其次为进度达到 100%，
+// 都没有时按删除按钮判断（staleDelete 为设置文件前已有删除按钮，需等其消失后再出现）
+func checkVideoUploadStatus(page playwright.Page, responses <-chan actionResponse, staleDelete bool) error {
+	log.Println("=== 监控上传状态 ===")
 
-	for i := 0; i < maxWait; i++ {
+	startTime := time.Now()
+	for i := 1; time.Since(startTime) < uploadTimeout; i++ {
 		time.Sleep(2 * time.Second)
 
-		// 检查删除按钮
-		if hasDeleteButton(page) {
-			elapsed := time.Since(startTime)
-			log.Printf("✅ 删除按钮出现！等待时间: %v", elapsed)
+		select {
+		case response := <-responses:
+			if response.known && response.ok {
+				log.Printf("✅ 基于上传完成接口检测，上传完成！等待时间: %v", time.Since(startTime))
+				return nil
+			}
+			if response.known {
+				return fmt.Errorf("上传过程中出现错误: 接口返回 %s", response.message)
+			}
+		default:
+		}
+
+		percent, found := uploadProgress(page)
+		if found && percent >= 100 {
+			log.Printf("✅ 基于上传进度检测，上传完成！等待时间: %v", time.Since(startTime))
 			return nil
 		}
 
-		// 检查上传错误
-		if hasUploadError(page) {
-			return fmt.Errorf("上传过程中出现错误")
+		// 检查上传错误，错误信息中带上平台提示的原文
+		if message, found := pageErrorMessage(page, uploadErrorSelectors); found {
+			return platformError("上传过程中出现错误", message)
 		}
 
-		/*		// 定期报告状态
-				if (i+1)%5 == 0 {
-					progress := getCurrentProgress(page)
-					elapsed := time.Since(startTime)
-					log.Printf("⏳ 等待上传完成... 进度: %s, 已等待: %v", progress, elapsed)
-				}*/
+		// 之前暂存文件的删除按钮消失后，再出现的删除按钮才属于本次上传
+		deleteButton := hasDeleteButton(page)
+		if staleDelete && !deleteButton {
+			staleDelete = false
+		}
+		if !staleDelete && deleteButton {
+			log.Printf("✅ 基于删除按钮检测，上传完成！等待时间: %v", time.Since(startTime))
+			return nil
+		}
 
-		// 检查超时
-		if time.Since(startTime) > 5*time.Minute {
-			return fmt.Errorf("等待删除按钮超时（5分钟）")
+		// 定期报告状态
+		if i%5 == 0 && found {
+			log.Printf("⏳ 等待上传完成... 进度: %d%%, 已等待: %v", percent, time.Since(startTime).Round(time.Second))
 		}
 	}
+	return fmt.Errorf("等待上传完成超时（%v）", uploadTimeout)
+}
 
-	return fmt.Errorf("等待删除按钮超时")
+// uploadProgress 页面上显示的上传进度百分比，没有进度元素时返回 false
+func uploadProgress(page playwright.Page) (int, bool) {
+	for _, selector := range uploadProgressSelectors {
+		locator := page.Locator(selector).First()
+		if visible, _ := locator.IsVisible(); !visible {
+			continue
+		}
+		text, err := locator.TextContent()
+		if err != nil {
+			continue
+		}
+		if match := uploadPercentPattern.FindStringSubmatch(text); match != nil {
+			percent, _ := strconv.Atoi(match[1])
+			return percent, true
+		}
+	}
+	return 0, false
 }
 
 // hasDeleteButton 检查是否有删除按钮
@@ -873,36 +1090,8 @@ func hasDeleteButton(page playwright.Page) bool {
 	return false
 }
 
-// 🔥 新增：检查上传错误
-func hasUploadError(page playwright.Page) bool {
-	errorSelectors := []string{
-		".ant-upload-list-item-error",
-		".ant-alert-error",
-		".upload-error",
-		"text=上传失败",
-		"text=格式不支持",
-		"text=文件过大",
-		"text=网络错误",
-		"[class*='error']",
-	}
-
-	for _, selector := range errorSelectors {
-		locator := page.Locator(selector)
-		if visible, _ := locator.First().IsVisible(); visible {
-			// 如果URL不对，检查页面内容 - 🔥 使用 Locator
-			bodyLocator := page.Locator("body")
-			_, errorText := bodyLocator.TextContent()
-			if errorText != nil {
-				log.Printf("🚨 检测到上传错误: %s - %s", selector, errorText)
-				return true
-			}
-		}
-	}
-	return false
-}
-
-// performFinalAction 执行最终操作 - 修复版本
-func performFinalAction(page playwright.Page, action string, isScheduled bool) error {
+// performFinalAction 执行最终操作 - 修复版本，点击按钮和等待结果分别记录为 submit、verification 步骤
+func performFinalAction(page playwright.Page, action string, isScheduled bool, onStep StepFunc) error {
 	var buttonSelector string
 	var actionName string
 
@@ -931,12 +1120,13 @@ func performFinalAction(page playwright.Page, action string, isScheduled bool) e
 
 	log.Printf("🎯 准备执行操作: %s", actionName)
 
-	// 方法1: 等待按钮可用并点击
-	if err := waitAndClickButton(page, buttonSelector, actionName); err == nil {
-		return waitForActionCompletion(page, action, actionName)
-	}
+	// 点击前开始监听接口响应，以接口结果为准，页面提示作为后备
+	responses := expectActionResponse(page, action)
+
+	// 方法1: 等待按钮可用并点击，点击失败时仍等待结果（按钮可能已被点击）
+	runStep(onStep, StepSubmit, func() error { return waitAndClickButton(page, buttonSelector, actionName) })
 
-	return waitForActionCompletion(page, action, actionName)
+	return runStep(onStep, StepVerification, func() error { return waitForActionCompletion(page, action, actionName, responses) })
 }
 
 // cancelScheduledPublish 取消定时发表
@@ -1013,23 +1203,31 @@ func waitAndClickButton(page playwright.Page, selector string, actionName string
 	return nil
 }
 
-// waitForActionCompletion 等待操作完成
-func waitForActionCompletion(page playwright.Page, action string, actionName string) error {
+// waitForActionCompletion 等待操作完成：收到接口响应时以接口结果为准，未收到或无法识别时按页面提示判断
+func waitForActionCompletion(page playwright.Page, action string, actionName string, responses <-chan actionResponse) error {
 	log.Printf("⏳ 等待 %s 操作完成...", actionName)
 
-	maxWait := 300 // 30秒超时
+	maxWait := int(actionResponseTimeout / time.Second)
 	for i := 0; i < maxWait; i++ {
 		time.Sleep(1 * time.Second)
 
+		// 接口已返回结果时以接口为准，不受页面提示出现太快或太慢的影响
+		if done, err := checkActionResponse(responses, actionName, 0); done {
+			return err
+		}
+
 		// 检查操作成功
 		if isActionSuccessful(page, action) {
 			log.Printf("✅ %s 操作成功完成", actionName)
 			return nil
 		}
 
-		// 检查操作失败
-		if hasActionFailed(page, action) {
-			return fmt.Errorf("%s 操作失败", actionName)
+		// 检查操作失败，页面提示可能误判（如其他元素的错误样式），稍等接口结果，接口返回成功时以接口为准
+		if message, failed := pageErrorMessage(page, actionErrorSelectors); failed {
+			if done, err := checkActionResponse(responses, actionName, 5*time.Second); done {
+				return err
+			}
+			return platformError(actionName+" 操作失败", message)
 		}
 
 		if (i+1)%5 == 0 {
@@ -1037,6 +1235,9 @@ func waitForActionCompletion(page playwright.Page, action string, actionName str
 		}
 	}
 	// 最后再检查一次，避免在最后一次sleep时完成
+	if done, err := checkActionResponse(responses, actionName, 0); done {
+		return err
+	}
 	if isActionSuccessful(page, action) {
 		log.Printf("✅ %s 操作成功完成", actionName)
 		return nil
@@ -1100,75 +1301,25 @@ func isActionSuccessful(page playwright.Page, action string) bool {
 	return false
 }
 
-// hasActionFailed 检查操作是否失败
-func hasActionFailed(page playwright.Page, action string) bool {
-	errorIndicators := []string{
-		"text=保存失败",
-		"text=发表失败",
-		"text=操作失败",
-		"text=网络错误",
-		".ant-message-error",
-		".weui-desktop-message--error",
-		"[class*='error']",
-	}
-
-	for _, selector := range errorIndicators {
-		if count, _ := page.Locator(selector).Count(); count > 0 {
-			if visible, _ := page.Locator(selector).First().IsVisible(); visible {
-				errorText, _ := page.Locator(selector).First().TextContent()
-				log.Printf("🚨 检测到操作失败: %s - %s", selector, errorText)
-				return true
-			}
-		}
-	}
-	return false
-}
+// timingChain 定时发表的单选按钮
+var timingChain = SelectorChain{Name: "schedule_timing", Selectors: []string{
+	"//label[.//span[contains(text(), '定时')]]",
+	"//label[.//input[@value='1']]",
+	"label:has(input[value='1'])",
+	".weui-desktop-form__check-label:has(input[value='1'])",
+	"input.weui-desktop-form__radio[value='1']",
+}}
 
-// setScheduledPublish 设置定时发表
-func setScheduledPublish(page playwright.Page, scheduleTime string) error {
+// setScheduledPublish 设置定时发表，返回日期输入框中最终显示的定时时间（未指定定时时间时为零值）
+func setScheduledPublish(page playwright.Page, scheduleTime string, minutes minuteSelection) (time.Time, error) {
 	log.Println("⏰ 开始设置定时发表...")
 
-	// 方法1: 点击包含radio的label（正确方法）
-	timingSelectors := []string{
-		"//label[.//span[contains(text(), '定时')]]",
-		"//label[.//input[@value='1']]",
-		"label:has(input[value='1'])",
-		".weui-desktop-form__check-label:has(input[value='1'])",
-	}
-
-	var timingLocator playwright.Locator
-	var found bool
-
-	// 尝试多种选择器
-	for _, selector := range timingSelectors {
-		if strings.HasPrefix(selector, "//") {
-			timingLocator = page.Locator(fmt.Sprintf("xpath=%s", selector))
-		} else {
-			timingLocator = page.Locator(selector)
-		}
-
-		if count, err := timingLocator.Count(); err == nil && count > 0 {
-			log.Printf("✅ 找到定时按钮label，选择器: %s", selector)
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		// 方法2: 直接点击radio input
-		radioSelector := "input.weui-desktop-form__radio[value='1']"
-		radioLocator := page.Locator(radioSelector)
-		if count, err := radioLocator.Count(); err == nil && count > 0 {
-			log.Printf("✅ 找到radio按钮，直接点击")
-			timingLocator = radioLocator
-			found = true
-		}
-	}
-
-	if !found {
+	// 优先点击包含radio的label，找不到时直接点击radio input
+	timingLocator, err := timingChain.Find(page)
+	if err != nil {
 		log.Println("❌ 未找到定时发表相关元素")
 		debugScheduledPublishElements(page)
-		return fmt.Errorf("未找到定时发表按钮")
+		return time.Time{}, fmt.Errorf("未找到定时发表按钮")
 	}
 
 	// 确保元素可见
@@ -1177,7 +1328,7 @@ func setScheduledPublish(page playwright.Page, scheduleTime string) error {
 		Timeout: playwright.Float(30000),
 	}); err != nil {
 		log.Printf("❌ 等待元素可见失败: %v", err)
-		return fmt.Errorf("定时发表按钮不可见: %v", err)
+		return time.Time{}, fmt.Errorf("定时发表按钮不可见: %v", err)
 	}
 
 	// 滚动到元素可见
@@ -1205,7 +1356,7 @@ func setScheduledPublish(page playwright.Page, scheduleTime string) error {
 		Force:   playwright.Bool(true),
 	}); err != nil {
 		log.Printf("❌ 点击失败: %v", err)
-		return fmt.Errorf("点击定时发表按钮失败: %v", err)
+		return time.Time{}, fmt.Errorf("点击定时发表按钮失败: %v", err)
 	}
 
 	log.Println("✅ 点击完成，等待页面响应...")
@@ -1247,15 +1398,16 @@ func setScheduledPublish(page playwright.Page, scheduleTime string) error {
 	}
 
 	// 如果有定时时间，设置具体时间
-	if scheduleTime != "" {
-		log.Printf("⏰ 设置定时时间: %s", scheduleTime)
-		if err := setScheduleTime(page, scheduleTime); err != nil {
-			return fmt.Errorf("设置定时时间失败: %v", err)
-		}
-		log.Println("✅ 定时时间设置成功")
+	if scheduleTime == "" {
+		return time.Time{}, nil
 	}
-
-	return nil
+	log.Printf("⏰ 设置定时时间: %s", scheduleTime)
+	scheduledAt, err := setScheduleTime(page, scheduleTime, minutes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("设置定时时间失败: %v", err)
+	}
+	log.Println("✅ 定时时间设置成功")
+	return scheduledAt, nil
 }
 
 // debugScheduledPublishElements 调试定时发表相关元素
@@ -1286,8 +1438,8 @@ func debugScheduledPublishElements(page playwright.Page) {
 	}
 }
 
-// setScheduleTime 设置具体的定时时间
-func setScheduleTime(page playwright.Page, scheduleTime string) error {
+// setScheduleTime 设置具体的定时时间，设置后读取日期输入框校验最终显示的时间，返回该时间
+func setScheduleTime(page playwright.Page, scheduleTime string, minutes minuteSelection) (time.Time, error) {
 	log.Printf("⏰ 设置定时时间: '%s'", scheduleTime)
 
 	// 直接使用正则提取所有数字，然后重新构建
@@ -1307,18 +1459,31 @@ func setScheduleTime(page playwright.Page, scheduleTime string) error {
 
 		targetTime, err := time.Parse("2006/01/02 15:04", formattedTime)
 		if err != nil {
-			return fmt.Errorf("解析时间失败: %v", err)
+			return time.Time{}, fmt.Errorf("解析时间失败: %v", err)
 		}
 
 		log.Printf("✅ 时间解析成功: %s", targetTime.Format("2006-01-02 15:04:05"))
-		return setDateTimePicker(page, targetTime)
+
+		// 分钟被调整时按调整后的分钟校验
+		expected := targetTime
+		onAdjust := minutes.OnAdjust
+		minutes.OnAdjust = func(requested int, actual int) {
+			expected = expected.Add(time.Duration(actual-requested) * time.Minute)
+			if onAdjust != nil {
+				onAdjust(requested, actual)
+			}
+		}
+		if err := setDateTimePicker(page, targetTime, minutes); err != nil {
+			return time.Time{}, err
+		}
+		return verifyScheduleInput(page, expected)
 	}
 
-	return fmt.Errorf("无法从字符串中提取时间信息: %s", scheduleTime)
+	return time.Time{}, fmt.Errorf("无法从字符串中提取时间信息: %s", scheduleTime)
 }
 
 // setDateTimePicker 设置日期时间选择器
-func setDateTimePicker(page playwright.Page, targetTime time.Time) error {
+func setDateTimePicker(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	log.Printf("📅 开始设置日期时间: %s", targetTime.Format("2006-01-02 15:04"))
 
 	// 点击日期时间选择器输入框
@@ -1335,7 +1500,7 @@ func setDateTimePicker(page playwright.Page, targetTime time.Time) error {
 	time.Sleep(3 * time.Second)
 
 	// 检测当前打开的面板类型并设置日期时间
-	if err := detectAndSetDateTime(page, targetTime); err != nil {
+	if err := detectAndSetDateTime(page, targetTime, minutes); err != nil {
 		return fmt.Errorf("设置日期时间失败: %v", err)
 	}
 
@@ -1348,7 +1513,7 @@ func setDateTimePicker(page playwright.Page, targetTime time.Time) error {
 }
 
 // detectAndSetDateTime 检测面板类型并设置日期时间
-func detectAndSetDateTime(page playwright.Page, targetTime time.Time) error {
+func detectAndSetDateTime(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	// 检测当前显示的面板类型
 	panelTypes := []string{
 		".weui-desktop-picker__panel_year",  // 年份选择面板
@@ -1373,27 +1538,27 @@ func detectAndSetDateTime(page playwright.Page, targetTime time.Time) error {
 
 	if currentPanel == "" {
 		log.Println("⚠️ 未检测到面板类型，尝试默认日期设置")
-		return setFullDateTime(page, targetTime)
+		return setFullDateTime(page, targetTime, minutes)
 	}
 
 	// 根据面板类型进行设置
 	switch currentPanel {
 	case ".weui-desktop-picker__panel_year":
 		log.Println("📅 当前在年份选择面板")
-		return setDateTimeFromYearPanel(page, targetTime)
+		return setDateTimeFromYearPanel(page, targetTime, minutes)
 	case ".weui-desktop-picker__panel_month":
 		log.Println("📅 当前在月份选择面板")
-		return setDateTimeFromMonthPanel(page, targetTime)
+		return setDateTimeFromMonthPanel(page, targetTime, minutes)
 	case ".weui-desktop-picker__panel_day":
 		log.Println("📅 当前在日期选择面板")
-		return setDateTimeFromDayPanel(page, targetTime)
+		return setDateTimeFromDayPanel(page, targetTime, minutes)
 	default:
-		return setFullDateTime(page, targetTime)
+		return setFullDateTime(page, targetTime, minutes)
 	}
 }
 
 // setDateTimeFromYearPanel 从年份面板开始设置完整日期时间
-func setDateTimeFromYearPanel(page playwright.Page, targetTime time.Time) error {
+func setDateTimeFromYearPanel(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	year := targetTime.Year()
 
 	log.Printf("🗓️ 设置年份: %d", year)
@@ -1418,17 +1583,17 @@ func setDateTimeFromYearPanel(page playwright.Page, targetTime time.Time) error
 	time.Sleep(3 * time.Second) // 等待切换到月份面板
 
 	// 继续设置月份和日期
-	return setDateTimeFromMonthPanel(page, targetTime)
+	return setDateTimeFromMonthPanel(page, targetTime, minutes)
 }
 
 // setDateTimeFromMonthPanel 从月份面板开始设置日期时间 - 简化版
-func setDateTimeFromMonthPanel(page playwright.Page, targetTime time.Time) error {
+func setDateTimeFromMonthPanel(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	targetMonth := int(targetTime.Month())
 
 	log.Printf("🗓️ 设置月份: %d月", targetMonth)
 
 	// 直接使用箭头切换月份
-	if err := selectSpecificMonth(page, targetMonth); err != nil {
+	if err := selectSpecificMonth(page, targetTime.Year(), targetMonth); err != nil {
 		return fmt.Errorf("选择月份失败: %v", err)
 	}
 
@@ -1436,81 +1601,106 @@ func setDateTimeFromMonthPanel(page playwright.Page, targetTime time.Time) error
 	time.Sleep(2 * time.Second) // 等待日期面板刷新
 
 	// 继续设置日期
-	return setDateTimeFromDayPanel(page, targetTime)
+	return setDateTimeFromDayPanel(page, targetTime, minutes)
 }
 
-// selectSpecificMonth 选择具体月份 - 通过点击箭头切换，不重复点击月份标签
-func selectSpecificMonth(page playwright.Page, targetMonth int) error {
-	log.Printf("📅 选择月份: %d月", targetMonth)
+// selectSpecificMonth 选择具体年月 - 通过点击左右箭头逐月切换，不重复点击月份标签；
+// 每次点击后重新读取显示的年月，跨年时同样按实际相差的月数切换
+func selectSpecificMonth(page playwright.Page, targetYear int, targetMonth int) error {
+	log.Printf("📅 选择月份: %d年%d月", targetYear, targetMonth)
 
-	// 获取当前显示的月份
-	currentMonth, err := getCurrentMonth(page)
+	// 获取当前显示的年月，日期选择器打开时显示当月，读不到年份时按今年计算
+	currentYear, currentMonth, err := getCurrentYearMonth(page, time.Now().Year())
 	if err != nil {
-		return fmt.Errorf("获取当前月份失败: %v", err)
+		return err
 	}
+	log.Printf("🔍 当前: %d年%d月, 目标: %d年%d月", currentYear, currentMonth, targetYear, targetMonth)
 
-	log.Printf("🔍 当前月份: %d, 目标月份: %d", currentMonth, targetMonth)
-
-	if currentMonth == targetMonth {
-		log.Printf("✅ 已经是目标月份: %d", targetMonth)
+	steps := monthSteps(currentYear, currentMonth, targetYear, targetMonth)
+	if steps == 0 {
+		log.Printf("✅ 已经是目标月份: %d年%d月", targetYear, targetMonth)
 		return nil
 	}
 
-	// 计算需要点击的次数
-	diff := targetMonth - currentMonth
-	if diff < 0 {
-		diff += 12 // 处理跨年情况
+	// 目标在后面点右箭头，在前面点左箭头
+	arrowSelector, arrowName, direction := ".weui-desktop-btn__icon__right", "右箭头", 1
+	if steps < 0 {
+		arrowSelector, arrowName, direction = ".weui-desktop-btn__icon__left", "左箭头", -1
+		steps = -steps
 	}
+	log.Printf("🔄 需要点击%s %d 次", arrowName, steps)
 
-	log.Printf("🔄 需要点击右箭头 %d 次", diff)
-
-	// 获取右箭头按钮
-	rightArrow := page.Locator(".weui-desktop-btn__icon__right").First()
-	if count, _ := rightArrow.Count(); count == 0 {
-		return fmt.Errorf("未找到右箭头按钮")
+	arrow := page.Locator(arrowSelector).First()
+	if count, _ := arrow.Count(); count == 0 {
+		return fmt.Errorf("未找到%s按钮", arrowName)
 	}
 
-	// 点击右箭头切换到目标月份
-	for i := 0; i < diff; i++ {
-		log.Printf("🖱️ 点击右箭头 (%d/%d)", i+1, diff)
-		if err := rightArrow.Click(playwright.LocatorClickOptions{
+	for i := 0; i < steps; i++ {
+		log.Printf("🖱️ 点击%s (%d/%d)", arrowName, i+1, steps)
+		if err := arrow.Click(playwright.LocatorClickOptions{
 			Timeout: playwright.Float(5000),
 		}); err != nil {
-			return fmt.Errorf("点击右箭头失败: %v", err)
+			return fmt.Errorf("点击%s失败: %v", arrowName, err)
 		}
 		time.Sleep(1 * time.Second) // 等待月份切换
 
-		// 检查当前月份
-		current, err := getCurrentMonth(page)
-		if err == nil {
-			log.Printf("📅 当前月份: %d", current)
+		// 每次点击后确认切换了一个月，避免多切或漏切后继续按原次数点击；
+		// 读不到年份时按上次显示的年月切换一个月后的年份计算
+		expectedYear, _ := addMonths(currentYear, currentMonth, direction)
+		year, month, err := getCurrentYearMonth(page, expectedYear)
+		if err != nil {
+			return err
+		}
+		remaining := monthSteps(year, month, targetYear, targetMonth)
+		if remaining < 0 {
+			remaining = -remaining
 		}
+		if remaining != steps-i-1 {
+			return fmt.Errorf("月份切换异常，点击后显示 %d年%d月, 目标: %d年%d月", year, month, targetYear, targetMonth)
+		}
+		log.Printf("📅 当前: %d年%d月", year, month)
+		currentYear, currentMonth = year, month
 	}
 
-	// 验证最终月份
-	finalMonth, err := getCurrentMonth(page)
+	log.Printf("✅ 月份切换成功: %d年%d月", targetYear, targetMonth)
+	return nil
+}
+
+// getCurrentYearMonth 获取当前显示的年月；年份标签无法读取时按 fallbackYear 计算
+func getCurrentYearMonth(page playwright.Page, fallbackYear int) (int, int, error) {
+	month, err := getCurrentMonth(page)
 	if err != nil {
-		return fmt.Errorf("验证最终月份失败: %v", err)
+		return 0, 0, fmt.Errorf("获取当前月份失败: %v", err)
 	}
-
-	if finalMonth == targetMonth {
-		log.Printf("✅ 月份切换成功: %d月", targetMonth)
-		return nil
-	} else {
-		return fmt.Errorf("月份切换失败，当前: %d, 目标: %d", finalMonth, targetMonth)
+	year, err := getCurrentYear(page)
+	if err != nil {
+		log.Printf("⚠️ 获取当前年份失败，按 %d 年计算: %v", fallbackYear, err)
+		year = fallbackYear
 	}
+	return year, month, nil
+}
+
+// monthSteps 从一个年月切换到另一个年月需要的月数，向后为正、向前为负
+func monthSteps(fromYear int, fromMonth int, toYear int, toMonth int) int {
+	return (toYear-fromYear)*12 + toMonth - fromMonth
 }
 
-// navigateToMonth 导航到指定月份 - 简化版，只使用箭头切换
-func navigateToMonth(page playwright.Page, targetMonth int) error {
-	log.Printf("🌍 导航到月份: %d", targetMonth)
+// addMonths 年月加上 n 个月（n 可以为负），跨年时年份随之变化
+func addMonths(year int, month int, n int) (int, int) {
+	total := year*12 + month - 1 + n
+	return total / 12, total%12 + 1
+}
+
+// navigateToMonth 导航到指定年月 - 简化版，只使用箭头切换
+func navigateToMonth(page playwright.Page, targetYear int, targetMonth int) error {
+	log.Printf("🌍 导航到月份: %d年%d月", targetYear, targetMonth)
 
 	// 直接使用箭头切换月份，不需要切换到月份选择面板
-	return selectSpecificMonth(page, targetMonth)
+	return selectSpecificMonth(page, targetYear, targetMonth)
 }
 
 // setDateTimeFromDayPanel 从日期面板设置日期和时间 - 修正版
-func setDateTimeFromDayPanel(page playwright.Page, targetTime time.Time) error {
+func setDateTimeFromDayPanel(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	targetDay := targetTime.Day()
 	targetMonth := int(targetTime.Month())
 	targetYear := targetTime.Year()
@@ -1535,7 +1725,7 @@ func setDateTimeFromDayPanel(page playwright.Page, targetTime time.Time) error {
 	time.Sleep(2 * time.Second)
 
 	// 设置时间
-	return setTimeSelection(page, targetTime)
+	return setTimeSelection(page, targetTime, minutes)
 }
 
 // verifyCurrentYearAndMonth 验证当前显示的年份和月份
@@ -1721,7 +1911,7 @@ func verifyDaySelectionByNumber(page playwright.Page, expectedDay int) error {
 }
 
 // 在setFullDateTime中使用优化版本
-func setFullDateTime(page playwright.Page, targetTime time.Time) error {
+func setFullDateTime(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	targetYear := targetTime.Year()
 	targetMonth := int(targetTime.Month())
 	targetDay := targetTime.Day()
@@ -1748,7 +1938,7 @@ func setFullDateTime(page playwright.Page, targetTime time.Time) error {
 
 	// 3. 设置时间
 	log.Printf("⏱️ 设置时间: %02d:%02d", targetHour, targetMinute)
-	if err := setTimeSelection(page, targetTime); err != nil {
+	if err := setTimeSelection(page, targetTime, minutes); err != nil {
 		return fmt.Errorf("设置时间失败: %v", err)
 	}
 
@@ -1834,7 +2024,7 @@ func navigateToYear(page playwright.Page, targetYear int) error {
 }
 
 // setTimeSelection 设置时间选择 - 针对这个特定时间控件
-func setTimeSelection(page playwright.Page, targetTime time.Time) error {
+func setTimeSelection(page playwright.Page, targetTime time.Time, minutes minuteSelection) error {
 	hour := targetTime.Hour()
 	minute := targetTime.Minute()
 
@@ -1851,7 +2041,7 @@ func setTimeSelection(page playwright.Page, targetTime time.Time) error {
 	}
 
 	// 3. 设置分钟
-	if err := setMinuteWithScroll(page, minute); err != nil {
+	if err := setMinuteWithScroll(page, minute, minutes); err != nil {
 		return fmt.Errorf("设置分钟失败: %v", err)
 	}
 
@@ -1942,7 +2132,11 @@ func setHourWithScroll(page playwright.Page, hour int) error {
 }
 
 // setMinuteWithScroll 设置分钟（支持滚动选择）
-func setMinuteWithScroll(page playwright.Page, minute int) error {
+func setMinuteWithScroll(page playwright.Page, minute int, minutes minuteSelection) error {
+	minute, err := resolveMinute(page, minute, minutes)
+	if err != nil {
+		return err
+	}
 	minuteStr := fmt.Sprintf("%02d", minute)
 
 	log.Printf("⏰ 设置分钟: %s", minuteStr)
@@ -2111,7 +2305,7 @@ func navigateToYearAndMonth(page playwright.Page, targetTime time.Time) error {
 	}
 
 	// 然后设置月份
-	if err := navigateToMonth(page, targetMonth); err != nil {
+	if err := navigateToMonth(page, targetYear, targetMonth); err != nil {
 		return fmt.Errorf("设置月份失败: %v", err)
 	}
 
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestMonthSteps(t *testing.T) {
+	tests := []struct {
+		name                  string
+		fromYear, fromMonth   int
+		toYear, toMonth, want int
+	}{
+		{"同一个月", 2025, 6, 2025, 6, 0},
+		{"同年向后", 2025, 3, 2025, 7, 4},
+		{"同年向前", 2025, 7, 2025, 3, -4},
+		{"跨年向后", 2025, 12, 2026, 1, 1},
+		{"跨年向后多月", 2025, 11, 2026, 2, 3},
+		{"跨年向前", 2026, 1, 2025, 12, -1},
+		{"跨年向前多月", 2026, 2, 2025, 11, -3},
+		{"相差一年以上", 2025, 6, 2027, 7, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monthSteps(tt.fromYear, tt.fromMonth, tt.toYear, tt.toMonth); got != tt.want {
+				t.Errorf("monthSteps(%d, %d, %d, %d) = %d, want %d", tt.fromYear, tt.fromMonth, tt.toYear, tt.toMonth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		year, month, n      int
+		wantYear, wantMonth int
+	}{
+		{2025, 6, 1, 2025, 7},
+		{2025, 12, 1, 2026, 1},
+		{2026, 1, -1, 2025, 12},
+		{2025, 11, 3, 2026, 2},
+		{2026, 2, -3, 2025, 11},
+		{2025, 6, 0, 2025, 6},
+	}
+	for _, tt := range tests {
+		year, month := addMonths(tt.year, tt.month, tt.n)
+		if year != tt.wantYear || month != tt.wantMonth {
+			t.Errorf("addMonths(%d, %d, %d) = %d年%d月, want %d年%d月", tt.year, tt.month, tt.n, year, month, tt.wantYear, tt.wantMonth)
+		}
+		if steps := monthSteps(tt.year, tt.month, year, month); steps != tt.n {
+			t.Errorf("monthSteps 与 addMonths(%d, %d, %d) 不一致: %d", tt.year, tt.month, tt.n, steps)
+		}
+	}
+}
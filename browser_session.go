@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// maxCrashRetries 页面或浏览器崩溃后单个任务的最大重试次数
+const maxCrashRetries = 1
+
+// browserSession 一个独立的浏览器进程及其上下文，崩溃后可重新启动并恢复认证
+type browserSession struct {
+	mu         sync.Mutex
+	options    BrowserOptions
+	authState  *PageState
+	generation int // 每次重新启动后加一，用于避免并发任务重复恢复
+
+	pw      *playwright.Playwright
+	browser *playwright.Browser
+	context *playwright.BrowserContext
+}
+
+// newBrowserSession 启动浏览器并恢复从扫码登录获取的授权信息
+func newBrowserSession(options BrowserOptions, authState *PageState) (*browserSession, error) {
+	s := &browserSession{options: options, authState: authState}
+	if err := s.launch(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// launch 启动浏览器并恢复认证信息
+func (s *browserSession) launch() error {
+	pw, browser, context, err := GenerateBrowser(s.browserOptions())
+	if err != nil {
+		return err
+	}
+	restoreAuthState(*context, s.authState)
+	s.pw, s.browser, s.context = pw, browser, context
+	return nil
+}
+
+// browserOptions 返回创建上下文的设置，固定 UA 时与登录时的 UA 和 UA-CH 保持一致
+func (s *browserSession) browserOptions() BrowserOptions {
+	options := s.options
+	if options.Context.PinUserAgent && s.authState != nil && s.authState.UserAgent != nil {
+		options.UserAgent = s.authState.UserAgent
+	}
+	return options
+}
+
+// SwitchProxy 关闭当前上下文，使用新的代理重新创建上下文并恢复认证，之后浏览器重新启动时也使用该代理
+func (s *browserSession) SwitchProxy(proxy *playwright.Proxy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.options.Proxy = proxy
+	context, err := newBrowserContext(*s.browser, s.browserOptions())
+	if err != nil {
+		return fmt.Errorf("切换代理失败: %v", err)
+	}
+	(*s.context).Close()
+	restoreAuthState(context, s.authState)
+	s.context = &context
+	s.generation++
+	return nil
+}
+
+// taskContext 按任务轮换代理时为任务新建使用下一个代理的上下文，否则返回共用的上下文；
+// 任务结束后调用 release 关闭新建的上下文
+func (s *browserSession) taskContext(proxies *ProxyPool) (*playwright.BrowserContext, int, func(), error) {
+	if !proxies.PerTask() {
+		context, generation := s.Context()
+		return context, generation, func() {}, nil
+	}
+	proxy, err := proxies.NextTask()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	options := s.browserOptions()
+	options.Proxy = proxy
+	context, err := newBrowserContext(*s.browser, options)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	restoreAuthState(context, s.authState)
+	return &context, s.generation, func() { context.Close() }, nil
+}
+
+// Context 返回当前的浏览器上下文及其代数
+func (s *browserSession) Context() (*playwright.BrowserContext, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.context, s.generation
+}
+
+// Recover 浏览器崩溃后重新启动并恢复认证；代数已变化说明其他任务已完成恢复
+func (s *browserSession) Recover(generation int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generation != generation {
+		return nil
+	}
+	if (*s.browser).IsConnected() {
+		// 只是页面崩溃，浏览器仍可用，新建页面即可
+		return nil
+	}
+
+	log.Println("🔄 浏览器已断开，重新启动并恢复登录状态...")
+	s.closeLocked()
+	if err := s.launch(); err != nil {
+		return fmt.Errorf("重新启动浏览器失败: %v", err)
+	}
+	s.generation++
+	log.Println("✅ 浏览器已重新启动")
+	return nil
+}
+
+// Restore 重新登录后清除旧的 cookies 并恢复新的认证状态，之后浏览器重新启动时也使用新的认证状态
+func (s *browserSession) Restore(authState *PageState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.authState = authState
+	if err := (*s.context).ClearCookies(); err != nil {
+		return fmt.Errorf("清除旧的cookies失败: %v", err)
+	}
+	restoreAuthState(*s.context, authState)
+	return nil
+}
+
+// isCrashed 判断任务失败是否由页面或浏览器崩溃导致
+func (s *browserSession) isCrashed(page *playwright.Page, errMessage string) bool {
+	if page != nil && (*page).IsClosed() {
+		return true
+	}
+	s.mu.Lock()
+	connected := (*s.browser).IsConnected()
+	s.mu.Unlock()
+	return !connected || isTargetClosedMessage(errMessage)
+}
+
+// Close 关闭上下文、浏览器和 Playwright，为 nil 时不执行
+func (s *browserSession) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked()
+}
+
+func (s *browserSession) closeLocked() {
+	(*s.context).Close()
+	(*s.browser).Close()
+	s.pw.Stop()
+}
+
+// isTargetClosedMessage 错误信息是否为页面/浏览器已关闭或崩溃
+func isTargetClosedMessage(message string) bool {
+	message = strings.ToLower(message)
+	crashMessages := []string{
+		"target closed",
+		"target page, context or browser has been closed",
+		"browser has been closed",
+		"browser has disconnected",
+		"page crashed",
+	}
+	for _, crashMessage := range crashMessages {
+		if strings.Contains(message, crashMessage) {
+			return true
+		}
+	}
+	return false
+}
+
+// runTaskOnNewPage 在新页面中执行单个任务，页面或浏览器崩溃时恢复后重试，登录失效时重新登录后重试
+func runTaskOnNewPage(session *browserSession, result *TaskResult, options ProcessOptions, reauth *reauthenticator) {
+	crashRetries, relogged := 0, false
+	for {
+		reauth.wait()
+		loginGeneration := reauth.Generation()
+		result.beginAttempt()
+
+		var page *playwright.Page
+		generation, release := 0, func() {}
+		err := result.Step(StepNavigation, func() error {
+			context, contextGeneration, closeContext, err := session.taskContext(options.Proxies)
+			if err != nil {
+				return err
+			}
+			generation, release = contextGeneration, closeContext
+			var pageError error
+			page, result.ChannelName, pageError = GeneratePage(context, options.Page)
+			return pageError
+		})
+		if err == nil {
+			// 上传视频和填充值表单并保存
+			err = createVideo(page, result, options)
+		}
+		if err == nil {
+			options.Pacing.waitAfterAction()
+			result.Success = true
+			result.Error = ""
+			// 保留的页面交给操作人员，运行结束前不关闭
+			if !result.Staged || options.staged == nil {
+				(*page).Close()
+				release()
+			}
+			return
+		}
+		result.Fail(err)
+
+		// 先判断是否崩溃再关闭页面
+		crashed := session.isCrashed(page, result.Error)
+		if page != nil {
+			(*page).Close()
+		}
+		release()
+
+		switch {
+		case crashed && crashRetries < maxCrashRetries:
+			crashRetries++
+			log.Printf("💥 第%d行任务执行中页面或浏览器崩溃，恢复后重试: %s", result.Task.RowIndex, result.Error)
+			result.Event("crash_recovery", err)
+			if err := session.Recover(generation); err != nil {
+				result.Error = fmt.Sprintf("%s; %v", result.Error, err)
+				return
+			}
+		case !crashed && !relogged && reauth.canRetry(result.Error):
+			relogged = true
+			log.Printf("🔐 第%d行任务登录信息失效，重新登录后重试: %s", result.Task.RowIndex, result.Error)
+			result.Event("relogin", err)
+			if err := reauth.Reauth(loginGeneration); err != nil {
+				result.Error = fmt.Sprintf("%s; %v", result.Error, err)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// bundleManifestName 离线包内校验清单文件名
+const bundleManifestName = "bundle-manifest.json"
+
+// BundleManifest 离线包校验清单
+type BundleManifest struct {
+	ChromiumRevision string            `json:"chromium_revision"`
+	CreatedAt        string            `json:"created_at"`
+	Files            map[string]string `json:"files"` // 相对路径 -> sha256
+}
+
+// runBundleCommand 处理 bundle 子命令：在联网机器上下载浏览器并打包为离线安装用的ZIP
+func runBundleCommand(args []string) error {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	output := fs.String("o", "ms-playwright.zip", "输出的ZIP文件路径")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// 下载到临时目录，避免污染本机已有的浏览器环境
+	browsersDir, err := os.MkdirTemp("", "ms-playwright-bundle-")
+	if err != nil {
+		return fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(browsersDir)
+
+	log.Printf("📥 下载浏览器到临时目录: %s", browsersDir)
+	if err := os.Setenv("PLAYWRIGHT_BROWSERS_PATH", browsersDir); err != nil {
+		return fmt.Errorf("设置浏览器目录失败: %v", err)
+	}
+	if err := playwright.Install(&playwright.RunOptions{Browsers: []string{"chromium"}}); err != nil {
+		return fmt.Errorf("下载浏览器失败: %v", err)
+	}
+
+	// 校验下载的版本与离线安装检查的版本一致
+	chromiumDir := filepath.Join(browsersDir, playwrightChromiumRevision)
+	if _, err := os.Stat(chromiumDir); err != nil {
+		return fmt.Errorf("下载的浏览器版本与所需版本 %s 不一致", playwrightChromiumRevision)
+	}
+
+	log.Printf("📦 打包到: %s", *output)
+	if err := zipDirWithManifest(browsersDir, *output); err != nil {
+		return fmt.Errorf("打包失败: %v", err)
+	}
+
+	log.Printf("✅ 离线包生成完成: %s", *output)
+	return nil
+}
+
+// zipDirWithManifest 将目录打包为ZIP，并写入每个文件的sha256校验清单
+func zipDirWithManifest(srcDir, zipPath string) (err error) {
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	// 打包失败时删除写了一半的ZIP，避免被当作完整的离线包使用
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(zipPath)
+		}
+	}()
+
+	zw := zip.NewWriter(out)
+	manifest := BundleManifest{
+		ChromiumRevision: playwrightChromiumRevision,
+		CreatedAt:        time.Now().Format(time.RFC3339),
+		Files:            make(map[string]string),
+	}
+
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = zw.CreateHeader(header)
+			return err
+		}
+		// 符号链接（如 macOS 的 Framework）按链接保存，内容为链接目标，不复制目标文件
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			target = filepath.ToSlash(target)
+			if !symlinkInside(name, target) {
+				return fmt.Errorf("符号链接 %s 指向打包目录之外: %s", name, target)
+			}
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, target)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		sum, err := copyWithChecksum(w, path)
+		if err != nil {
+			return err
+		}
+		manifest.Files[name] = sum
+		return nil
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	w, err := zw.Create(bundleManifestName)
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		zw.Close()
+		return err
+	}
+
+	log.Printf("📝 校验清单: %d 个文件", len(manifest.Files))
+	return zw.Close()
+}
+
+// symlinkInside 包内路径为 name 的符号链接的目标 target 是否在包内：只允许不越出根目录的相对路径，
+// 且 .. 只能写在开头。中间的 ..（如 l/..）在 l 本身是符号链接时从链接的目标向上，按路径文字无法判断是否越出
+func symlinkInside(name, target string) bool {
+	if target == "" || path.IsAbs(target) || filepath.IsAbs(filepath.FromSlash(target)) {
+		return false
+	}
+	up, descended := 0, false
+	for _, part := range strings.FieldsFunc(target, func(r rune) bool { return r == '/' || r == '\\' }) {
+		switch part {
+		case ".":
+		case "..":
+			if descended {
+				return false
+			}
+			up++
+		default:
+			descended = true
+		}
+	}
+	// 开头的 .. 不能超过链接所在目录的层数
+	return up <= strings.Count(path.Clean(name), "/")
+}
+
+// copyWithChecksum 复制文件内容并返回sha256
+func copyWithChecksum(w io.Writer, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyBundleManifest 按校验清单检查解压后的文件，清单不存在时跳过（兼容旧的手工打包）
+func verifyBundleManifest(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, bundleManifestName))
+	if os.IsNotExist(err) {
+		log.Println("⚠️ ZIP 中没有校验清单，跳过校验")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取校验清单失败: %v", err)
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("解析校验清单失败: %v", err)
+	}
+	if manifest.ChromiumRevision != playwrightChromiumRevision {
+		return fmt.Errorf("离线包浏览器版本 %s 与所需版本 %s 不一致", manifest.ChromiumRevision, playwrightChromiumRevision)
+	}
+
+	names := make([]string, 0, len(manifest.Files))
+	for name := range manifest.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mismatched []string
+	for _, name := range names {
+		sum, err := copyWithChecksum(io.Discard, filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil || sum != manifest.Files[name] {
+			mismatched = append(mismatched, name)
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("%d 个文件校验失败: %s", len(mismatched), strings.Join(mismatched, ", "))
+	}
+
+	log.Printf("✅ 离线包校验通过: %d 个文件", len(names))
+	return nil
+}
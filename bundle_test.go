@@ -0,0 +1,98 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSymlinkInside(t *testing.T) {
+	tests := []struct {
+		name, target string
+		want         bool
+	}{
+		{"Chromium.app/Contents/Frameworks/F.framework/Versions/Current", "A", true},
+		{"F.framework/Resources", "Versions/Current/Resources", true},
+		{"d/l", "..", true},
+		{"d/l", "../..", false},
+		{"l", "..", false},
+		{"d/e/l", "../../x", true},
+		{"d/l", "x/../..", false},
+		{"d/l", "l2/..", false},
+		{"d/l", `..\..`, false},
+		{"d/l", "/etc", false},
+		{"d/l", "", false},
+	}
+	for _, tt := range tests {
+		if got := symlinkInside(tt.name, tt.target); got != tt.want {
+			t.Errorf("symlinkInside(%q, %q) = %v, want %v", tt.name, tt.target, got, tt.want)
+		}
+	}
+}
+
+// writeTestZip 按顺序写入条目，target 不为空时为符号链接
+func writeTestZip(t *testing.T, entries [][2]string) string {
+	t.Helper()
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	zw := zip.NewWriter(out)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry[0]}
+		header.SetMode(0644)
+		body := "data"
+		if entry[1] != "" {
+			header.SetMode(os.ModeSymlink | 0777)
+			body = entry[1]
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return zipPath
+}
+
+func TestUnzipRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries [][2]string
+	}{
+		{"条目名含 ..", [][2]string{{"../escaped", ""}}},
+		{"链接链越出", [][2]string{{"d/l", ".."}, {"d/l/l2", ".."}}},
+		{"经过链接写入", [][2]string{{"d/l", ".."}, {"d/l/file", ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "out")
+			if err := unzip(writeTestZip(t, tt.entries), dest); err == nil {
+				t.Fatal("unzip() error = nil")
+			}
+			if _, err := os.Lstat(filepath.Join(filepath.Dir(dest), "escaped")); err == nil {
+				t.Error("写到了解压目录之外")
+			}
+		})
+	}
+}
+
+func TestZipDirWithManifestRemovesPartialZip(t *testing.T) {
+	src := t.TempDir()
+	if err := os.Symlink("/etc", filepath.Join(src, "outside")); err != nil {
+		t.Skip(err)
+	}
+	zipPath := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := zipDirWithManifest(src, zipPath); err == nil {
+		t.Fatal("zipDirWithManifest() error = nil")
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Errorf("打包失败后仍留有 %s", zipPath)
+	}
+}
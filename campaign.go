@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// campaignColumn 投放活动列的表头，放在J列之后：只用于分组统计（结果、通知、上传历史），不填写到页面；
+// 与E列"活动"（参与平台活动）不同
+const campaignColumn = "投放活动"
+
+// noCampaign 未填写投放活动的任务归入的分组
+const noCampaign = "未分组"
+
+// taskCampaign 任务所属的投放活动
+func taskCampaign(task VideoCreateTask) string {
+	if name := strings.TrimSpace(task.Campaign); name != "" {
+		return name
+	}
+	return noCampaign
+}
+
+// campaignCount 投放活动的任务数统计
+type campaignCount struct {
+	Name      string `json:"name"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// countCampaigns 按投放活动统计成功、失败数，按首次出现的顺序返回；没有任务填写投放活动时返回 nil
+func countCampaigns(results []TaskResult) []campaignCount {
+	var counts []campaignCount
+	index := make(map[string]int)
+	grouped := false
+	for _, result := range results {
+		name := taskCampaign(result.Task)
+		grouped = grouped || name != noCampaign
+		i, ok := index[name]
+		if !ok {
+			i = len(counts)
+			index[name] = i
+			counts = append(counts, campaignCount{Name: name})
+		}
+		if result.Success {
+			counts[i].Succeeded++
+		} else {
+			counts[i].Failed++
+		}
+	}
+	if !grouped {
+		return nil
+	}
+	return counts
+}
+
+// campaignFilter 按投放活动过滤（逗号分隔，为空时不过滤）
+type campaignFilter map[string]bool
+
+// parseCampaignFilter 解析逗号分隔的投放活动列表，"未分组" 匹配未填写投放活动的任务
+func parseCampaignFilter(value string) campaignFilter {
+	var filter campaignFilter
+	for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '，' }) {
+		if name = strings.TrimSpace(name); name != "" {
+			if filter == nil {
+				filter = make(campaignFilter)
+			}
+			filter[name] = true
+		}
+	}
+	return filter
+}
+
+// match 任务是否属于过滤的投放活动，未设置过滤时全部匹配
+func (f campaignFilter) match(campaign string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	if strings.TrimSpace(campaign) == "" {
+		campaign = noCampaign
+	}
+	return f[strings.TrimSpace(campaign)]
+}
+
+// filterTasks 只保留属于过滤的投放活动的任务
+func (f campaignFilter) filterTasks(tasks []VideoCreateTask) []VideoCreateTask {
+	if len(f) == 0 {
+		return tasks
+	}
+	var kept []VideoCreateTask
+	for _, task := range tasks {
+		if f.match(task.Campaign) {
+			kept = append(kept, task)
+		}
+	}
+	return kept
+}
+
+// forCampaigns 只包含指定投放活动的任务的通知：失败和结束通知按过滤后的结果重新生成，没有相关任务时返回 false；
+// 其他事件（登录、安全验证等）不属于某个投放活动，原样发送
+func (e NotifyEvent) forCampaigns(filter campaignFilter) (NotifyEvent, bool) {
+	if len(filter) == 0 || (e.Type != EventOnFailure && e.Type != EventOnFinish) {
+		return e, true
+	}
+	var results []TaskResult
+	for _, result := range e.Results {
+		if filter.match(result.Task.Campaign) {
+			results = append(results, result)
+		}
+	}
+	if len(results) == 0 {
+		return e, false
+	}
+	filtered := failureEvent(results...)
+	if e.Type == EventOnFinish {
+		filtered = finishEvent(results)
+	}
+	filtered.Time = e.Time
+	return filtered, true
+}
+
+// runHistoryCommand 处理 history 子命令：按投放活动、账号、时间查询上传历史，并按投放活动和账号汇总
+func runHistoryCommand(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	path := fs.String("file", "upload_history.jsonl", "上传历史文件(上传时为 output-dir/upload_history.jsonl)")
+	campaigns := fs.String("campaign", "", "只查询这些投放活动, 逗号分隔(\"未分组\"为未填写投放活动的记录)")
+	account := fs.String("account", "", "只查询该账号(视频号名称)")
+	since := fs.String("since", "", "开始日期(含), 例如 2026-10-01")
+	until := fs.String("until", "", "结束日期(含), 例如 2026-10-31")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var from, to time.Time
+	var err error
+	if *since != "" {
+		if from, err = time.ParseInLocation("2006-01-02", *since, time.Local); err != nil {
+			return fmt.Errorf("-since 格式错误: %v", err)
+		}
+	}
+	if *until != "" {
+		if to, err = time.ParseInLocation("2006-01-02", *until, time.Local); err != nil {
+			return fmt.Errorf("-until 格式错误: %v", err)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	history, err := OpenUploadHistory(*path)
+	if err != nil {
+		return err
+	}
+
+	filter := parseCampaignFilter(*campaigns)
+	var names []string
+	counts := make(map[string]map[string]int) // 投放活动 -> 账号 -> 次数
+	total := 0
+	for _, record := range history.Records() {
+		if !filter.match(record.Campaign) || (*account != "" && record.Account != *account) ||
+			(!from.IsZero() && record.UploadedAt.Before(from)) || (!to.IsZero() && !record.UploadedAt.Before(to)) {
+			continue
+		}
+		campaign := taskCampaign(VideoCreateTask{Campaign: record.Campaign})
+		fmt.Printf("%s  %-12s %-12s %s\n", record.UploadedAt.Format("2006-01-02 15:04"), campaign, record.Account, record.VideoPath)
+		if counts[campaign] == nil {
+			counts[campaign] = make(map[string]int)
+			names = append(names, campaign)
+		}
+		counts[campaign][record.Account]++
+		total++
+	}
+
+	fmt.Printf("\n共 %d 条上传记录\n", total)
+	for _, campaign := range names {
+		sum := 0
+		var parts []string
+		for _, account := range slices.Sorted(maps.Keys(counts[campaign])) {
+			count := counts[campaign][account]
+			sum += count
+			if account == "" {
+				account = unknownAccount
+			}
+			parts = append(parts, fmt.Sprintf("%s %d", account, count))
+		}
+		fmt.Printf("  %s: %d (%s)\n", campaign, sum, strings.Join(parts, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 安全验证类型
+const (
+	CaptchaSlider = "slider" // 滑块验证
+	CaptchaSMS    = "sms"    // 短信验证码
+)
+
+// defaultCaptchaTimeout 默认等待人工完成安全验证的时间
+const defaultCaptchaTimeout = 10 * time.Minute
+
+// captchaPollInterval 检查安全验证是否已完成的间隔
+const captchaPollInterval = 2 * time.Second
+
+// captchaSelectors 安全验证弹窗的选择器，按类型匹配
+var captchaSelectors = []struct {
+	kind     string
+	selector string
+}{
+	{CaptchaSlider, "iframe[src*='captcha']"},
+	{CaptchaSlider, "#tcaptcha_transform_dy"},
+	{CaptchaSlider, "#tCaptchaDyContent"},
+	{CaptchaSMS, ".weui-desktop-dialog:has-text('短信验证')"},
+	{CaptchaSMS, ".weui-desktop-dialog:has-text('验证码')"},
+}
+
+// CaptchaOptions 出现安全验证时暂停任务等待处理的设置
+type CaptchaOptions struct {
+	Timeout time.Duration // 等待人工完成验证的最长时间，0 时为 10 分钟
+	Solver  CaptchaConfig // 打码命令，未配置时直接等待人工处理
+}
+
+// CaptchaChallenge 页面上出现的安全验证
+type CaptchaChallenge struct {
+	Kind       string // slider | sms
+	RowIndex   int    // 任务所在行
+	Screenshot string // 出现验证时的页面截图路径，截图失败时为空
+	ViewURL    string // 实时查看页面的地址，未启用时为空
+}
+
+// detectCaptcha 检查页面上是否有可见的安全验证，返回验证类型，没有时返回空
+func detectCaptcha(page playwright.Page) string {
+	for _, item := range captchaSelectors {
+		visible, err := page.Locator(item.selector).First().IsVisible()
+		if err == nil && visible {
+			return item.kind
+		}
+	}
+	return ""
+}
+
+// captchaStep 包装步骤函数：步骤开始前和失败后检查安全验证，等待处理完成后继续；
+// 步骤因验证失败时重新执行一次，点击提交按钮的步骤除外（验证完成后提交通常已继续）
+func captchaStep(page playwright.Page, result *TaskResult, options ProcessOptions, onStep StepFunc) StepFunc {
+	return func(name string, fn func() error) error {
+		return runStep(onStep, name, func() error {
+			if err := resolveCaptcha(page, result, options); err != nil {
+				return err
+			}
+			err := fn()
+			if err == nil || detectCaptcha(page) == "" {
+				return err
+			}
+			if resolveErr := resolveCaptcha(page, result, options); resolveErr != nil {
+				return fmt.Errorf("%v; %v", err, resolveErr)
+			}
+			if name == StepSubmit {
+				return err
+			}
+			return fn()
+		})
+	}
+}
+
+// resolveCaptcha 出现安全验证时截图，先交给配置的打码命令自动处理，未能完成时通知操作人员并暂停任务，
+// 验证消失后继续；超过等待时间时返回错误
+func resolveCaptcha(page playwright.Page, result *TaskResult, options ProcessOptions) error {
+	kind := detectCaptcha(page)
+	if kind == "" {
+		return nil
+	}
+
+	challenge := CaptchaChallenge{Kind: kind, RowIndex: result.Task.RowIndex}
+	log.Printf("🧩 第%d行任务出现安全验证(%s)，暂停等待处理", challenge.RowIndex, kind)
+	result.Event("captcha", nil)
+	if path, err := saveCaptchaScreenshot(page, challenge, options.OutputDir); err != nil {
+		log.Printf("⚠️ 安全验证截图失败: %v", err)
+	} else {
+		challenge.Screenshot = path
+	}
+	challenge.ViewURL = options.LiveView.server.URL(liveViewID(result))
+
+	// 自动处理（打码命令），未能完成时等待人工处理
+	if called, err := solveCaptcha(page, challenge, *result, options.Captcha.Solver); err != nil {
+		log.Printf("⚠️ 自动处理安全验证失败: %v", err)
+	} else if called && detectCaptcha(page) == "" {
+		log.Printf("✅ 第%d行任务安全验证已自动完成", challenge.RowIndex)
+		return nil
+	}
+
+	timeout := options.Captcha.Timeout
+	if timeout <= 0 {
+		timeout = defaultCaptchaTimeout
+	}
+	options.Notifier.Notify(captchaEvent(challenge, timeout, result))
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(captchaPollInterval)
+		if page.IsClosed() {
+			return fmt.Errorf("等待安全验证时页面已关闭")
+		}
+		if detectCaptcha(page) == "" {
+			log.Printf("✅ 第%d行任务安全验证已完成，继续执行", challenge.RowIndex)
+			return nil
+		}
+	}
+	return fmt.Errorf("安全验证未在%v内完成", timeout)
+}
+
+// saveCaptchaScreenshot 将出现验证时的页面截图保存到 output-dir/captcha/ 目录
+func saveCaptchaScreenshot(page playwright.Page, challenge CaptchaChallenge, outputDir string) (string, error) {
+	dir := filepath.Join(outputDir, "captcha")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("row%d_%s.png", challenge.RowIndex, time.Now().Format("20060102_150405")))
+	if _, err := page.Screenshot(playwright.PageScreenshotOptions{Path: playwright.String(path)}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// captchaEvent 生成需要处理安全验证的通知
+func captchaEvent(challenge CaptchaChallenge, timeout time.Duration, result *TaskResult) NotifyEvent {
+	kindName := map[string]string{CaptchaSlider: "滑块验证", CaptchaSMS: "短信验证"}[challenge.Kind]
+	message := fmt.Sprintf("第%d行任务出现%s，请在%v内完成，完成后任务自动继续", challenge.RowIndex, kindName, timeout)
+	if challenge.ViewURL != "" {
+		message += fmt.Sprintf("，实时查看: %s", challenge.ViewURL)
+	}
+	if challenge.Screenshot != "" {
+		message += fmt.Sprintf("，截图: %s", challenge.Screenshot)
+	}
+	return NotifyEvent{Type: EventOnCaptcha, Title: "需要处理安全验证", Message: message, Results: []TaskResult{*result}}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// defaultCaptchaSolverTimeout 打码命令的默认超时时间
+const defaultCaptchaSolverTimeout = 2 * time.Minute
+
+// captchaFrameSelector 滑块验证所在的 iframe
+const captchaFrameSelector = "iframe[src*='captcha']"
+
+// captchaSliderHandles 滑块验证的拖动按钮，先在验证 iframe 中查找，再在页面中查找
+var captchaSliderHandles = []string{
+	"#tcaptcha_drag_thumb",
+	".tc-slider-normal",
+	"[class*='slider'][class*='thumb']",
+}
+
+// 短信验证弹窗中的验证码输入框和确定按钮
+const (
+	captchaSMSInput   = ".weui-desktop-dialog input"
+	captchaSMSConfirm = ".weui-desktop-dialog button:has-text('确定')"
+)
+
+// CaptchaConfig 自动处理安全验证的打码命令（配置文件 captcha）：出现验证时先执行该命令，
+// 命令读取截图后在标准输出打印答案，程序按答案完成验证；未配置、命令失败或完成后验证仍在时通知人工处理
+type CaptchaConfig struct {
+	Solver  string        `yaml:"solver"`  // 打码命令，通过系统 shell 执行，验证信息通过 UPLOADER_CAPTCHA_ 开头的环境变量传入
+	Timeout time.Duration `yaml:"timeout"` // 打码命令的超时时间，默认 2 分钟
+}
+
+// Validate 检查超时时间
+func (c CaptchaConfig) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout 不能为负数")
+	}
+	return nil
+}
+
+// captchaAnswer 打码命令在标准输出打印的 JSON：滑块验证为拖动距离（页面像素），短信验证为验证码
+type captchaAnswer struct {
+	SliderOffset float64 `json:"slider_offset"`
+	SMSCode      string  `json:"sms_code"`
+}
+
+// solveCaptcha 执行打码命令并按答案操作页面，未配置命令时返回 false
+func solveCaptcha(page playwright.Page, challenge CaptchaChallenge, result TaskResult, config CaptchaConfig) (bool, error) {
+	if config.Solver == "" {
+		return false, nil
+	}
+	answer, err := runCaptchaSolver(challenge, result, config)
+	if err != nil {
+		return true, err
+	}
+	switch challenge.Kind {
+	case CaptchaSlider:
+		if answer.SliderOffset <= 0 {
+			return true, fmt.Errorf("打码命令没有返回 slider_offset")
+		}
+		return true, dragCaptchaSlider(page, answer.SliderOffset)
+	case CaptchaSMS:
+		if answer.SMSCode == "" {
+			return true, fmt.Errorf("打码命令没有返回 sms_code")
+		}
+		return true, fillCaptchaSMS(page, answer.SMSCode)
+	}
+	return true, fmt.Errorf("不支持的安全验证类型: %s", challenge.Kind)
+}
+
+// runCaptchaSolver 执行打码命令，标准错误写入日志，标准输出按 captchaAnswer 解析
+func runCaptchaSolver(challenge CaptchaChallenge, result TaskResult, config CaptchaConfig) (captchaAnswer, error) {
+	var answer captchaAnswer
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultCaptchaSolverTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, config.Solver)
+	cmd.Env = append(os.Environ(), taskCommandEnv(result)...)
+	cmd.Env = append(cmd.Env,
+		"UPLOADER_CAPTCHA_KIND="+challenge.Kind,
+		"UPLOADER_CAPTCHA_SCREENSHOT="+challenge.Screenshot,
+		"UPLOADER_CAPTCHA_VIEW_URL="+challenge.ViewURL,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = 5 * time.Second
+	output, err := cmd.Output()
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+		if line != "" {
+			log.Printf("🧩 第%d行打码命令: %s", challenge.RowIndex, line)
+		}
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return answer, fmt.Errorf("打码命令执行超时(%v)", timeout)
+	}
+	if err != nil {
+		return answer, fmt.Errorf("打码命令失败: %v", err)
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(output), &answer); err != nil {
+		return answer, fmt.Errorf("解析打码命令的输出失败: %v", err)
+	}
+	return answer, nil
+}
+
+// dragCaptchaSlider 按住滑块按钮向右拖动 offset 像素后松开
+func dragCaptchaSlider(page playwright.Page, offset float64) error {
+	handle, err := captchaSliderHandle(page)
+	if err != nil {
+		return err
+	}
+	box, err := handle.BoundingBox()
+	if err != nil || box == nil {
+		return fmt.Errorf("获取滑块位置失败: %v", err)
+	}
+	startX, startY := box.X+box.Width/2, box.Y+box.Height/2
+	mouse := page.Mouse()
+	if err := mouse.Move(startX, startY); err != nil {
+		return fmt.Errorf("拖动滑块失败: %v", err)
+	}
+	if err := mouse.Down(); err != nil {
+		return fmt.Errorf("拖动滑块失败: %v", err)
+	}
+	if err := mouse.Move(startX+offset, startY, playwright.MouseMoveOptions{Steps: playwright.Int(20)}); err != nil {
+		mouse.Up()
+		return fmt.Errorf("拖动滑块失败: %v", err)
+	}
+	if err := mouse.Up(); err != nil {
+		return fmt.Errorf("拖动滑块失败: %v", err)
+	}
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// captchaSliderHandle 查找可见的滑块按钮
+func captchaSliderHandle(page playwright.Page) (playwright.Locator, error) {
+	frame := page.FrameLocator(captchaFrameSelector)
+	for _, selector := range captchaSliderHandles {
+		for _, locator := range []playwright.Locator{frame.Locator(selector).First(), page.Locator(selector).First()} {
+			if visible, _ := locator.IsVisible(); visible {
+				return locator, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("未找到滑块验证的拖动按钮")
+}
+
+// fillCaptchaSMS 在短信验证弹窗中填写验证码并确定
+func fillCaptchaSMS(page playwright.Page, code string) error {
+	if err := page.Locator(captchaSMSInput).First().Fill(code, playwright.LocatorFillOptions{Timeout: playwright.Float(5000)}); err != nil {
+		return fmt.Errorf("未找到短信验证码输入框: %v", err)
+	}
+	if err := page.Locator(captchaSMSConfirm).First().Click(playwright.LocatorClickOptions{Timeout: playwright.Float(5000)}); err != nil {
+		return fmt.Errorf("未找到短信验证的确定按钮: %v", err)
+	}
+	time.Sleep(2 * time.Second)
+	return nil
+}
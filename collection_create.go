@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// createCollectionValue 合集列填写"创建新合集"（使用默认标题）或"创建新合集:标题"时创建合集
+const createCollectionValue = "创建新合集"
+
+// defaultNewCollectionTitle 只填写"创建新合集"时新合集的标题
+const defaultNewCollectionTitle = "我的视频合集"
+
+// collectionOptionChain 合集下拉列表中的合集
+var collectionOptionChain = SelectorChain{Name: "collection_option", Selectors: []string{
+	".post-album-wrap .option-item",
+	".filter-wrap .option-item",
+	"[class*='album'] [class*='option-item']",
+}}
+
+// parseCollection 解析合集列，返回合集标题和是否需要时创建
+func parseCollection(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	rest, ok := strings.CutPrefix(value, createCollectionValue)
+	if !ok {
+		return value, false
+	}
+	rest = strings.TrimSpace(strings.TrimLeft(rest, ":："))
+	if rest == "" {
+		return defaultNewCollectionTitle, true
+	}
+	return rest, true
+}
+
+// collectionName 合集列对应的合集标题，同一合集的不同写法（创建新合集:标题、标题）视为同一合集
+func collectionName(value string) string {
+	name, _ := parseCollection(value)
+	return name
+}
+
+// createdCollections 本次运行中已创建的合集：多行填写创建同一个合集时只创建一次，后续各行加入已创建的合集，
+// 避免重复创建被平台拒绝；为 nil 时不记录
+type createdCollections struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// newCreatedCollections 创建空的合集记录
+func newCreatedCollections() *createdCollections {
+	return &createdCollections{names: make(map[string]bool)}
+}
+
+// has 合集是否已在本次运行中创建
+func (c *createdCollections) has(name string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.names[name]
+}
+
+// add 记录已创建的合集
+func (c *createdCollections) add(name string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names[name] = true
+}
+
+// selectCollectionOption 在已打开的合集下拉列表中选择标题相同的合集，没有时返回 false
+func selectCollectionOption(page playwright.Page, name string) (bool, error) {
+	options, err := collectionOptionChain.Find(page)
+	if err != nil {
+		return false, nil
+	}
+	count, _ := options.Count()
+	for i := 0; i < count; i++ {
+		option := options.Nth(i)
+		text, err := option.TextContent()
+		if err != nil || strings.TrimSpace(text) != name {
+			continue
+		}
+		if err := option.Click(); err != nil {
+			return false, fmt.Errorf("选择合集失败: %v", err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// createCollection 在已打开的合集下拉列表中创建合集
+func createCollection(page playwright.Page, name string) error {
+	// 点击创建新合集
+	if err := page.Locator(".filter-wrap .create a").First().Click(); err != nil {
+		return err
+	}
+	time.Sleep(1 * time.Second)
+
+	// 填写合集标题
+	titleInput := ".weui-desktop-dialog__wrp input[placeholder='有趣的合集标题更容易吸引粉丝']"
+	if err := page.Locator(titleInput).First().Fill(name); err != nil {
+		return err
+	}
+
+	// 点击创建按钮（等待按钮可用）
+	createBtn := ".weui-desktop-dialog__ft .weui-desktop-btn_primary:not(.weui-desktop-btn_disabled)"
+	if err := page.Locator(createBtn).First().WaitFor(playwright.LocatorWaitForOptions{
+		State:   playwright.WaitForSelectorStateVisible,
+		Timeout: playwright.Float(5000),
+	}); err != nil {
+		return err
+	}
+
+	if err := page.Locator(createBtn).First().Click(); err != nil {
+		return err
+	}
+
+	// 等待创建成功并关闭对话框
+	time.Sleep(2 * time.Second)
+	confirmBtn := ".create-success-dialog .weui-desktop-btn_primary"
+	if err := page.Locator(confirmBtn).First().Click(); err != nil {
+		return err
+	}
+	log.Printf("✅ 已创建合集: %s", name)
+	return nil
+}
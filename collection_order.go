@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// collectionLanes 按合集把 indexes 中的任务分组：同一合集的任务按表格顺序放在同一组中串行执行，
+// 保证并发模式下合集内的集数顺序（创建新合集:标题 与 标题 为同一合集）；没有合集的任务各自一组。各组按第一个任务的顺序排列
+func collectionLanes(results []TaskResult, indexes []int) [][]int {
+	var lanes [][]int
+	laneOf := make(map[string]int)
+	for _, i := range indexes {
+		collection := collectionName(results[i].Task.Collection)
+		if collection == "" {
+			lanes = append(lanes, []int{i})
+			continue
+		}
+		if lane, ok := laneOf[collection]; ok {
+			lanes[lane] = append(lanes[lane], i)
+			continue
+		}
+		laneOf[collection] = len(lanes)
+		lanes = append(lanes, []int{i})
+	}
+	return lanes
+}
+
+// laterInCollection 第 index 个任务之后同一合集的任务
+func laterInCollection(results []TaskResult, index int) []int {
+	collection := collectionName(results[index].Task.Collection)
+	if collection == "" {
+		return nil
+	}
+	var later []int
+	for i := index + 1; i < len(results); i++ {
+		// 合集属于账号，不同账号的同名合集互不影响
+		if collectionName(results[i].Task.Collection) == collection && taskAccount(results[i].Task) == taskAccount(results[index].Task) {
+			later = append(later, i)
+		}
+	}
+	return later
+}
+
+// skipCollectionTasks 合集中某一集失败后，后续各集不再发表（否则集数顺序会错乱），标记为失败并合并为一条通知
+func skipCollectionTasks(results []TaskResult, indexes []int, failed TaskResult, logFile io.Writer, state *RunState, options ProcessOptions) {
+	if len(indexes) == 0 {
+		return
+	}
+	err := fmt.Errorf("合集 %s 中第%d行失败，跳过后续各集以保证发表顺序", failed.Task.Collection, failed.Task.RowIndex)
+	skipped := make([]TaskResult, 0, len(indexes))
+	for _, index := range indexes {
+		results[index].Fail(err)
+		results[index].classify()
+		state.Finish(index, results[index])
+		writeLogFile(logFile, results[index])
+		options.Status.WriteStatus(results[index])
+		options.Hooks.taskCompleted(results[index])
+		skipped = append(skipped, results[index])
+	}
+	options.Notifier.Notify(failureEvent(skipped...))
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config 配置文件（YAML），用于命令行参数不便表达的配置，如通知、数据库任务来源、任务前后命令、自定义表单步骤、默认位置、定时分钟调整、选择器包、重试队列、发表配额、平台页面地址、认证状态文件加密；
+// 敏感信息可写作 ${NAME}，从环境变量或 env_file 中读取
+type Config struct {
+	Version      int                `yaml:"version"` // 配置文件版本，见 configVersion
+	Notifiers    []NotifierConfig   `yaml:"notifiers"`
+	Source       SQLSourceConfig    `yaml:"source"`
+	TaskCommands TaskCommandConfig  `yaml:"task_commands"`
+	FormSteps    []FormStepConfig   `yaml:"form_steps"`
+	FormFields   FormFieldsConfig   `yaml:"form_fields"`
+	Location     LocationConfig     `yaml:"location"`
+	Schedule     ScheduleConfig     `yaml:"schedule"`
+	Browser      BrowserConfig      `yaml:"browser"`
+	SelectorPack SelectorPackConfig `yaml:"selector_pack"`
+	RetryQueue   RetryQueueConfig   `yaml:"retry_queue"`
+	Quota        QuotaConfig        `yaml:"quota"`
+	Pages        PagesConfig        `yaml:"pages"`
+	Navigation   NavigationConfig   `yaml:"navigation"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Media        MediaConfig        `yaml:"media"`
+	Captcha      CaptchaConfig      `yaml:"captcha"`
+	EnvFile      string             `yaml:"env_file"` // ${NAME} 引用的 .env 文件，相对于配置文件所在目录
+
+	env *ConfigEnv
+}
+
+// LoadConfig 读取配置文件，path 为空时返回空配置
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if data, err = migrateConfig(data); err != nil {
+		return nil, err
+	}
+	if errs := validateConfigSchema(data); len(errs) > 0 {
+		return nil, fmt.Errorf("配置文件 %s 有 %d 处错误:\n  %s", path, len(errs), strings.Join(errs, "\n  "))
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	if len(doc.Content) == 0 {
+		return config, nil
+	}
+	if config.env, err = loadConfigEnv(doc.Content[0], path); err != nil {
+		return nil, err
+	}
+	if err := config.env.expandNode(&doc); err != nil {
+		return nil, fmt.Errorf("配置文件 %v", err)
+	}
+	if err := doc.Decode(config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	// 与 env_file 相同，脚本文件的相对路径相对于配置文件所在目录
+	for i, step := range config.FormSteps {
+		if step.ScriptFile != "" && !filepath.IsAbs(step.ScriptFile) {
+			config.FormSteps[i].ScriptFile = filepath.Join(filepath.Dir(path), step.ScriptFile)
+		}
+	}
+	if err := validateMinuteRounding(config.Schedule.MinuteRounding); err != nil {
+		return nil, fmt.Errorf("配置文件错误: %v", err)
+	}
+	if err := validateScheduleFallback(config.Schedule.Fallback); err != nil {
+		return nil, fmt.Errorf("配置文件错误: %v", err)
+	}
+	if err := validateScheduleWindow(config.Schedule); err != nil {
+		return nil, fmt.Errorf("配置文件 schedule 错误: %v", err)
+	}
+	if err := config.Browser.Login.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 browser.login 错误: %v", err)
+	}
+	if err := config.Browser.Upload.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 browser.upload 错误: %v", err)
+	}
+	if err := config.Browser.Context.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 browser.context 错误: %v", err)
+	}
+	if err := config.SelectorPack.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 selector_pack 错误: %v", err)
+	}
+	if err := config.RetryQueue.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 retry_queue 错误: %v", err)
+	}
+	if err := config.Quota.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 quota 错误: %v", err)
+	}
+	if err := config.Pages.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 pages 错误: %v", err)
+	}
+	if err := config.FormFields.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 form_fields 错误: %v", err)
+	}
+	if err := config.Navigation.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 navigation 错误: %v", err)
+	}
+	if err := config.Auth.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 auth 错误: %v", err)
+	}
+	if err := config.Media.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 media 错误: %v", err)
+	}
+	if err := config.Captcha.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件 captcha 错误: %v", err)
+	}
+	return config, nil
+}
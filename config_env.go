@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envReference 配置中引用环境变量的写法 ${NAME}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ConfigEnv 配置文件和代理池文件中 ${NAME} 引用的变量：先取进程的环境变量，再取 env_file 指定的 .env 文件，
+// 代理账号密码、webhook 地址、SMTP 密码等敏感信息不必写在配置文件中，替换后的值不输出到日志
+type ConfigEnv struct {
+	file map[string]string // .env 文件中的变量
+}
+
+// LoadEnvFile 读取 .env 文件：每行 NAME=value，# 开头为注释，可带 export 前缀，值可用单引号或双引号括起
+func LoadEnvFile(path string) (*ConfigEnv, error) {
+	env := &ConfigEnv{file: make(map[string]string)}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 env_file 失败: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envReference.MatchString("${"+name+"}") {
+			// 不输出该行内容，避免泄露其中的值
+			return nil, fmt.Errorf("env_file 第%d行格式错误(应为 NAME=value)", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env.file[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 env_file 失败: %v", err)
+	}
+	return env, nil
+}
+
+// lookup 查找变量，进程的环境变量优先
+func (e *ConfigEnv) lookup(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	if e == nil {
+		return "", false
+	}
+	value, ok := e.file[name]
+	return value, ok
+}
+
+// Expand 替换文本中的 ${NAME}，变量未设置时返回错误（只包含变量名）；e 为 nil 时只使用进程的环境变量
+func (e *ConfigEnv) Expand(text string) (string, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(text, func(reference string) string {
+		name := envReference.FindStringSubmatch(reference)[1]
+		value, ok := e.lookup(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("环境变量未设置: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// loadConfigEnv 读取配置文件中 env_file 指定的 .env 文件，相对路径相对于配置文件所在目录，未指定时返回 nil
+func loadConfigEnv(doc *yaml.Node, configPath string) (*ConfigEnv, error) {
+	if doc.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "env_file" || doc.Content[i+1].Value == "" {
+			continue
+		}
+		path := doc.Content[i+1].Value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(configPath), path)
+		}
+		return LoadEnvFile(path)
+	}
+	return nil, nil
+}
+
+// expandNode 替换配置中所有字符串值里的 ${NAME}（不包括键），错误中只包含行号和变量名
+func (e *ConfigEnv) expandNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := e.expandNode(child); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := e.expandNode(node.Content[i]); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return nil
+		}
+		value, err := e.Expand(node.Value)
+		if err != nil {
+			return fmt.Errorf("第%d行: %v", node.Line, err)
+		}
+		node.Value = value
+		if node.Style == 0 {
+			// 按替换后的值重新识别类型，如 smtp_port: ${SMTP_PORT}
+			node.Tag = ""
+		}
+	}
+	return nil
+}
+
+// withoutURL 去掉 net/url、net/http 错误中的完整地址，地址中可能带有账号密码或 token
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
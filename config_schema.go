@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configSchemaURL 生成的 JSON Schema 的规范版本
+const configSchemaURL = "http://json-schema.org/draft-07/schema#"
+
+// configEnums 配置项的可选值（类型名.字段名），列表类型的配置项对每一项校验
+var configEnums = map[string][]string{
+	"NotifierConfig.Type":               {"console", "file", "webhook", "email"},
+	"NotifierConfig.Events":             {EventOnFailure, EventOnFinish, EventOnLoginRequired, EventOnCaptcha, EventOnRetryExhausted, EventOnQuotaWarning, EventOnAccountBreaker},
+	"ScheduleConfig.MinuteRounding":     {MinuteRoundCeil, MinuteRoundFloor, MinuteRoundFail},
+	"ScheduleConfig.Fallback":           {ScheduleFallbackFail, ScheduleFallbackDraft},
+	"BrowserPhaseConfig.BlockResources": slices.Sorted(maps.Keys(blockableResourceTypes)),
+	"BrowserPhaseConfig.StealthSkip":    stealthScriptNames(),
+	"ContextConfig.ColorScheme":         slices.Sorted(maps.Keys(colorSchemes)),
+	"FormFieldsConfig.Order":            defaultFormFieldOrder,
+	"FormFieldsConfig.Skip":             defaultFormFieldOrder,
+	"NavigationConfig.WaitUntil":        slices.Sorted(maps.Keys(navigationWaitStates)),
+}
+
+// durationType time.Duration 的配置项写作 10m、200ms 等
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// jsonSchema 由 Config 结构体生成的 JSON Schema，可供编辑器补全和校验配置文件
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"` // false 或值的 schema
+	Items                *jsonSchema            `json:"items,omitempty"`
+}
+
+// configSchema 生成配置文件的 JSON Schema
+func configSchema() *jsonSchema {
+	schema := schemaForType(reflect.TypeOf(Config{}), nil)
+	schema.Schema = configSchemaURL
+	schema.Title = "wechat-uploader 配置文件"
+	return schema
+}
+
+// schemaForType 按字段类型生成 schema，enum 为该字段的可选值
+func schemaForType(t reflect.Type, enum []string) *jsonSchema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return &jsonSchema{Type: []string{"string", "integer"}, Format: "duration"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema), AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			schema.Properties[name] = schemaForType(field.Type, configEnums[t.Name()+"."+field.Name])
+		}
+		return schema
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: schemaForType(t.Elem(), enum)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), nil)}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	default:
+		return &jsonSchema{Type: "string", Enum: enum}
+	}
+}
+
+// validateConfigSchema 按 schema 校验配置文件，返回所有错误（行号、配置项路径、期望的类型或可选值）
+func validateConfigSchema(data []byte) []string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []string{err.Error()}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	var errs []string
+	configSchema().validate(doc.Content[0], "", &errs)
+	return errs
+}
+
+// validate 校验一个节点，错误追加到 errs
+func (s *jsonSchema) validate(node *yaml.Node, path string, errs *[]string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+	if node.Kind == yaml.ScalarNode && envReference.MatchString(node.Value) && s.Type != "object" && s.Type != "array" {
+		// 引用环境变量的值在替换后才能确定
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		location := path
+		if location == "" {
+			location = "(根)"
+		}
+		*errs = append(*errs, fmt.Sprintf("第%d行 %s: %s", node.Line, location, fmt.Sprintf(format, args...)))
+	}
+
+	if s.Format == "duration" {
+		// 整数按纳秒读取
+		if node.Kind != yaml.ScalarNode {
+			fail("应为时长，例如 30s、10m、1h，实际为%s", yamlKindName(node))
+		} else if node.ShortTag() != "!!int" {
+			if _, err := time.ParseDuration(node.Value); err != nil {
+				fail("时长格式错误 %q，例如 30s、10m、1h", node.Value)
+			}
+		}
+		return
+	}
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			fail("应为对象(键: 值)，实际为%s", yamlKindName(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			if property, ok := s.Properties[key]; ok {
+				property.validate(value, child, errs)
+			} else if additional, ok := s.AdditionalProperties.(*jsonSchema); ok {
+				additional.validate(value, child, errs)
+			} else {
+				node := node.Content[i]
+				*errs = append(*errs, fmt.Sprintf("第%d行 %s: 不支持的配置项，可选: %s", node.Line, child, strings.Join(s.propertyNames(), ", ")))
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			fail("应为列表，实际为%s", yamlKindName(node))
+			return
+		}
+		for i, item := range node.Content {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			fail("应为 true 或 false，实际为%s", yamlKindName(node))
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" {
+			fail("应为整数，实际为%s", yamlKindName(node))
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.ShortTag() != "!!int" && node.ShortTag() != "!!float") {
+			fail("应为数字，实际为%s", yamlKindName(node))
+		}
+	case "string":
+		// 数字、true/false 等标量也会按原文读取为字符串
+		if node.Kind != yaml.ScalarNode {
+			fail("应为字符串，实际为%s", yamlKindName(node))
+			return
+		}
+		if len(s.Enum) > 0 && node.Value != "" && !slices.Contains(s.Enum, node.Value) {
+			fail("不支持的值 %q，可选: %s", node.Value, strings.Join(s.Enum, ", "))
+		}
+	}
+}
+
+// propertyNames 按名称排序的配置项
+func (s *jsonSchema) propertyNames() []string {
+	return slices.Sorted(maps.Keys(s.Properties))
+}
+
+// yamlKindName YAML 节点类型的中文名称
+func yamlKindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "对象"
+	case yaml.SequenceNode:
+		return "列表"
+	}
+	switch node.ShortTag() {
+	case "!!bool":
+		return fmt.Sprintf("布尔值 %s", node.Value)
+	case "!!int", "!!float":
+		return fmt.Sprintf("数字 %s", node.Value)
+	}
+	return fmt.Sprintf("字符串 %q", node.Value)
+}
+
+// runConfigCommand 处理 config 子命令：validate 校验配置文件，schema 输出配置文件的 JSON Schema
+func runConfigCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: config validate <配置文件> | config schema")
+	}
+	switch args[0] {
+	case "validate":
+		if len(args) != 2 {
+			return fmt.Errorf("用法: config validate <配置文件>")
+		}
+		if _, err := LoadConfig(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ 配置文件校验通过: %s\n", args[1])
+		return nil
+	case "schema":
+		data, err := json.MarshalIndent(configSchema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	default:
+		return fmt.Errorf("未知的 config 操作: %s (可选 validate/schema)", args[0])
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 内容类型，对应创建页面上的不同发表入口
+const (
+	ContentTypeVideo      = "video"       // 普通视频（默认）
+	ContentTypeShortDrama = "short_drama" // 短剧
+	ContentTypeLiveReplay = "live_replay" // 直播回放
+	ContentTypeImage      = "image"       // 图文动态，媒体列为一张或多张图片
+)
+
+// contentTypeColumn 内容类型列的表头，放在J列之后，为空时为普通视频
+const contentTypeColumn = "内容类型"
+
+// contentTypeNames 内容类型可以写中文或英文
+var contentTypeNames = map[string]string{
+	"视频":                  ContentTypeVideo,
+	"短剧":                  ContentTypeShortDrama,
+	"直播回放":                ContentTypeLiveReplay,
+	"图文":                  ContentTypeImage,
+	"图文动态":                ContentTypeImage,
+	ContentTypeVideo:      ContentTypeVideo,
+	ContentTypeShortDrama: ContentTypeShortDrama,
+	ContentTypeLiveReplay: ContentTypeLiveReplay,
+	ContentTypeImage:      ContentTypeImage,
+}
+
+// contentTypeFlow 内容类型的创建流程入口
+type contentTypeFlow struct {
+	Name    string        // 用于日志和错误信息
+	URL     func() string // 创建页面地址
+	Entries []string      // 打开页面后依次尝试点击的入口，为空时页面本身就是创建流程
+}
+
+// contentTypeFlows 各内容类型的创建流程
+var contentTypeFlows = map[string]contentTypeFlow{
+	ContentTypeVideo: {Name: "视频", URL: platformURLs.CreatePage},
+	ContentTypeImage: {Name: "图文", URL: platformURLs.ImageCreatePage},
+	ContentTypeShortDrama: {Name: "短剧", URL: platformURLs.CreatePage, Entries: []string{
+		"[role='tab']:has-text('短剧')",
+		".post-type-tab:has-text('短剧')",
+		"text=短剧",
+	}},
+	ContentTypeLiveReplay: {Name: "直播回放", URL: platformURLs.CreatePage, Entries: []string{
+		"[role='tab']:has-text('直播回放')",
+		".post-type-tab:has-text('直播回放')",
+		"text=直播回放",
+	}},
+}
+
+// parseContentType 解析内容类型列，为空时为普通视频
+func parseContentType(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ContentTypeVideo, nil
+	}
+	if contentType, ok := contentTypeNames[strings.ToLower(value)]; ok {
+		return contentType, nil
+	}
+	return "", fmt.Errorf("不支持的内容类型: %s(可选 视频、图文、短剧、直播回放)", value)
+}
+
+// enterContentFlow 进入内容类型对应的创建流程；普通视频已在创建页面时不再导航，
+// 有入口的类型每次重新打开创建页面，避免沿用上一个任务切换过的入口
+func enterContentFlow(page playwright.Page, contentType string) error {
+	flow, ok := contentTypeFlows[contentType]
+	if !ok {
+		return fmt.Errorf("不支持的内容类型: %s", contentType)
+	}
+	if len(flow.Entries) == 0 && strings.HasPrefix(page.URL(), flow.URL()) {
+		return nil
+	}
+
+	log.Printf("🧭 进入%s创建流程...", flow.Name)
+	if err := navigateWithRetry(page, flow.URL()); err != nil {
+		return err
+	}
+	if err := waitForPageReady(page); err != nil {
+		return fmt.Errorf("页面加载失败: %v", err)
+	}
+	if len(flow.Entries) == 0 {
+		return nil
+	}
+	for _, selector := range flow.Entries {
+		locator := page.Locator(selector).First()
+		if visible, _ := locator.IsVisible(); !visible {
+			continue
+		}
+		if err := locator.Click(); err != nil {
+			return fmt.Errorf("点击%s入口失败: %v", flow.Name, err)
+		}
+		time.Sleep(2 * time.Second)
+		log.Printf("✅ 已进入%s创建流程", flow.Name)
+		return nil
+	}
+	return fmt.Errorf("未找到%s创建入口", flow.Name)
+}
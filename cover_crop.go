@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// coverCropColumn 封面裁剪列的表头，放在J列之后，为空时使用平台默认的居中裁剪
+const coverCropColumn = "封面裁剪"
+
+// coverImageColumn 封面图片列的表头，放在J列之后，相对路径与视频位置相同；为空时使用平台从视频中截取的封面
+const coverImageColumn = "封面图片"
+
+// coverAspects 封面裁剪的比例 -> 封面编辑弹窗中对应的卡片
+var coverAspects = map[string]string{
+	"3:4": "个人主页卡片",
+	"4:3": "分享卡片",
+}
+
+// coverCropMaxOffset 裁剪框偏移的上限（占封面宽、高的百分比）
+const coverCropMaxOffset = 50
+
+// coverCrop 封面裁剪参数：比例和裁剪框相对居中位置的偏移
+type coverCrop struct {
+	Aspect string // 3:4 或 4:3
+	X      int    // 水平偏移，占封面宽度的百分比，正数向右
+	Y      int    // 垂直偏移，占封面高度的百分比，正数向下（人物头部被裁掉时填负数上移）
+}
+
+// String 按列中的格式输出
+func (c coverCrop) String() string {
+	return fmt.Sprintf("%s x=%d y=%d", c.Aspect, c.X, c.Y)
+}
+
+// 封面编辑入口、弹窗中的裁剪框和裁剪区域、确认按钮
+var (
+	coverEditChain = SelectorChain{Name: "cover_edit", Selectors: []string{
+		".post-cover-wrap .cover-edit",
+		"[class*='cover-wrap'] [class*='edit']",
+		"text=编辑封面",
+		"text=更换封面",
+	}}
+	coverCropBoxChain = SelectorChain{Name: "cover_crop_box", Selectors: []string{
+		".weui-desktop-dialog .cropper-crop-box",
+		"[class*='cropper'] [class*='crop-box']",
+		"[class*='cover-dialog'] [class*='crop-box']",
+	}}
+	coverCropAreaChain = SelectorChain{Name: "cover_crop_area", Selectors: []string{
+		".weui-desktop-dialog .cropper-canvas",
+		"[class*='cropper'] [class*='canvas']",
+		"[class*='cover-dialog'] img",
+	}}
+	coverUploadChain = SelectorChain{Name: "cover_upload", Selectors: []string{
+		".weui-desktop-dialog input[type='file']",
+		"[role='dialog'] input[type='file']",
+		"[class*='cover-dialog'] input[type='file']",
+	}}
+	coverConfirmChain = SelectorChain{Name: "cover_confirm", Selectors: []string{
+		".weui-desktop-dialog button:has-text('确定')",
+		".weui-desktop-dialog button:has-text('完成')",
+		"[role='dialog'] button:has-text('确定')",
+	}}
+)
+
+// parseCoverCrop 解析封面裁剪列，例如"3:4 x=0 y=-20"：比例必填，x、y 为裁剪框偏移（百分比，可省略，默认 0）；
+// 为空时返回 nil
+func parseCoverCrop(value string) (*coverCrop, error) {
+	fields := strings.Fields(strings.ReplaceAll(strings.ReplaceAll(value, "，", " "), ",", " "))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	crop := &coverCrop{Aspect: strings.ReplaceAll(fields[0], "：", ":")}
+	if _, ok := coverAspects[crop.Aspect]; !ok {
+		return nil, fmt.Errorf("封面裁剪比例只能为 3:4 或 4:3: %s", fields[0])
+	}
+	for _, field := range fields[1:] {
+		name, number, ok := strings.Cut(strings.ToLower(field), "=")
+		offset, err := strconv.Atoi(strings.TrimSuffix(number, "%"))
+		if !ok || err != nil || (name != "x" && name != "y") {
+			return nil, fmt.Errorf("封面裁剪偏移格式应为 x=数字 或 y=数字: %s", field)
+		}
+		if offset < -coverCropMaxOffset || offset > coverCropMaxOffset {
+			return nil, fmt.Errorf("封面裁剪偏移应在 -%d 到 %d 之间: %s", coverCropMaxOffset, coverCropMaxOffset, field)
+		}
+		if name == "x" {
+			crop.X = offset
+		} else {
+			crop.Y = offset
+		}
+	}
+	return crop, nil
+}
+
+// parseCoverImage 解析封面图片列：相对路径按任务文件目录解析，checkFiles 时检查文件是否存在；只支持 jpg、png、webp 图片
+func parseCoverImage(value string, checkFiles bool, baseDir string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !isImagePath(value) {
+		return "", fmt.Errorf("封面图片只支持 jpg、png、webp: %s", value)
+	}
+	path := resolveMediaPaths(value, baseDir)
+	if checkFiles {
+		if exists, err := checkFileExists(path, ""); !exists {
+			return "", fmt.Errorf("封面图片不存在: %s, %s", path, err)
+		}
+	}
+	return path, nil
+}
+
+// applyCover 打开封面编辑弹窗，指定了封面图片时先上传图片，指定了裁剪时选择比例对应的卡片、按偏移拖动裁剪框，最后确认
+func applyCover(page playwright.Page, image string, crop *coverCrop) error {
+	if err := clickFirstVisible(page, coverEditChain); err != nil {
+		return fmt.Errorf("未找到封面编辑入口")
+	}
+	time.Sleep(1 * time.Second)
+
+	if image != "" {
+		if err := uploadCoverImage(page, image); err != nil {
+			return err
+		}
+	}
+	if crop != nil {
+		if err := cropCover(page, *crop); err != nil {
+			return err
+		}
+	}
+
+	if err := clickFirstVisible(page, coverConfirmChain); err != nil {
+		return fmt.Errorf("未找到封面编辑的确定按钮")
+	}
+	time.Sleep(1 * time.Second)
+	return nil
+}
+
+// uploadCoverImage 在封面编辑弹窗中上传封面图片，等待图片出现在裁剪区域
+func uploadCoverImage(page playwright.Page, image string) error {
+	input, err := coverUploadChain.Find(page)
+	if err != nil {
+		return fmt.Errorf("未找到封面图片上传输入框")
+	}
+	if err := input.First().SetInputFiles([]string{resolvePath(image)}); err != nil {
+		return fmt.Errorf("设置封面图片失败: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		time.Sleep(1 * time.Second)
+		if message, found := pageErrorMessage(page, uploadErrorSelectors); found {
+			return platformError("上传封面图片失败", message)
+		}
+		if _, err := coverCropAreaChain.FindVisible(page); err == nil {
+			log.Printf("🖼️ 封面图片已上传: %s", filepath.Base(image))
+			return nil
+		}
+	}
+	return fmt.Errorf("等待封面图片上传超时: %s", filepath.Base(image))
+}
+
+// cropCover 选择比例对应的卡片，按偏移拖动裁剪框
+func cropCover(page playwright.Page, crop coverCrop) error {
+	card := coverAspects[crop.Aspect]
+	if err := page.Locator(fmt.Sprintf("text=%s", card)).First().Click(playwright.LocatorClickOptions{Timeout: playwright.Float(5000)}); err != nil {
+		return fmt.Errorf("未找到封面比例 %s（%s）: %v", crop.Aspect, card, err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	if crop.X != 0 || crop.Y != 0 {
+		if err := dragCoverCropBox(page, crop); err != nil {
+			return err
+		}
+	}
+	log.Printf("🖼️ 封面已按 %s 裁剪", crop)
+	return nil
+}
+
+// dragCoverCropBox 从裁剪框中心按偏移拖动，偏移按裁剪区域（封面图片）的宽、高换算为像素
+func dragCoverCropBox(page playwright.Page, crop coverCrop) error {
+	box, err := coverCropBoxChain.FindVisible(page)
+	if err != nil {
+		return fmt.Errorf("未找到封面裁剪框")
+	}
+	area, err := coverCropAreaChain.FindVisible(page)
+	if err != nil {
+		return fmt.Errorf("未找到封面裁剪区域")
+	}
+	boxRect, err := box.BoundingBox()
+	if err != nil || boxRect == nil {
+		return fmt.Errorf("获取封面裁剪框位置失败: %v", err)
+	}
+	areaRect, err := area.BoundingBox()
+	if err != nil || areaRect == nil {
+		return fmt.Errorf("获取封面裁剪区域位置失败: %v", err)
+	}
+
+	startX := boxRect.X + boxRect.Width/2
+	startY := boxRect.Y + boxRect.Height/2
+	endX := startX + areaRect.Width*float64(crop.X)/100
+	endY := startY + areaRect.Height*float64(crop.Y)/100
+	mouse := page.Mouse()
+	if err := mouse.Move(startX, startY); err != nil {
+		return fmt.Errorf("拖动封面裁剪框失败: %v", err)
+	}
+	if err := mouse.Down(); err != nil {
+		return fmt.Errorf("拖动封面裁剪框失败: %v", err)
+	}
+	// 分步移动，裁剪组件按 mousemove 更新位置，超出图片的部分由组件限制在边缘
+	if err := mouse.Move(endX, endY, playwright.MouseMoveOptions{Steps: playwright.Int(10)}); err != nil {
+		mouse.Up()
+		return fmt.Errorf("拖动封面裁剪框失败: %v", err)
+	}
+	if err := mouse.Up(); err != nil {
+		return fmt.Errorf("拖动封面裁剪框失败: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	return nil
+}
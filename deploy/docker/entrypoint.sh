@@ -0,0 +1,20 @@
+#!/bin/sh
+# 容器入口：以容器模式运行，Excel文件通过 UPLOADER_FILE 指定（相对 /data）
+set -e
+
+if [ -z "$UPLOADER_FILE" ] && [ $# -eq 0 ]; then
+    echo "错误: 请设置 UPLOADER_FILE 环境变量或传入命令行参数" >&2
+    exit 1
+fi
+
+if [ $# -gt 0 ]; then
+    exec channel_video_uploader -container "$@"
+fi
+
+# 页面查看服务默认不开启，需要时设置 UPLOADER_VIEW_ADDR=0.0.0.0:8081（访问令牌见日志）
+exec channel_video_uploader -container \
+    -file="$UPLOADER_FILE" \
+    -concurrent="${UPLOADER_CONCURRENT:-false}" \
+    -output-dir="${UPLOADER_OUTPUT_DIR:-/data}" \
+    -qr-addr="${UPLOADER_QR_ADDR:-:8080}" \
+    -view-addr="${UPLOADER_VIEW_ADDR:-}"
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// freeDiskSpace 获取目录所在磁盘的可用空间
+func freeDiskSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, fmt.Errorf("获取磁盘空间失败: %v", err)
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
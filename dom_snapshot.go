@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// snapshotSteps 找不到元素时保存 DOM 快照的关键步骤
+var snapshotSteps = map[string]bool{
+	StepContentType:  true,
+	StepFileUpload:   true,
+	StepOpenDraft:    true,
+	StepDescription:  true,
+	StepLocation:     true,
+	StepSchedule:     true,
+	StepShortTitle:   true,
+	StepCover:        true,
+	StepSubmit:       true,
+	StepVerification: true,
+}
+
+// snapshotAnnotateScript 在页面顶部显示失败的步骤和错误，并框出该步骤的元素，截图后移除
+const snapshotAnnotateScript = `([text, selector]) => {
+	const banner = document.createElement('div');
+	banner.id = '__uploader_snapshot_banner';
+	banner.textContent = text;
+	banner.style.cssText = 'position:fixed;top:0;left:0;right:0;z-index:2147483647;padding:8px;background:rgba(200,0,0,0.85);color:#fff;font:14px monospace;white-space:pre-wrap;word-break:break-all';
+	document.body.appendChild(banner);
+	if (selector) {
+		try {
+			document.querySelectorAll(selector).forEach((el) => { el.dataset.snapshotOutline = el.style.outline; el.style.outline = '3px dashed red'; });
+		} catch (e) {}
+	}
+}`
+
+// snapshotCleanupScript 移除标注
+const snapshotCleanupScript = `() => {
+	document.getElementById('__uploader_snapshot_banner')?.remove();
+	document.querySelectorAll('[data-snapshot-outline]').forEach((el) => { el.style.outline = el.dataset.snapshotOutline; delete el.dataset.snapshotOutline; });
+}`
+
+// snapshotStep 包装步骤函数：关键步骤因找不到元素失败时保存页面 HTML 和标注后的截图，记入任务附件
+func snapshotStep(page playwright.Page, result *TaskResult, options ProcessOptions, onStep StepFunc) StepFunc {
+	return func(name string, fn func() error) error {
+		err := runStep(onStep, name, fn)
+		if err == nil || !snapshotSteps[name] || !isSelectorMiss(err.Error()) || page.IsClosed() {
+			return err
+		}
+		artifacts, snapshotErr := saveDOMSnapshot(page, result, name, err, options.OutputDir)
+		if snapshotErr != nil {
+			log.Printf("⚠️ 保存DOM快照失败: %v", snapshotErr)
+		}
+		if len(artifacts) > 0 {
+			log.Printf("📸 已保存DOM快照: %s", strings.Join(artifacts, ", "))
+			result.Artifacts = append(result.Artifacts, artifacts...)
+		}
+		return err
+	}
+}
+
+// isSelectorMiss 错误是否由找不到页面元素导致，包括等待元素出现超时
+func isSelectorMiss(message string) bool {
+	return classifyError(message) == ErrorCategorySelector || strings.Contains(message, "waiting for locator")
+}
+
+// saveDOMSnapshot 将页面 HTML 和标注后的截图保存到 output-dir/artifacts/ 目录，返回已保存的文件
+func saveDOMSnapshot(page playwright.Page, result *TaskResult, step string, stepErr error, outputDir string) ([]string, error) {
+	dir := filepath.Join(outputDir, "artifacts")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("row%d_%s_%s", result.Task.RowIndex, step, time.Now().Format("20060102_150405")))
+
+	var saved []string
+	content, err := page.Content()
+	if err != nil {
+		return saved, fmt.Errorf("读取页面HTML失败: %v", err)
+	}
+	header := fmt.Sprintf("<!-- url: %s\n     step: %s\n     error: %s -->\n", page.URL(), step, strings.ReplaceAll(stepErr.Error(), "--", "- -"))
+	if err := os.WriteFile(base+".html", []byte(header+content), 0644); err != nil {
+		return saved, fmt.Errorf("写入页面HTML失败: %v", err)
+	}
+	saved = append(saved, base+".html")
+
+	annotation := fmt.Sprintf("第%d行 步骤: %s\n错误: %s", result.Task.RowIndex, step, stepErr.Error())
+	if _, err := page.Evaluate(snapshotAnnotateScript, []interface{}{annotation, stepSelectors[step]}); err != nil {
+		log.Printf("⚠️ 标注截图失败: %v", err)
+	}
+	_, err = page.Screenshot(playwright.PageScreenshotOptions{Path: playwright.String(base + ".png"), FullPage: playwright.Bool(true)})
+	page.Evaluate(snapshotCleanupScript)
+	if err != nil {
+		return saved, fmt.Errorf("截图失败: %v", err)
+	}
+	return append(saved, base+".png"), nil
+}
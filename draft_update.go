@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// draftEditTimeout 点击编辑后等待草稿编辑页面出现描述输入框的时间
+const draftEditTimeout = 30000
+
+// 作品管理中的草稿箱入口和草稿列表中的草稿
+var (
+	draftTabChain = SelectorChain{Name: "draft_tab", Selectors: []string{
+		".weui-desktop-tab__nav:has-text('草稿')",
+		"[role='tab']:has-text('草稿')",
+		"text=草稿箱",
+	}}
+	draftItemChain = SelectorChain{Name: "draft_item", Selectors: []string{
+		".post-feed-item",
+		"[class*='draft-item']",
+		"[class*='post-item']",
+	}}
+)
+
+// draftEditSelectors 草稿中的编辑入口（在草稿元素内查找）
+var draftEditSelectors = []string{
+	"text=编辑",
+	"text=继续编辑",
+	"[class*='edit']",
+}
+
+// draftFileKey 视频文件名（不含扩展名），图文动态为第一张图片的文件名
+func draftFileKey(videoPath string) string {
+	paths := splitMediaPaths(videoPath)
+	if len(paths) == 0 {
+		return ""
+	}
+	name := filepath.Base(paths[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// draftMatchKeys 在草稿箱中查找草稿的关键字，依次为视频文件名、短标题、描述
+func draftMatchKeys(task VideoCreateTask) []string {
+	var keys []string
+	for _, key := range []string{draftFileKey(task.VideoPath), strings.TrimSpace(task.ShortTitle), postKeyword(task.Description)} {
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// openDraft 打开作品管理的草稿箱，按文件名、短标题、描述依次查找草稿并进入编辑页面，返回匹配的关键字；
+// 一个关键字匹配到多个草稿时无法确定要更新哪一个，任务失败
+func openDraft(page playwright.Page, task VideoCreateTask) (string, error) {
+	keys := draftMatchKeys(task)
+	if len(keys) == 0 {
+		return "", fmt.Errorf("视频位置、短标题和描述都为空，无法确定要更新的草稿")
+	}
+	if err := navigateWithRetry(page, platformURLs.PostListPage()); err != nil {
+		return "", fmt.Errorf("打开作品管理失败: %v", err)
+	}
+	tab, err := draftTabChain.FindVisible(page)
+	if err != nil {
+		return "", err
+	}
+	if err := tab.Click(); err != nil {
+		return "", fmt.Errorf("打开草稿箱失败: %v", err)
+	}
+	time.Sleep(3 * time.Second)
+
+	for _, key := range keys {
+		item, count := findDraft(page, key)
+		if count == 0 {
+			continue
+		}
+		if count > 1 {
+			return "", fmt.Errorf("草稿箱中有 %d 个草稿匹配 %s，无法确定要更新的草稿", count, key)
+		}
+		log.Printf("📝 在草稿箱中找到草稿: %s", key)
+		if err := editDraft(page, item); err != nil {
+			return "", err
+		}
+		return key, nil
+	}
+	return "", fmt.Errorf("草稿箱中没有匹配的草稿: %s", strings.Join(keys, "、"))
+}
+
+// findDraft 返回草稿箱中包含关键字的第一个草稿和匹配的草稿个数
+func findDraft(page playwright.Page, key string) (playwright.Locator, int) {
+	for _, selector := range draftItemChain.Selectors {
+		items := page.Locator(selector).Filter(playwright.LocatorFilterOptions{HasText: key})
+		if count, _ := items.Count(); count > 0 {
+			return items.First(), count
+		}
+	}
+	return nil, 0
+}
+
+// editDraft 点击草稿的编辑入口，等待编辑页面加载完成
+func editDraft(page playwright.Page, item playwright.Locator) error {
+	// 编辑入口可能在鼠标悬停时才显示
+	item.Hover()
+	clicked := false
+	for _, selector := range draftEditSelectors {
+		button := item.Locator(selector).First()
+		if visible, _ := button.IsVisible(); !visible {
+			continue
+		}
+		if err := button.Click(); err == nil {
+			clicked = true
+			break
+		}
+	}
+	if !clicked {
+		return fmt.Errorf("未找到草稿的编辑入口: %s", strings.Join(draftEditSelectors, ", "))
+	}
+	if err := page.Locator(descriptionSelector).First().WaitFor(playwright.LocatorWaitForOptions{
+		State:   playwright.WaitForSelectorStateVisible,
+		Timeout: playwright.Float(draftEditTimeout),
+	}); err != nil {
+		return fmt.Errorf("等待草稿编辑页面超时: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import "strings"
+
+// 任务失败分类，用于汇总一次运行主要失败在哪里
+const (
+	ErrorCategoryInternal     = "internal error"    // 程序内部错误（panic）
+	ErrorCategoryLoginExpired = "login expired"     // 登录失效，需要重新扫码
+	ErrorCategoryBrowserCrash = "browser crash"     // 页面或浏览器崩溃
+	ErrorCategoryUpload       = "upload failed"     // 视频文件上传失败
+	ErrorCategorySelector     = "selector broke"    // 页面元素找不到，多为页面改版
+	ErrorCategoryTimeout      = "timeout"           // 等待页面或操作超时
+	ErrorCategoryRejected     = "platform rejected" // 平台提示操作失败
+	ErrorCategoryInvalidInput = "invalid input"     // Excel中的取值不被支持
+	ErrorCategoryTaskCommand  = "task command"      // 任务前后命令要求跳过或失败
+	ErrorCategorySchedule     = "schedule mismatch" // 页面显示的定时时间与期望不一致
+	ErrorCategoryCaptcha      = "captcha"           // 出现安全验证且未在等待时间内完成
+	ErrorCategoryBreaker      = "account breaker"   // 账号已熔断，任务未执行
+	ErrorCategoryDailyLimit   = "daily limit"       // 平台提示今日发表次数已达上限，次日重试
+	ErrorCategoryUnknown      = "unknown"
+)
+
+// categoryRule 包含任一关键字（不区分大小写）的错误信息属于 category
+type categoryRule struct {
+	category string
+	keywords []string
+}
+
+// errorCategoryRules 按顺序匹配错误信息，先匹配到的分类优先
+var errorCategoryRules = []categoryRule{
+	{ErrorCategoryBreaker, []string{"已熔断"}},
+	{ErrorCategoryLoginExpired, []string{"登录信息失效", "登录失败", "登录超时", "不在正确的上传页面"}},
+	{ErrorCategorySchedule, []string{"定时时间校验失败"}},
+	{ErrorCategoryCaptcha, []string{"安全验证"}},
+	{ErrorCategoryUpload, []string{"上传过程中出现错误", "所有上传方法都失败", "设置文件失败", "等待上传完成超时"}},
+	{ErrorCategoryInvalidInput, []string{"不支持的", "解析时间失败", "无法从字符串中提取时间信息", "定时时间超出范围", "定时时间不能为空", "必须以发表方式保存", "时间格式错误", "没有匹配的草稿", "无法确定要更新的草稿", "与当前登录的视频号", "文件为空", "可能是占位文件"}},
+	{ErrorCategorySelector, []string{"未找到", "不可见", "无法填写", "strict mode violation"}},
+	{ErrorCategoryTimeout, []string{"超时", "timeout"}},
+	{ErrorCategoryRejected, []string{"操作失败"}},
+}
+
+// retryableCategory 失败分类是否值得重试：取值不支持、定时时间不一致时重试也不会成功
+func retryableCategory(category string) bool {
+	return category != ErrorCategoryInvalidInput && category != ErrorCategorySchedule
+}
+
+// classifyError 根据错误信息判断失败分类，错误信息中有已知的平台提示时按平台提示分类
+func classifyError(message string) string {
+	if isTargetClosedMessage(message) {
+		return ErrorCategoryBrowserCrash
+	}
+	if category := platformMessageCategory(message); category != "" {
+		return category
+	}
+	if category := matchCategory(errorCategoryRules, message); category != "" {
+		return category
+	}
+	return ErrorCategoryUnknown
+}
+
+// matchCategory 返回第一个匹配的规则的分类，都不匹配时为空
+func matchCategory(rules []categoryRule, message string) string {
+	lower := strings.ToLower(message)
+	for _, rule := range rules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(lower, strings.ToLower(keyword)) {
+				return rule.category
+			}
+		}
+	}
+	return ""
+}
+
+// stepPhase 步骤所属阶段：command、navigation、upload、form、submit、verification
+func stepPhase(step string) string {
+	switch step {
+	case StepBeforeTask, StepAfterTask:
+		return "command"
+	case StepNavigation, StepContentType, StepOpenDraft:
+		return "navigation"
+	case StepFileUpload:
+		return "upload"
+	case StepSubmit:
+		return "submit"
+	case StepVerification, StepScheduleCheck, StepPublishCheck:
+		return "verification"
+	case "":
+		return ""
+	default:
+		return "form"
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/extrame/xls"
+	"github.com/xuri/excelize/v2"
+)
+
+// 工作簿文件格式（按文件头识别，不依赖扩展名）
+const (
+	workbookFormatOOXML = "ooxml" // .xlsx / .xlsm（含 WPS 另存的文件）
+	workbookFormatBIFF  = "biff"  // 旧版 .xls（OLE2 复合文档）
+)
+
+var (
+	zipSignature  = []byte("PK\x03\x04")
+	ole2Signature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+)
+
+// scheduleTimeLayouts 定时时间支持的格式，WPS 等软件保存后格式可能与 Excel 不同
+var scheduleTimeLayouts = []string{
+	"2006/1/2 15:04",
+	"2006/1/2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2 15:04:05",
+	"2006年1月2日 15:04",
+	"2006.1.2 15:04",
+}
+
+// sniffWorkbookFormat 读取文件头判断工作簿格式
+func sniffWorkbookFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开Excel文件失败: %v", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, len(ole2Signature))
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("读取Excel文件失败: %v", err)
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, zipSignature):
+		return workbookFormatOOXML, nil
+	case bytes.HasPrefix(header, ole2Signature):
+		return workbookFormatBIFF, nil
+	default:
+		return "", fmt.Errorf("无法识别的文件格式(不是 Excel 工作簿)，CSV/JSON 任务请使用 .csv/.json 扩展名或指定 -format")
+	}
+}
+
+// readWorkbookRows 读取任务表的所有行：优先读取 Sheet1，WPS 等软件重命名时读取第一个工作表
+func readWorkbookRows(path string) ([][]string, error) {
+	format, err := sniffWorkbookFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == workbookFormatBIFF {
+		rows, err := readBIFFRows(path)
+		if err != nil {
+			return nil, err
+		}
+		return normalizeRows(rows), nil
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheet := taskSheetName(f)
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("读取%s失败: %v", sheet, err)
+	}
+	return normalizeRows(rows), nil
+}
+
+// readBIFFRows 读取旧版 .xls (BIFF8) 工作簿的第一个工作表
+func readBIFFRows(path string) (rows [][]string, err error) {
+	// 解析库遇到异常数据时可能 panic，转换为错误返回
+	defer func() {
+		if r := recover(); r != nil {
+			rows, err = nil, fmt.Errorf("解析旧版 .xls 文件失败: %v；请用 Excel 或 WPS 打开后\"另存为\" .xlsx 格式再使用", r)
+		}
+	}()
+
+	workbook, err := xls.Open(path, "utf-8")
+	if err != nil {
+		return nil, fmt.Errorf("打开旧版 .xls 文件失败: %v", err)
+	}
+	if workbook == nil || workbook.NumSheets() == 0 {
+		// 加密的 .xlsx 同样是 OLE2 复合文档，但没有 Workbook 数据
+		return nil, fmt.Errorf("无法读取该 .xls 文件(可能已加密)；请取消密码后用 Excel 或 WPS \"另存为\" .xlsx 格式再使用")
+	}
+
+	sheet := workbook.GetSheet(0)
+	for i := 0; i <= int(sheet.MaxRow); i++ {
+		row := biffRow(sheet, i)
+		if row == nil {
+			rows = append(rows, nil)
+			continue
+		}
+		var cells []string
+		for j := 0; j <= row.LastCol(); j++ {
+			cell := row.Col(j)
+			if cell == "FormulaCol" {
+				return nil, fmt.Errorf("旧版 .xls 第%d行包含公式，无法读取公式结果；请另存为 .xlsx 格式再使用", i+1)
+			}
+			cells = append(cells, cell)
+		}
+		// 去掉行尾的空单元格，与 excelize 的 GetRows 保持一致
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// biffRow 获取一行，空行返回 nil（解析库对不存在的行会 panic）
+func biffRow(sheet *xls.WorkSheet, i int) (row *xls.Row) {
+	defer func() {
+		if recover() != nil {
+			row = nil
+		}
+	}()
+	return sheet.Row(i)
+}
+
+// openWorkbookForResults 打开任务文件用于写回结果；旧版 .xls、CSV、JSON 和标准输入
+// 无法直接写入，按读取的内容新建工作簿
+func openWorkbookForResults(source *TaskSource) (*excelize.File, error) {
+	if source.Format == TaskFormatExcel {
+		format, err := sniffWorkbookFormat(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		if format == workbookFormatOOXML {
+			f, err := excelize.OpenFile(source.Path)
+			if err != nil {
+				return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+			}
+			return f, nil
+		}
+	}
+
+	f := excelize.NewFile()
+	for i, row := range source.Rows {
+		values := make([]interface{}, len(row))
+		for j, cell := range row {
+			values[j] = cell
+		}
+		if err := setRow(f, resultsTaskSheet, 1, i+1, values); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// taskSheetName 任务所在的工作表
+func taskSheetName(f *excelize.File) string {
+	sheets := f.GetSheetList()
+	for _, sheet := range sheets {
+		if sheet == resultsTaskSheet {
+			return sheet
+		}
+	}
+	if len(sheets) > 0 {
+		return sheets[0]
+	}
+	return resultsTaskSheet
+}
+
+// normalizeRows 去除 WPS 等软件带入的 BOM、不间断空格和零宽字符
+func normalizeRows(rows [][]string) [][]string {
+	replacer := strings.NewReplacer("\ufeff", "", "\u200b", "", "\u00a0", " ", "\u3000", " ")
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = replacer.Replace(cell)
+		}
+	}
+	return rows
+}
+
+// parseScheduleTime 解析定时时间，兼容多种日期格式和未带格式的 Excel 日期序列号
+func parseScheduleTime(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	for _, layout := range scheduleTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// 单元格类型丢失时日期以序列号保存，如 45678.5
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			// 序列号没有时区，按本地时间解释，并舍入到分钟
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+			return t.Round(time.Minute), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("时间格式错误: %s", value)
+}
@@ -7,54 +7,94 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
-
-	"github.com/playwright-community/playwright-go"
-	"github.com/xuri/excelize/v2"
 )
 
-// VideoCreateTask 上传任务结构体
+// VideoCreateTask 上传任务结构体（只包含从Excel解析的输入，执行结果见 TaskResult）
 type VideoCreateTask struct {
-	Description  string
-	Location     string
-	Collection   string
-	Link         string
-	Activity     string
-	Schedule     bool
-	ScheduleTime string
-	ShortTitle   string
-	Action       string
-	VideoPath    string
-	RowIndex     int
-	Page         *playwright.Page
-	ChannelName  string
-	Success      bool
-	Error        string
+	Description  string   `json:"description,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	Collection   string   `json:"collection,omitempty"`
+	Link         string   `json:"link,omitempty"`
+	Activity     string   `json:"activity,omitempty"`
+	Schedule     bool     `json:"schedule,omitempty"`
+	ScheduleTime string   `json:"schedule_time,omitempty"`
+	ShortTitle   string   `json:"short_title,omitempty"`
+	Action       string   `json:"action,omitempty"`
+	VideoPath    string   `json:"video_path"`
+	ContentType  string   `json:"content_type,omitempty"` // 内容类型列，为空时为普通视频
+	ImagePaths   []string `json:"-"`                      // 图文动态的图片，由视频位置列拆分
+	Music        string   `json:"music,omitempty"`        // 音乐列，按歌名搜索平台曲库
+	CoverCrop    string   `json:"cover_crop,omitempty"`   // 封面裁剪列，如"3:4 x=0 y=-20"
+	CoverImage   string   `json:"cover_image,omitempty"`  // 封面图片列，为空时使用平台截取的封面
+	Tags         []string `json:"tags,omitempty"`         // 话题列，发表时以 #话题 追加到描述末尾
+	Retry        string   `json:"retry,omitempty"`        // 重试列，如"max_attempts=3 backoff=30m"，覆盖配置文件 retry_queue
+	Note         string   `json:"note,omitempty"`         // 备注列和定时、保存方式列的批注，带入结果和通知
+	Campaign     string   `json:"campaign,omitempty"`     // 投放活动列，只用于分组统计
+	Account      string   `json:"account,omitempty"`      // 账号列，为空时使用 -login 登录的账号
+	RowIndex     int      `json:"row_index"`
+
+	Extra map[string]string `json:"extra,omitempty"` // J列之后的列（列名 -> 值），供自定义表单步骤使用
 }
 
-// ValidateExcelFile 验证Excel文件并解析任务
-func ValidateExcelFile(filePath string) ([]VideoCreateTask, error) {
-	log.Println("🔍 验证Excel文件格式...")
+// ValidateTaskFile 验证任务文件并解析任务：按扩展名识别 Excel（.xlsx/.xlsm/.xls）、CSV、JSON，
+// 各格式的列含义相同，得到相同的任务；视频位置中的相对路径相对于任务文件所在目录
+func ValidateTaskFile(filePath string) ([]VideoCreateTask, error) {
+	log.Println("🔍 验证任务文件格式...")
 
 	// 检查文件是否存在
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("文件不存在: %s", filePath)
 	}
 
-	// 打开Excel文件
-	f, err := excelize.OpenFile(filePath)
+	source, err := LoadTaskSource(filePath, TaskFormatAuto)
+	if err != nil {
+		return nil, err
+	}
+	tasks, rowErrors, err := parseTaskRows(source.Rows, true, source.BaseDir, nil)
 	if err != nil {
-		return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+		return nil, err
 	}
-	defer f.Close()
+	if len(rowErrors) > 0 {
+		return nil, taskRowsError(rowErrors)
+	}
+	source.applyNotes(tasks)
+	return tasks, nil
+}
 
-	// 获取Sheet1
-	rows, err := f.GetRows("Sheet1")
+// validateTaskRows 校验表头并解析数据行，Excel、CSV、JSON 任务共用；
+// checkVideos 为 false 时不检查视频文件是否存在（由前置命令准备视频），相对路径相对于当前目录
+func validateTaskRows(rows [][]string, checkVideos bool) ([]VideoCreateTask, error) {
+	tasks, rowErrors, err := parseTaskRows(rows, checkVideos, "", nil)
 	if err != nil {
-		return nil, fmt.Errorf("读取Sheet1失败: %v", err)
+		return nil, err
+	}
+	if len(rowErrors) > 0 {
+		return nil, taskRowsError(rowErrors)
 	}
+	log.Printf("✅ 任务验证成功，共 %d 个上传任务", len(tasks))
+	return tasks, nil
+}
 
+// rowError 数据行的校验错误
+type rowError struct {
+	Row int // 行号，表头为第1行
+	Err error
+}
+
+// taskRowsError 合并各行的校验错误
+func taskRowsError(rowErrors []rowError) error {
+	lines := make([]string, len(rowErrors))
+	for i, rowErr := range rowErrors {
+		lines[i] = fmt.Sprintf("第%d行: %v", rowErr.Row, rowErr.Err)
+	}
+	return fmt.Errorf("数据行错误:\n%s", strings.Join(lines, "\n"))
+}
+
+// parseTaskRows 校验表头并解析数据行，返回解析成功的任务和校验失败的行；视频位置中的相对路径相对于 baseDir，
+// skip 中的行（如已隔离的行）不解析
+func parseTaskRows(rows [][]string, checkVideos bool, baseDir string, skip map[int]bool) ([]VideoCreateTask, []rowError, error) {
 	if len(rows) < 2 {
-		return nil, fmt.Errorf("Excel文件没有数据行")
+		return nil, nil, fmt.Errorf("任务文件没有数据行")
 	}
 
 	// 检查表头
@@ -77,136 +117,171 @@ func ValidateExcelFile(filePath string) ([]VideoCreateTask, error) {
 	}
 
 	if len(missingColumns) > 0 {
-		return nil, fmt.Errorf("缺少必要的列: %v", missingColumns)
+		return nil, nil, fmt.Errorf("缺少必要的列: %v", missingColumns)
 	}
 
 	log.Printf("✅ 表头验证成功，开始检查数据行...")
 
 	// 解析数据行
 	var tasks []VideoCreateTask
-	var errors []string
+	var rowErrors []rowError
 
 	for i, row := range rows[1:] {
 		rowIndex := i + 2 // Excel行号从1开始，表头占1行
-		task, err := parseTaskFromRow(row)
+		if skip[rowIndex] {
+			continue
+		}
+		enabled, err := parseEnabled(columnValue(headerMap, row, enabledColumn))
+		if err != nil {
+			rowErrors = append(rowErrors, rowError{rowIndex, err})
+			continue
+		}
+		if !enabled {
+			log.Printf("⏸️ 第%d行: 未启用，跳过", rowIndex)
+			continue
+		}
+		if note := columnValue(headerMap, row, noteColumn); isSkipNote(note) {
+			log.Printf("⏭️ 第%d行: 备注为\"%s\"，跳过", rowIndex, note)
+			continue
+		}
+		task, err := parseTaskFromRow(row, checkVideos, baseDir)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("第%d行: %v", rowIndex, err))
+			rowErrors = append(rowErrors, rowError{rowIndex, err})
 			continue
 		}
+		contentType := columnValue(headerMap, row, contentTypeColumn)
+		if task.ContentType, err = parseContentType(contentType); err == nil {
+			err = resolveMedia(&task, contentType != "")
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, rowError{rowIndex, err})
+			continue
+		}
+		task.CoverCrop = columnValue(headerMap, row, coverCropColumn)
+		if _, err := parseCoverCrop(task.CoverCrop); err != nil {
+			rowErrors = append(rowErrors, rowError{rowIndex, err})
+			continue
+		}
+		if task.CoverImage, err = parseCoverImage(columnValue(headerMap, row, coverImageColumn), checkVideos, baseDir); err != nil {
+			rowErrors = append(rowErrors, rowError{rowIndex, err})
+			continue
+		}
+		task.Retry = columnValue(headerMap, row, retryColumn)
+		if _, err := parseTaskRetry(task.Retry); err != nil {
+			rowErrors = append(rowErrors, rowError{rowIndex, err})
+			continue
+		}
+		task.Tags = parseTags(columnValue(headerMap, row, tagsColumn))
+		task.Music = columnValue(headerMap, row, musicColumn)
+		task.Note = columnValue(headerMap, row, noteColumn)
+		task.Campaign = columnValue(headerMap, row, campaignColumn)
+		task.Account = columnValue(headerMap, row, accountColumn)
 		task.RowIndex = rowIndex
+		task.Extra = extraColumns(headers, row)
 		tasks = append(tasks, task)
 	}
-
-	if len(errors) > 0 {
-		return nil, fmt.Errorf("数据行错误:\n%s", strings.Join(errors, "\n"))
-	}
-
-	log.Printf("✅ Excel文件验证成功，共 %d 个上传任务", len(tasks))
-	return tasks, nil
+	return tasks, rowErrors, nil
 }
 
-// parseTaskFromRow 从Excel行解析任务
-func parseTaskFromRow(row []string) (VideoCreateTask, error) {
-	task := VideoCreateTask{}
-
-	// 视频描述 (A列)
-	if len(row) > 0 {
-		task.Description = strings.TrimSpace(row[0])
-	}
-
-	// 位置 (B列)
-	if len(row) > 1 {
-		task.Location = strings.TrimSpace(row[1])
-	}
-
-	// 添加到合集 (C列)
-	if len(row) > 2 {
-		task.Collection = strings.TrimSpace(row[2])
+// columnValue 按表头读取J列之后的可选列，没有该列时返回空
+func columnValue(headerMap map[string]int, row []string, name string) string {
+	if i, ok := headerMap[name]; ok && i < len(row) {
+		return strings.TrimSpace(row[i])
 	}
+	return ""
+}
 
-	// 链接 (D列)
-	if len(row) > 3 {
-		task.Link = strings.TrimSpace(row[3])
+// extraColumns 读取J列之后有表头且不为空的列（内容类型等可选列除外）
+func extraColumns(headers []string, row []string) map[string]string {
+	var extra map[string]string
+	for i := len(taskColumns); i < len(headers) && i < len(row); i++ {
+		name := strings.TrimSpace(headers[i])
+		value := strings.TrimSpace(row[i])
+		if name == "" || value == "" || isOptionalTaskColumn(name) {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]string)
+		}
+		extra[name] = value
 	}
+	return extra
+}
 
-	// 活动 (E列)
-	if len(row) > 4 {
-		task.Activity = strings.TrimSpace(row[4])
+// parseTaskFromRow 从Excel行解析任务，视频位置中的相对路径相对于 baseDir（为空时相对于当前目录）；
+// excelize 读取时省略行尾的空单元格，缺少的列按空单元格处理
+func parseTaskFromRow(row []string, checkVideo bool, baseDir string) (VideoCreateTask, error) {
+	cells := taskRowCells(row)
+	task := VideoCreateTask{
+		Description:  cells.get(colDescription),      // 视频描述 (A列)
+		Location:     cells.get(colLocation),         // 位置 (B列)
+		Collection:   cells.get(colCollection),       // 添加到合集 (C列)
+		Link:         cells.get(colLink),             // 链接 (D列)
+		Activity:     cells.get(colActivity),         // 活动 (E列)
+		Schedule:     cells.get(colSchedule) == "定时", // 定时发表 (F列)
+		ScheduleTime: cells.get(colScheduleTime),     // 定时时间 (G列)，与保存方式一起按定时规则校验
+		ShortTitle:   cells.get(colShortTitle),       // 短标题 (H列)
 	}
 
-	// 定时发表 (F列)
-	if len(row) > 5 {
-		schedule := strings.TrimSpace(row[5])
-		task.Schedule = schedule == "定时"
+	// 保存方式 (I列) - 必需
+	switch action := cells.get(colAction); action {
+	case "":
+		return task, fmt.Errorf("缺少保存方式")
+	case "保存草稿":
+		task.Action = "save_draft"
+	case "手机预览":
+		task.Action = "preview"
+	case "发表":
+		task.Action = "publish"
+	case "仅上传不提交":
+		task.Action = actionStage
+	default:
+		return task, fmt.Errorf("不支持的保存方式: %s", action)
 	}
 
-	// 定时时间 (G列)
-	if row[5] == "定时" && row[6] == "" {
-		return task, fmt.Errorf("定时发表时定时时间不能为空")
+	// 校验定时时间范围和保存方式，统一为 年/月/日 时:分 格式
+	var err error
+	if task.ScheduleTime, err = scheduleRules.Validate(task.Schedule, task.ScheduleTime, task.Action, time.Now()); err != nil {
+		return task, err
 	}
-	if len(row) > 6 {
-		task.ScheduleTime = strings.TrimSpace(row[6])
-		if row[5] == "定时" && task.ScheduleTime != "" {
-			// 直接解析并校验
-			targetTime, err := time.Parse("2006/01/2 15:04", task.ScheduleTime)
-			if err != nil {
-				return task, fmt.Errorf("时间格式错误")
-			}
 
-			now := time.Now()
-			if targetTime.Before(now) || targetTime.After(now.Add(30*24*time.Hour)) {
-				return task, fmt.Errorf("定时时间需要大于当前时间且在一个月内")
-			}
-		}
+	// 视频位置 (J列) - 必需，图文动态可以是多张图片
+	location := cells.get(colVideoPath)
+	if location == "" {
+		return task, fmt.Errorf("缺少视频位置")
 	}
-
-	// 短标题 (H列)
-	if len(row) > 7 {
-		task.ShortTitle = strings.TrimSpace(row[7])
+	// 相对路径按任务文件目录解析；Excel 中的文件名与磁盘上的 Unicode 形式可能不一致，统一为实际路径，之后的检查和上传都使用该路径
+	videoPath := resolveMediaPaths(location, baseDir)
+	if videoPath == "" {
+		return task, fmt.Errorf("视频位置不能为空")
 	}
-
-	// 保存方式 (I列) - 必需
-	if len(row) > 8 {
-		action := strings.TrimSpace(row[8])
-		if row[5] == "定时" && row[8] == "保存草稿" {
-			return task, fmt.Errorf("定时发表方式必须以发表方式保存")
+	// 检查视频格式，以及视频或图片文件是否存在、是否为空（由前置命令准备视频时只检查格式）
+	for _, path := range splitMediaPaths(videoPath) {
+		if err := mediaRules.CheckExtension(path); err != nil {
+			return task, err
 		}
-		switch action {
-		case "保存草稿":
-			task.Action = "save_draft"
-		case "手机预览":
-			task.Action = "preview"
-		case "发表":
-			task.Action = "publish"
-		default:
-			return task, fmt.Errorf("不支持的保存方式: %s", action)
+		if !checkVideo {
+			continue
 		}
-	} else {
-		return task, fmt.Errorf("缺少保存方式")
-	}
-
-	// 视频位置 (J列) - 必需
-	if len(row) > 9 {
-		videoPath := strings.TrimSpace(row[9])
-		if videoPath == "" {
-			return task, fmt.Errorf("视频位置不能为空")
+		if exists, err := checkFileExists(path, ""); !exists {
+			if isImagePath(path) {
+				return task, fmt.Errorf("图片文件不存在: %s, %s", path, err)
+			}
+			return task, fmt.Errorf("视频文件不存在: %s, %s", path, err)
 		}
-		// 检查视频文件是否存在
-		if exists, err := checkFileExists(videoPath, ""); !exists {
-			return task, fmt.Errorf("视频文件不存在: %s, %s", videoPath, err)
+		if err := mediaRules.CheckSize(path); err != nil {
+			return task, err
 		}
-		task.VideoPath = videoPath
-	} else {
-		return task, fmt.Errorf("缺少视频位置")
 	}
+	task.VideoPath = videoPath
 
 	return task, nil
 }
 
 // 检查文件是否存在（支持相对路径和绝对路径）
 func checkFileExists(filename string, extension string) (bool, error) {
-	// filepath.Abs 会自动处理相对路径和绝对路径
-	absPath, err := filepath.Abs(filename)
+	// filepath.Abs 会自动处理相对路径和绝对路径，文件名按 Unicode 规范化后匹配
+	absPath, err := filepath.Abs(resolvePath(filename))
 	if err != nil {
 		return false, fmt.Errorf("无法解析文件路径 %s: %v", filename, err)
 	}
@@ -227,35 +302,65 @@ func checkFileExists(filename string, extension string) (bool, error) {
 	// 检查文件扩展名
 	if extension == "xls" || extension == "xlsx" {
 		ext := strings.ToLower(filepath.Ext(absPath))
-		if ext != ".xls" && ext != ".xlsx" {
-			return false, fmt.Errorf("文件扩展名不是 .xls 或 .xlsx: %s", filename)
+		if ext != ".xls" && ext != ".xlsx" && ext != ".xlsm" {
+			return false, fmt.Errorf("文件扩展名不是 .xls、.xlsx 或 .xlsm: %s", filename)
 		}
 	}
 	return true, nil
 }
 
 // PrintVideoCreateResults 打印上传结果
-func PrintVideoCreateResults(results []VideoCreateTask) {
+func PrintVideoCreateResults(results []TaskResult) {
 	log.Println("\n📊 ===== 上传结果统计 =====")
 
-	successCount := 0
-	failCount := 0
-
 	for _, result := range results {
-		if result.Success {
-			successCount++
-			log.Printf("✅ 第%d行: %s - 成功",
-				result.RowIndex, filepath.Base(result.VideoPath))
+		if result.Success && result.ManualSchedule != "" {
+			log.Printf("⏸️ 第%d行: %s - 已保存草稿，需手动设置定时: %s",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath), result.ManualSchedule)
+		} else if result.Success && result.Staged {
+			log.Printf("⏸️ 第%d行: %s - 已上传并填写表单，待手动发表",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath))
+		} else if result.Success && result.UploadOnly {
+			log.Printf("📥 第%d行: %s - 已上传并保存草稿，待补充信息",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath))
+		} else if result.Success && result.Draft != "" {
+			log.Printf("📝 第%d行: %s - 已更新草稿（%s）",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath), result.Draft)
+		} else if result.Success && result.AlreadyPublished != "" {
+			log.Printf("⏭️ 第%d行: %s - 已发表，重试时未再发表: %s",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath), result.AlreadyPublished)
+		} else if result.Success {
+			upload := formatUpload(result)
+			if upload != "" {
+				upload = ", " + upload
+			}
+			log.Printf("✅ 第%d行: %s - 成功%s",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath), upload)
 		} else {
-			failCount++
-			log.Printf("❌ 第%d行: %s - 失败: %s",
-				result.RowIndex, filepath.Base(result.VideoPath), result.Error)
+			log.Printf("❌ 第%d行: %s - 失败[%s/%s]: %s",
+				result.Task.RowIndex, filepath.Base(result.Task.VideoPath),
+				result.ErrorCategory, stepPhase(result.FailedStep), result.Error)
+			if result.Trace != "" {
+				log.Printf("   🧭 步骤: %s", result.Trace)
+			}
 		}
 	}
 
-	log.Printf("📈 总计: %d 成功, %d 失败", successCount, failCount)
+	// 按失败分类、阶段和错误信息汇总
+	summary := summarizeResults(results)
+	for _, line := range summary.Lines() {
+		log.Printf("📈 %s", line)
+	}
+	if notes := noteLines(results); len(notes) > 0 {
+		log.Println("📝 ===== 操作备注 =====")
+		for _, line := range notes {
+			log.Printf("📝 %s", line)
+		}
+	}
+	printAccountSummaries(results)
+	printStepSummary(results)
 
-	if failCount > 0 {
-		log.Printf("⚠️ 有 %d 个文件上传失败，详情请查看: wechat_channel_uploader.log", failCount)
+	if summary.Failed > 0 {
+		log.Printf("⚠️ 有 %d 个文件上传失败，详情请查看: wechat_channel_uploader.log", summary.Failed)
 	}
 }
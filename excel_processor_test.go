@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// sampleTaskRow 完整的任务行（A-J列），定时发表时定时时间为两天后
+func sampleTaskRow(schedule bool) []string {
+	row := []string{"视频描述", "", "", "", "", "不定时", "", "短标题", "发表", "video.mp4"}
+	if schedule {
+		row[colSchedule] = "定时"
+		row[colScheduleTime] = time.Now().Add(48 * time.Hour).Format("2006/01/02 15:04")
+	}
+	return row
+}
+
+func TestParseTaskFromRowLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule bool
+		length   int
+		wantErr  string // 为空时应解析成功
+	}{
+		{"5列", false, 5, "缺少保存方式"},
+		{"6列", false, 6, "缺少保存方式"},
+		{"6列定时", true, 6, "缺少保存方式"},
+		{"7列", false, 7, "缺少保存方式"},
+		{"7列定时", true, 7, "缺少保存方式"},
+		{"8列", false, 8, "缺少保存方式"},
+		{"8列定时", true, 8, "缺少保存方式"},
+		{"9列", false, 9, "缺少视频位置"},
+		{"9列定时", true, 9, "缺少视频位置"},
+		{"10列", false, 10, ""},
+		{"10列定时", true, 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := sampleTaskRow(tt.schedule)[:tt.length]
+			task, err := parseTaskFromRow(row, false, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseTaskFromRow(%d列) error = %v, want %q", tt.length, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTaskFromRow(%d列) error = %v", tt.length, err)
+			}
+			if task.Schedule != tt.schedule || task.Action != "publish" || task.VideoPath == "" {
+				t.Errorf("parseTaskFromRow(%d列) = %+v", tt.length, task)
+			}
+			if tt.schedule && task.ScheduleTime != row[colScheduleTime] {
+				t.Errorf("ScheduleTime = %q, want %q", task.ScheduleTime, row[colScheduleTime])
+			}
+		})
+	}
+}
+
+func TestParseTaskFromRowTrailingEmptyCells(t *testing.T) {
+	// 定时发表但定时时间为空：excelize 读到的行可能在G列之后截断，也可能保留空单元格
+	row := sampleTaskRow(true)
+	row[colScheduleTime] = ""
+	if _, err := parseTaskFromRow(row, false, ""); err == nil || !strings.Contains(err.Error(), "定时时间不能为空") {
+		t.Errorf("定时时间为空 error = %v", err)
+	}
+
+	// 视频位置只有空白
+	row = sampleTaskRow(false)
+	row[colVideoPath] = "  "
+	if _, err := parseTaskFromRow(row, false, ""); err == nil || !strings.Contains(err.Error(), "缺少视频位置") {
+		t.Errorf("视频位置为空白 error = %v", err)
+	}
+
+	// J列之后的空单元格不影响解析
+	row = append(sampleTaskRow(false), "", "")
+	if _, err := parseTaskFromRow(row, false, ""); err != nil {
+		t.Errorf("行尾有空单元格 error = %v", err)
+	}
+}
+
+func TestTaskRowCells(t *testing.T) {
+	cells := taskRowCells{" 描述 ", "位置"}
+	tests := []struct {
+		column int
+		want   string
+	}{
+		{colDescription, "描述"},
+		{colLocation, "位置"},
+		{colCollection, ""},
+		{colVideoPath, ""},
+		{-1, ""},
+	}
+	for _, tt := range tests {
+		if got := cells.get(tt.column); got != tt.want {
+			t.Errorf("get(%d) = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+}
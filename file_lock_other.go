@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// probeExclusiveOpen 非 Windows 系统没有强制的独占打开，只按大小和修改时间判断
+func probeExclusiveOpen(path string) error {
+	return nil
+}
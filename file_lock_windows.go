@@ -0,0 +1,27 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// probeExclusiveOpen 以不共享的方式打开文件，其他程序仍打开该文件（如正在写入）时返回错误
+func probeExclusiveOpen(path string) error {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+	handle, err := windows.CreateFile(name, windows.GENERIC_READ, 0, nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return fmt.Errorf("文件正被其他程序占用")
+	}
+	if err != nil {
+		return nil
+	}
+	windows.CloseHandle(handle)
+	return nil
+}
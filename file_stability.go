@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 视频文件写入检查
+const (
+	defaultFileStableFor = 5 * time.Second  // 大小和修改时间不变多久后视为已写完
+	fileStableTimeout    = 10 * time.Minute // 等待文件写完的最长时间
+	fileStablePoll       = time.Second      // 等待时检查的间隔
+	maxFileDeferrals     = 3                // 顺序模式下一个任务最多移到队列末尾的次数
+)
+
+// FileStabilityOptions 上传前检查视频文件是否已写完（渲染程序仍在写入时上传会得到截断的视频）：
+// 文件大小和修改时间在 StableFor 内没有变化，并可检查文件是否仍被其他程序独占打开
+type FileStabilityOptions struct {
+	StableFor time.Duration // 为 0 时不检查
+	LockCheck bool          // 以独占方式打开文件，其他程序仍在写入时打开失败（仅 Windows）
+	Timeout   time.Duration // 等待文件写完的最长时间，为 0 时为 fileStableTimeout
+
+	tracker *fileTracker
+}
+
+// fileObservation 文件的大小、修改时间及首次观察到该状态的时间
+type fileObservation struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// fileTracker 记录各文件最近一次变化后首次观察到的时间，并发任务共用
+type fileTracker struct {
+	mu   sync.Mutex
+	seen map[string]fileObservation
+}
+
+// enabled 是否检查，Observe 之后才开始检查
+func (o *FileStabilityOptions) enabled() bool {
+	return o != nil && o.StableFor > 0 && o.tracker != nil
+}
+
+// Observe 开始检查并记录任务文件当前的大小和修改时间，运行开始时调用，执行到该任务时通常已无需等待
+func (o *FileStabilityOptions) Observe(tasks []VideoCreateTask) {
+	if o.StableFor <= 0 {
+		return
+	}
+	if o.tracker == nil {
+		o.tracker = &fileTracker{seen: make(map[string]fileObservation)}
+	}
+	for _, task := range tasks {
+		for _, path := range taskMediaPaths(task) {
+			o.unstableReason(path)
+		}
+	}
+}
+
+// Unstable 任务中尚未写完的文件及原因，都已写完时原因为空
+func (o *FileStabilityOptions) Unstable(task VideoCreateTask) (string, string) {
+	if !o.enabled() {
+		return "", ""
+	}
+	for _, path := range taskMediaPaths(task) {
+		if reason := o.unstableReason(path); reason != "" {
+			return path, reason
+		}
+	}
+	return "", ""
+}
+
+// WaitStable 等待任务的文件写完，超过 Timeout 仍未写完时返回错误
+func (o *FileStabilityOptions) WaitStable(task VideoCreateTask) error {
+	path, reason := o.Unstable(task)
+	if reason == "" {
+		return nil
+	}
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = fileStableTimeout
+	}
+	log.Printf("⏳ 视频文件未写完(%s): %s，等待写入完成...", reason, filepath.Base(path))
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(fileStablePoll)
+		if _, reason = o.Unstable(task); reason == "" {
+			log.Printf("✅ 视频文件已写完: %s", filepath.Base(path))
+			return nil
+		}
+	}
+	return fmt.Errorf("等待视频文件写入完成超时(%v): %s (%s)", timeout, path, reason)
+}
+
+// unstableReason 文件未写完的原因，已写完或无法读取时返回空（文件不存在等错误由上传步骤报告）
+func (o *FileStabilityOptions) unstableReason(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	now := time.Now()
+	o.tracker.mu.Lock()
+	observed, ok := o.tracker.seen[path]
+	if !ok || observed.size != info.Size() || !observed.modTime.Equal(info.ModTime()) {
+		observed = fileObservation{size: info.Size(), modTime: info.ModTime(), since: now}
+		o.tracker.seen[path] = observed
+	}
+	o.tracker.mu.Unlock()
+
+	// 修改时间早于观察开始时，文件至少从修改时间起就没有变化
+	unchanged := now.Sub(observed.since)
+	if age := now.Sub(info.ModTime()); age > unchanged {
+		unchanged = age
+	}
+	if unchanged < o.StableFor {
+		return fmt.Sprintf("%v 内大小或修改时间有变化", o.StableFor)
+	}
+	if o.LockCheck {
+		if err := probeExclusiveOpen(path); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// capabilityProbeTimeout 探测每个控件时等待其出现的时间
+const capabilityProbeTimeout = 2000
+
+// capabilityOriginal 声明原创控件，没有内置字段（可用自定义表单步骤），只在探测结果中报告
+const capabilityOriginal = "original"
+
+// capabilityProbes 探测的可选控件（名称与表单字段相同）及其元素
+var capabilityProbes = []struct {
+	name     string
+	label    string
+	selector string
+}{
+	{StepLocation, "位置", locationSelector},
+	{StepCollection, "合集", collectionSelector},
+	{StepActivity, "活动", activitySelector},
+	{StepLink, "链接", linkSelector},
+	{capabilityOriginal, "声明原创", ".declare-original-checkbox"},
+}
+
+// formCapabilities 当前账号上传页面有哪些可选控件：每种内容类型的第一个任务探测一次，之后的任务直接跳过没有的控件，
+// 不再等待选择器超时；为 nil 时不探测
+type formCapabilities struct {
+	mu     sync.Mutex
+	absent map[string]map[string]bool // 内容类型 -> 没有的控件
+}
+
+// newFormCapabilities 创建空的探测结果，每次运行（每个账号）重新探测
+func newFormCapabilities() *formCapabilities {
+	return &formCapabilities{absent: make(map[string]map[string]bool)}
+}
+
+// detect 返回上传页面上没有的控件，该内容类型尚未探测时先探测并在日志中报告；
+// 同时执行的任务等待探测完成后使用同一结果
+func (c *formCapabilities) detect(page playwright.Page, contentType string, channelName string) map[string]bool {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if absent, ok := c.absent[contentType]; ok {
+		return absent
+	}
+
+	absent := make(map[string]bool)
+	var report []string
+	for _, probe := range capabilityProbes {
+		err := page.Locator(probe.selector).First().WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateAttached,
+			Timeout: playwright.Float(capabilityProbeTimeout),
+		})
+		if page.IsClosed() {
+			// 页面关闭时的结果不可信，下一个任务重新探测
+			return nil
+		}
+		if err != nil {
+			absent[probe.name] = true
+			report = append(report, probe.label+" ✗")
+		} else {
+			report = append(report, probe.label+" ✓")
+		}
+	}
+	c.absent[contentType] = absent
+	log.Printf("🧩 %s上传页面的可选控件（%s）: %s，没有的控件本次运行中直接跳过",
+		accountLabel(channelName), contentType, strings.Join(report, " "))
+	return absent
+}
+
+// capabilityLabel 控件的中文名称
+func capabilityLabel(name string) string {
+	for _, probe := range capabilityProbes {
+		if probe.name == name {
+			return probe.label
+		}
+	}
+	return name
+}
+
+// accountLabel 日志中的账号名称，还没有读取到视频号名称时为"当前账号"
+func accountLabel(channelName string) string {
+	if channelName == "" {
+		return "当前账号"
+	}
+	return fmt.Sprintf("账号 %s ", channelName)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// defaultFormFieldOrder 可选表单字段的默认执行顺序（名称与步骤名称相同）
+var defaultFormFieldOrder = []string{
+	StepLocation,
+	StepCollection,
+	StepLink,
+	StepActivity,
+	StepSchedule,
+	StepShortTitle,
+	StepMusic,
+	StepCover,
+}
+
+// 可选字段填写失败时的处理方式
+const (
+	FieldPolicyFail = "fail" // 任务失败，按失败分类进入重试
+	FieldPolicyWarn = "warn" // 只记录警告，任务继续
+)
+
+// policyFields 可以设置失败处理方式的字段，短标题、定时发表、封面裁剪失败时任务总是失败
+var policyFields = []string{StepLocation, StepCollection, StepLink, StepActivity, StepMusic}
+
+// FormFieldsConfig 可选表单字段的执行顺序、不执行的字段和失败时的处理方式（配置文件 form_fields）：
+// 部分账号类型的上传页面没有活动、链接等控件，跳过后不再每个任务等待选择器超时
+type FormFieldsConfig struct {
+	Order  []string          `yaml:"order"`  // 执行顺序，未列出的字段按默认顺序排在后面
+	Skip   []string          `yaml:"skip"`   // 不执行的字段，任务中填写了也忽略
+	Policy map[string]string `yaml:"policy"` // 字段 -> fail | warn，未配置的字段按 -strict 决定
+}
+
+// Validate 检查字段名称和失败处理方式，定时发表影响最终操作，不能跳过
+func (c FormFieldsConfig) Validate() error {
+	for _, name := range append(slices.Clone(c.Order), c.Skip...) {
+		if !slices.Contains(defaultFormFieldOrder, name) {
+			return fmt.Errorf("未知的表单字段 %s，可选: %v", name, defaultFormFieldOrder)
+		}
+	}
+	if slices.Contains(c.Skip, StepSchedule) {
+		return fmt.Errorf("定时发表（%s）不能跳过", StepSchedule)
+	}
+	for name, policy := range c.Policy {
+		if !slices.Contains(policyFields, name) {
+			return fmt.Errorf("policy 中的字段 %s 不能设置失败处理方式，可选: %v", name, policyFields)
+		}
+		if policy != FieldPolicyFail && policy != FieldPolicyWarn {
+			return fmt.Errorf("policy.%s 只能为 fail 或 warn: %s", name, policy)
+		}
+	}
+	return nil
+}
+
+// order 可选表单字段的执行顺序
+func (c FormFieldsConfig) order() []string {
+	order := make([]string, 0, len(defaultFormFieldOrder))
+	for _, name := range append(slices.Clone(c.Order), defaultFormFieldOrder...) {
+		if !slices.Contains(order, name) {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
+// required 字段填写失败时任务是否失败：按字段配置的处理方式，未配置时为 fallback（严格模式）
+func (c FormFieldsConfig) required(name string, fallback bool) bool {
+	switch c.Policy[name] {
+	case FieldPolicyFail:
+		return true
+	case FieldPolicyWarn:
+		return false
+	}
+	return fallback
+}
+
+// skipped 字段是否配置为不执行
+func (c FormFieldsConfig) skipped(name string) bool {
+	return slices.Contains(c.Skip, name)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// formStepFunc 自定义表单步骤：用任务表中对应列的值操作上传页面
+type formStepFunc func(page playwright.Page, value string) error
+
+// formStep 按列注册的自定义表单步骤，用于内置字段尚未覆盖的平台功能（如账号特有的功能）
+type formStep struct {
+	Column   string // 任务表的列名，JSON 任务为 extra 中的键
+	Required bool   // true 时失败则任务失败，否则只记录警告（与位置、合集等可选字段一致）
+	Run      formStepFunc
+}
+
+// FormStepConfig 配置文件中以 JS 片段定义的表单步骤
+type FormStepConfig struct {
+	Column     string `yaml:"column"`
+	Script     string `yaml:"script"`      // JS 函数，以单元格的值调用，例: (value) => document.querySelector('#x').click()
+	ScriptFile string `yaml:"script_file"` // 从文件读取 JS 函数，与 script 二选一，相对路径相对于配置文件所在目录
+	Required   bool   `yaml:"required"`
+}
+
+var (
+	formStepsMu sync.RWMutex
+	formSteps   []formStep
+)
+
+// registerFormStep 注册自定义表单步骤，在内置字段之后、最终操作之前按注册顺序执行，
+// 任务中该列为空时跳过；同一列重复注册时替换之前的步骤
+func registerFormStep(step formStep) {
+	formStepsMu.Lock()
+	defer formStepsMu.Unlock()
+	for i := range formSteps {
+		if formSteps[i].Column == step.Column {
+			formSteps[i] = step
+			return
+		}
+	}
+	formSteps = append(formSteps, step)
+}
+
+// RegisterScriptFormSteps 注册配置文件中的 JS 表单步骤，脚本通过 page.Evaluate 在上传页面中执行
+func RegisterScriptFormSteps(configs []FormStepConfig) error {
+	for _, config := range configs {
+		if config.Column == "" {
+			return fmt.Errorf("自定义表单步骤缺少 column")
+		}
+		script := config.Script
+		if config.ScriptFile != "" {
+			data, err := os.ReadFile(config.ScriptFile)
+			if err != nil {
+				return fmt.Errorf("读取自定义表单步骤 %s 的脚本失败: %v", config.Column, err)
+			}
+			script = string(data)
+		}
+		if strings.TrimSpace(script) == "" {
+			return fmt.Errorf("自定义表单步骤 %s 缺少 script 或 script_file", config.Column)
+		}
+
+		registerFormStep(formStep{
+			Column:   config.Column,
+			Required: config.Required,
+			Run: func(page playwright.Page, value string) error {
+				if _, err := page.Evaluate(script, value); err != nil {
+					return fmt.Errorf("执行脚本失败: %v", err)
+				}
+				return nil
+			},
+		})
+	}
+	return nil
+}
+
+// runFormSteps 执行已注册的自定义表单步骤，步骤名称为 custom:<列名>
+func runFormSteps(page playwright.Page, extra map[string]string, onStep StepFunc) error {
+	formStepsMu.RLock()
+	steps := append([]formStep(nil), formSteps...)
+	formStepsMu.RUnlock()
+
+	for _, step := range steps {
+		value := extra[step.Column]
+		if value == "" {
+			continue
+		}
+		log.Printf("🧩 自定义步骤 %s: %s", step.Column, value)
+		err := runStep(onStep, "custom:"+step.Column, func() error { return step.Run(page, value) })
+		if err != nil && step.Required {
+			return fmt.Errorf("自定义步骤 %s 失败: %v", step.Column, err)
+		}
+		if err != nil {
+			log.Printf("⚠️ 自定义步骤 %s 失败: %v", step.Column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+
+	"wechat-uploader/uploader"
+)
+
+// Hooks -events 时把任务开始、步骤完成、任务结束和需要扫码登录的事件以 JSON Lines 写入标准输出，
+// 嵌入本程序的调用方通过 uploader 包的 Run 接收并调用自己的回调；为 nil 时不输出。
+// 并发模式下多个任务同时写入，每个事件为完整的一行
+type Hooks struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// newHooks 创建写入 w 的事件输出
+func newHooks(w io.Writer) *Hooks {
+	return &Hooks{enc: json.NewEncoder(w)}
+}
+
+// emit 写入一个事件，失败时只记录日志
+func (h *Hooks) emit(event uploader.Event) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.enc.Encode(event); err != nil {
+		log.Printf("⚠️ 写入事件失败: %v", err)
+	}
+}
+
+// taskStarted 任务开始执行
+func (h *Hooks) taskStarted(task VideoCreateTask) {
+	h.emit(uploader.Event{Type: uploader.EventTaskStart, Task: hookTask(task)})
+}
+
+// progress 任务的一个步骤执行完成
+func (h *Hooks) progress(task VideoCreateTask, step StepTiming) {
+	h.emit(uploader.Event{Type: uploader.EventProgress, Task: hookTask(task),
+		Step: &uploader.Step{Name: step.Name, Duration: step.Duration, Error: step.Error}})
+}
+
+// taskCompleted 任务结束，包括未能开始就被标记为失败的任务
+func (h *Hooks) taskCompleted(result TaskResult) {
+	h.emit(uploader.Event{Type: uploader.EventTaskComplete, Result: &uploader.Result{
+		Task:          *hookTask(result.Task),
+		Success:       result.Success,
+		Error:         result.Error,
+		ErrorCategory: result.ErrorCategory,
+		FailedStep:    result.FailedStep,
+		PublishedURL:  result.PublishedURL,
+		Duration:      result.Duration,
+	}})
+}
+
+// loginRequired 在已有的二维码处理函数（如扫码页面）之后把每次的二维码截图作为 login_required 事件输出
+func (h *Hooks) loginRequired(onQRCode QRCodeHandler) QRCodeHandler {
+	if h == nil {
+		return onQRCode
+	}
+	return combineQRHandlers(onQRCode, func(png []byte) {
+		h.emit(uploader.Event{Type: uploader.EventLoginRequired, QRCode: png})
+	})
+}
+
+// hookTask 事件中的任务字段
+func hookTask(task VideoCreateTask) *uploader.Task {
+	return &uploader.Task{
+		Row:         task.RowIndex,
+		Account:     task.Account,
+		Description: task.Description,
+		VideoPath:   task.VideoPath,
+		Action:      task.Action,
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+const (
+	// maxPostImages 图文动态最多可上传的图片数
+	maxPostImages = 18
+	// imageUploadTimeout 单张图片上传完成的等待时间
+	imageUploadTimeout = 60 * time.Second
+)
+
+// imageExtensions 图文动态支持的图片格式
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+}
+
+// imageThumbnailSelectors 已上传图片的缩略图，用于判断每张图片是否上传完成
+var imageThumbnailSelectors = []string{
+	".image-list .image-item",
+	"[class*='image-item']",
+	"[class*='img-item']",
+	".ant-upload-list-item",
+}
+
+// taskMediaPaths 任务要上传的文件：图文动态为各图片，否则为视频
+func taskMediaPaths(task VideoCreateTask) []string {
+	if task.ContentType == ContentTypeImage {
+		return task.ImagePaths
+	}
+	return []string{task.VideoPath}
+}
+
+// splitMediaPaths 拆分媒体列，多个文件用 ; 、| 或换行分隔
+func splitMediaPaths(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == '；' || r == '|' || r == '\n'
+	})
+	var paths []string
+	for _, field := range fields {
+		if path := strings.TrimSpace(field); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// isImagePath 按扩展名判断是否为图片
+func isImagePath(path string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// resolveMedia 根据内容类型和媒体列确定任务的图片：未指定内容类型且媒体列全是图片时按图文动态处理
+func resolveMedia(task *VideoCreateTask, explicitType bool) error {
+	paths := splitMediaPaths(task.VideoPath)
+	images := len(paths) > 0
+	for _, path := range paths {
+		if !isImagePath(path) {
+			images = false
+			break
+		}
+	}
+
+	if task.ContentType == ContentTypeImage || (!explicitType && images) {
+		if !images {
+			return fmt.Errorf("图文动态只支持 jpg、png、webp 图片: %s", task.VideoPath)
+		}
+		if len(paths) > maxPostImages {
+			return fmt.Errorf("图文动态最多 %d 张图片，实际为 %d 张", maxPostImages, len(paths))
+		}
+		task.ContentType = ContentTypeImage
+		task.ImagePaths = paths
+		return nil
+	}
+	if len(paths) > 1 {
+		return fmt.Errorf("视频位置只能有一个视频文件，多张图片请使用图文内容类型")
+	}
+	return nil
+}
+
+// uploadImages 按顺序逐张上传图片，每张上传完成后再上传下一张，保证图片顺序
+func uploadImages(page playwright.Page, paths []string) error {
+	log.Printf("=== 开始上传 %d 张图片 ===", len(paths))
+	for i, path := range paths {
+		log.Printf("🖼️ 上传第 %d/%d 张图片: %s", i+1, len(paths), filepath.Base(path))
+		input, err := findImageInput(page)
+		if err != nil {
+			return err
+		}
+		if err := input.SetInputFiles([]string{resolvePath(path)}); err != nil {
+			return fmt.Errorf("设置文件失败: %v", err)
+		}
+		if err := waitForImageCount(page, i+1); err != nil {
+			return fmt.Errorf("第 %d 张图片上传失败: %v", i+1, err)
+		}
+	}
+	log.Println("✅ 图片全部上传完成")
+	return nil
+}
+
+// findImageInput 查找图片上传的文件输入框
+func findImageInput(page playwright.Page) (playwright.Locator, error) {
+	selectors := []string{
+		"input[type='file'][accept*='image']",
+		".ant-upload input[type='file']",
+		"input[type='file']",
+	}
+	for _, selector := range selectors {
+		locator := page.Locator(selector)
+		if count, _ := locator.Count(); count > 0 {
+			return locator.First(), nil
+		}
+	}
+	return nil, fmt.Errorf("未找到图片上传输入框")
+}
+
+// waitForImageCount 等待已上传图片的缩略图数量达到 expected
+func waitForImageCount(page playwright.Page, expected int) error {
+	deadline := time.Now().Add(imageUploadTimeout)
+	for time.Now().Before(deadline) {
+		if message, found := pageErrorMessage(page, uploadErrorSelectors); found {
+			return platformError("上传过程中出现错误", message)
+		}
+		for _, selector := range imageThumbnailSelectors {
+			if count, _ := page.Locator(selector).Count(); count >= expected {
+				return nil
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("等待图片上传完成超时")
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"golang.org/x/net/websocket"
+)
+
+// liveViewPage 单个页面的实时查看，通过 WebSocket 接收页面画面，可操作时把鼠标和键盘操作发回浏览器
+const liveViewPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s - 页面实时查看</title>
+</head>
+<body style="text-align:center;font-family:sans-serif">
+<h3>%s</h3>
+<img id="screen" tabindex="0" alt="画面加载中..." style="max-width:95%%;outline:none;user-select:none" draggable="false">
+<p id="status">连接中...</p>
+<script>
+const interactive = %t;
+const screenImage = document.getElementById("screen");
+const statusText = document.getElementById("status");
+const ws = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/ws?id=" + encodeURIComponent(%q) + "&token=" + encodeURIComponent(%q));
+let metadata = {deviceWidth: 1, deviceHeight: 1};
+ws.onmessage = (e) => {
+  const frame = JSON.parse(e.data);
+  metadata = frame.metadata;
+  screenImage.src = "data:image/jpeg;base64," + frame.data;
+  statusText.textContent = (interactive ? "可操作" : "只读") + " | 更新时间: " + new Date().toLocaleTimeString();
+};
+ws.onclose = () => { statusText.textContent = "连接已断开（任务已结束或页面已关闭）"; };
+function send(input) { if (ws.readyState === WebSocket.OPEN) ws.send(JSON.stringify(input)); }
+function point(type, e) {
+  const rect = screenImage.getBoundingClientRect();
+  return {type: type, x: (e.clientX - rect.left) / rect.width * metadata.deviceWidth, y: (e.clientY - rect.top) / rect.height * metadata.deviceHeight};
+}
+if (interactive) {
+  screenImage.style.cursor = "crosshair";
+  screenImage.addEventListener("mousedown", (e) => { e.preventDefault(); screenImage.focus(); send(point("down", e)); });
+  screenImage.addEventListener("mousemove", (e) => { if (e.buttons) send(point("move", e)); });
+  screenImage.addEventListener("mouseup", (e) => send(point("up", e)));
+  screenImage.addEventListener("keydown", (e) => {
+    e.preventDefault();
+    send(e.key.length === 1 ? {type: "text", text: e.key} : {type: "key", key: e.key});
+  });
+}
+</script>
+</body>
+</html>`
+
+// liveViewFrameBuffer 待发送画面的缓冲数量，浏览器收到确认后才发送下一帧，正常不会积压
+const liveViewFrameBuffer = 8
+
+// liveViewTokenBytes 访问令牌的随机字节数
+const liveViewTokenBytes = 16
+
+// LiveViewOptions 页面实时查看服务的设置
+type LiveViewOptions struct {
+	Addr        string // 监听地址，为空时不启动，只有端口（如 :8081）时只监听 127.0.0.1
+	Interactive bool   // 是否允许通过查看页面操作浏览器（点击、拖动、输入）
+
+	server *LiveViewServer
+}
+
+// LiveViewServer 通过 WebSocket 推送浏览器页面的画面，供服务器模式下查看无头浏览器的页面并在卡住时介入
+type LiveViewServer struct {
+	mu          sync.RWMutex
+	pages       map[string]playwright.Page
+	interactive bool
+	token       string // 访问令牌，每次启动随机生成，所有地址都需要带上 token 参数
+	server      *http.Server
+}
+
+// NewLiveViewServer 创建页面查看HTTP服务，生成访问令牌
+func NewLiveViewServer(options LiveViewOptions) (*LiveViewServer, error) {
+	token := make([]byte, liveViewTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("生成页面查看服务的访问令牌失败: %v", err)
+	}
+	s := &LiveViewServer{pages: make(map[string]playwright.Page), interactive: options.Interactive, token: hex.EncodeToString(token)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.authorized(s.handleIndex))
+	mux.HandleFunc("/view", s.authorized(s.handleView))
+	mux.HandleFunc("/screenshot.png", s.authorized(s.handleScreenshot))
+	mux.HandleFunc("/ws", s.authorized(websocket.Server{Handler: s.streamPage, Handshake: checkSameOrigin}.ServeHTTP))
+	s.server = &http.Server{Addr: liveViewListenAddr(options.Addr), Handler: mux}
+	return s, nil
+}
+
+// liveViewListenAddr 只指定端口时只监听本机，需要从其他机器访问时明确指定 0.0.0.0:端口
+func liveViewListenAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "127.0.0.1" + addr
+	}
+	return addr
+}
+
+// authorized 校验请求中的访问令牌，不正确时返回 403
+func (s *LiveViewServer) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(s.token)) != 1 {
+			http.Error(w, "访问令牌不正确", http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// checkSameOrigin WebSocket 只接受来自查看页面本身的连接（Origin 与 Host 一致），防止其他网页借用浏览器发起连接
+func checkSameOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil || origin.Host != r.Host {
+		return fmt.Errorf("WebSocket 来源 %v 与页面查看服务 %s 不一致", origin, r.Host)
+	}
+	config.Origin = origin
+	return nil
+}
+
+// Start 后台启动HTTP服务
+func (s *LiveViewServer) Start() {
+	go func() {
+		log.Printf("🖥️ 页面查看服务已启动: http://%s/?token=%s", s.displayAddr(), s.token)
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ 页面查看服务异常: %v", err)
+		}
+	}()
+}
+
+// Stop 关闭HTTP服务，为 nil 时不执行
+func (s *LiveViewServer) Stop() {
+	if s == nil {
+		return
+	}
+	if err := s.server.Close(); err != nil {
+		log.Printf("⚠️ 关闭页面查看服务失败: %v", err)
+	}
+}
+
+// Register 添加可查看的页面，为 nil 时不执行
+func (s *LiveViewServer) Register(id string, page playwright.Page) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[id] = page
+}
+
+// Unregister 移除页面，为 nil 时不执行
+func (s *LiveViewServer) Unregister(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pages, id)
+}
+
+// URL 返回页面的查看地址，为 nil 时返回空
+func (s *LiveViewServer) URL(id string) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("http://%s/view?id=%s&token=%s", s.displayAddr(), url.QueryEscape(id), s.token)
+}
+
+// displayAddr 查看地址中的主机和端口，监听所有网卡时显示主机名
+func (s *LiveViewServer) displayAddr() string {
+	return qrDisplayAddr(strings.TrimPrefix(s.server.Addr, "0.0.0.0"))
+}
+
+// liveViewID 任务页面在查看服务中的 id
+func liveViewID(result *TaskResult) string {
+	return fmt.Sprintf("row%d", result.Task.RowIndex)
+}
+
+// page 按 id 查找页面
+func (s *LiveViewServer) page(id string) (playwright.Page, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	page, ok := s.pages[id]
+	return page, ok
+}
+
+func (s *LiveViewServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.pages))
+	for id := range s.pages {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	sort.Strings(ids)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<!DOCTYPE html><html><head><meta charset="utf-8"><meta http-equiv="refresh" content="5"><title>页面实时查看</title></head><body style="font-family:sans-serif"><h3>页面实时查看</h3>`)
+	if len(ids) == 0 {
+		fmt.Fprint(w, "<p>当前没有正在执行的任务</p>")
+	}
+	for _, id := range ids {
+		fmt.Fprintf(w, `<p><a href="/view?id=%s&amp;token=%s">%s</a></p>`, url.QueryEscape(id), s.token, html.EscapeString(id))
+	}
+	fmt.Fprint(w, "</body></html>")
+}
+
+func (s *LiveViewServer) handleView(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if _, ok := s.page(id); !ok {
+		http.Error(w, "页面不存在或任务已结束", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	title := html.EscapeString(id)
+	fmt.Fprintf(w, liveViewPage, title, title, s.interactive, id, s.token)
+}
+
+func (s *LiveViewServer) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	page, ok := s.page(r.URL.Query().Get("id"))
+	if !ok || page.IsClosed() {
+		http.Error(w, "页面不存在或已关闭", http.StatusNotFound)
+		return
+	}
+	png, err := page.Screenshot()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("截图失败: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+// streamPage 通过 CDP 录屏把页面画面推送到 WebSocket，可操作时接收鼠标和键盘操作
+func (s *LiveViewServer) streamPage(ws *websocket.Conn) {
+	defer ws.Close()
+	page, ok := s.page(ws.Request().URL.Query().Get("id"))
+	if !ok || page.IsClosed() {
+		return
+	}
+	session, err := page.Context().NewCDPSession(page)
+	if err != nil {
+		log.Printf("⚠️ 页面查看连接浏览器失败: %v", err)
+		return
+	}
+	defer session.Detach()
+
+	// 事件回调中不能等待 CDP 调用的结果，画面交给下面的循环发送和确认
+	frames := make(chan map[string]interface{}, liveViewFrameBuffer)
+	session.On("Page.screencastFrame", func(params map[string]interface{}) {
+		select {
+		case frames <- params:
+		default:
+		}
+	})
+	if _, err := session.Send("Page.startScreencast", map[string]interface{}{
+		"format":    "jpeg",
+		"quality":   60,
+		"maxWidth":  1920,
+		"maxHeight": 1080,
+	}); err != nil {
+		log.Printf("⚠️ 页面查看开始录屏失败: %v", err)
+		return
+	}
+	defer session.Send("Page.stopScreencast", nil)
+
+	closed := make(chan struct{})
+	go s.receiveInput(ws, page, closed)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			if page.IsClosed() {
+				return
+			}
+		case frame := <-frames:
+			if _, err := session.Send("Page.screencastFrameAck", map[string]interface{}{"sessionId": frame["sessionId"]}); err != nil {
+				return
+			}
+			delete(frame, "sessionId")
+			if err := websocket.JSON.Send(ws, frame); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// liveViewInput 查看页面发回的鼠标或键盘操作，坐标为页面 CSS 像素
+type liveViewInput struct {
+	Type string  `json:"type"` // down | move | up | key | text
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Key  string  `json:"key"`
+	Text string  `json:"text"`
+}
+
+// receiveInput 接收查看页面的操作，只读时忽略；连接断开后关闭 closed
+func (s *LiveViewServer) receiveInput(ws *websocket.Conn, page playwright.Page, closed chan struct{}) {
+	defer close(closed)
+	for {
+		var input liveViewInput
+		if err := websocket.JSON.Receive(ws, &input); err != nil {
+			return
+		}
+		if !s.interactive {
+			continue
+		}
+		if err := input.apply(page); err != nil {
+			log.Printf("⚠️ 页面查看操作失败: %v", err)
+		}
+	}
+}
+
+// apply 在页面上执行操作，按下和松开前先移动到对应位置以支持拖动滑块
+func (input liveViewInput) apply(page playwright.Page) error {
+	switch input.Type {
+	case "down":
+		if err := page.Mouse().Move(input.X, input.Y); err != nil {
+			return err
+		}
+		return page.Mouse().Down()
+	case "move":
+		return page.Mouse().Move(input.X, input.Y, playwright.MouseMoveOptions{Steps: playwright.Int(2)})
+	case "up":
+		if err := page.Mouse().Move(input.X, input.Y); err != nil {
+			return err
+		}
+		return page.Mouse().Up()
+	case "key":
+		return page.Keyboard().Press(input.Key)
+	case "text":
+		return page.Keyboard().InsertText(input.Text)
+	}
+	return fmt.Errorf("不支持的操作: %s", input.Type)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// locationHidden 不显示位置
+const locationHidden = "不显示位置"
+
+// LocationConfig 位置设置：任务未填写位置时的默认值，以及选择后校验不一致时的处理方式
+type LocationConfig struct {
+	Default string `yaml:"default"` // 位置列为空时使用，例如 不显示位置；为空时不操作位置
+	Strict  bool   `yaml:"strict"`  // true 时选择失败或显示的位置与期望不一致则任务失败，否则只记录警告
+}
+
+// locationDisplayChain 位置选择器上显示最终位置的元素
+var locationDisplayChain = SelectorChain{Name: "location_display", Selectors: []string{
+	".post-position-wrap .position-display .text",
+	".post-position-wrap .position-display",
+}}
+
+// displayedLocation 读取位置选择器上最终显示的位置
+func displayedLocation(page playwright.Page) (string, error) {
+	text, err := locationDisplayChain.Text(page)
+	if err != nil {
+		return "", fmt.Errorf("未找到位置显示元素")
+	}
+	return strings.Join(strings.Fields(text), " "), nil
+}
+
+// verifyLocation 校验最终显示的位置与期望一致，返回显示的位置；
+// 搜索结果的名称通常比填写的更完整，互相包含即视为一致
+func verifyLocation(page playwright.Page, expected string) (string, error) {
+	displayed, err := displayedLocation(page)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(displayed, expected) || (displayed != "" && strings.Contains(expected, displayed)) {
+		return displayed, nil
+	}
+	return displayed, fmt.Errorf("位置校验失败: 期望 %s，实际显示 %s", expected, displayed)
+}
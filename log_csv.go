@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// csvLogColumns CSV 日志的列
+var csvLogColumns = []string{"timestamp", "row", "account", "step", "level", "message"}
+
+// csvLog 与日志文件并行写入的 CSV 日志，每条日志一行，可在 Excel 中按行号、账号、步骤、级别筛选
+type csvLog struct {
+	w io.Writer
+}
+
+// activeCSVLog 当前运行的 CSV 日志，为 nil 时不写入
+var activeCSVLog atomic.Pointer[csvLog]
+
+// createCSVLog 在日志文件旁创建同名的 .csv 文件，写入 BOM（Excel 按 UTF-8 打开）和表头；
+// appendMode 时追加写入，文件已有内容时不再写表头
+func createCSVLog(logPath string, appendMode bool) (*os.File, error) {
+	path := strings.TrimSuffix(logPath, ".log") + ".csv"
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("创建CSV日志失败: %v", err)
+	}
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		return file, nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	writer := csv.NewWriter(&buf)
+	writer.Write(csvLogColumns)
+	writer.Flush()
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("创建CSV日志失败: %v", err)
+	}
+	return file, nil
+}
+
+// startCSVLog 之后的日志同时写入 w（经日志管道，与其他日志按顺序写入），返回停止写入的函数
+func startCSVLog(w io.Writer) func() {
+	c := &csvLog{w: logs.Writer(w)}
+	activeCSVLog.Store(c)
+	return func() { activeCSVLog.CompareAndSwap(c, nil) }
+}
+
+// write 写入一条日志，result 为日志所属的任务（不在任务中时为 nil）
+func (c *csvLog) write(r slog.Record, result *TaskResult) {
+	var row, account, step string
+	if result != nil {
+		row = strconv.Itoa(result.Task.RowIndex)
+		account = result.ChannelName
+		step = result.step
+	}
+	level, message := csvLogLevel(r)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{r.Time.Format("2006-01-02 15:04:05.000"), row, account, step, level, message})
+	writer.Flush()
+	// 一次写入一整行，避免与并发任务的日志交错
+	c.w.Write(buf.Bytes())
+}
+
+// csvLogLevel 日志级别和去掉开头表情符号的消息：❌ 为 ERROR，⚠️ 为 WARN，其余按 slog 的级别
+func csvLogLevel(r slog.Record) (string, string) {
+	message := strings.TrimSpace(r.Message)
+	level := r.Level.String()
+	switch {
+	case strings.HasPrefix(message, "❌"):
+		level = slog.LevelError.String()
+	case strings.HasPrefix(message, "⚠"):
+		level = slog.LevelWarn.String()
+	}
+	message = strings.TrimLeftFunc(message, func(r rune) bool {
+		return unicode.Is(unicode.So, r) || unicode.IsSpace(r) || r == '\ufe0f' || r == '\u200d'
+	})
+	return level, message
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// taskLogBackups 按大小轮转时保留的旧日志个数（<名称>.1.log 最新）
+const taskLogBackups = 5
+
+// latestLogName 指向当前任务日志的符号链接
+const latestLogName = "latest.log"
+
+// TaskLogOptions 任务日志文件（output-dir/log/）的写入方式：默认每次运行新建一个文件；
+// 日志采集程序只能跟踪固定路径时，可追加写入固定文件名的日志并按大小轮转，或通过 latest.log 读取当前日志
+type TaskLogOptions struct {
+	Name    string // 固定的日志名称（如账号名），不为空时追加写入 <名称>.log，为空时每次运行新建
+	MaxSize int64  // 追加写入时超过该字节数先轮转为 <名称>.1.log，0 表示不轮转
+	Latest  bool   // 创建 latest.log 符号链接指向当前日志
+}
+
+// logPath 本次运行的日志文件路径
+func (o TaskLogOptions) logPath(logDir string, runTime string) string {
+	if o.Name == "" {
+		return filepath.Join(logDir, fmt.Sprintf("wechat_channel_uploader_%s.log", runTime))
+	}
+	return filepath.Join(logDir, accountDirName(o.Name)+".log")
+}
+
+// openFile 打开日志文件：固定名称时追加写入，否则新建
+func (o TaskLogOptions) openFile(path string) (*os.File, error) {
+	if o.Name == "" {
+		return os.Create(path)
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// rotateLogFile 日志文件超过 maxSize 时依次改名为 .1、.2…（最多保留 taskLogBackups 个），
+// 同名的 .csv 日志一起轮转；只在打开前检查，运行中不轮转
+func rotateLogFile(path string, maxSize int64) error {
+	if maxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < maxSize {
+		return nil
+	}
+	base := strings.TrimSuffix(path, ".log")
+	for _, ext := range []string{".log", ".csv"} {
+		os.Remove(fmt.Sprintf("%s.%d%s", base, taskLogBackups, ext))
+		for i := taskLogBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d%s", base, i, ext), fmt.Sprintf("%s.%d%s", base, i+1, ext))
+		}
+		if err := os.Rename(base+ext, base+".1"+ext); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("轮转日志文件失败: %v", err)
+		}
+	}
+	log.Printf("🔄 日志文件超过 %d 字节，已轮转为 %s.1.log", maxSize, filepath.Base(base))
+	return nil
+}
+
+// linkLatestLog 在日志目录中创建 latest.log 符号链接指向 path（相对路径），已存在时替换；
+// Windows 上创建符号链接需要管理员权限或开发者模式，失败时只记录警告
+func linkLatestLog(path string) {
+	link := filepath.Join(filepath.Dir(path), latestLogName)
+	os.Remove(link)
+	if err := os.Symlink(filepath.Base(path), link); err != nil {
+		log.Printf("⚠️ 创建 %s 失败: %v", latestLogName, err)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// logPipelineBuffer 日志管道的缓冲条数，写满时写日志的 goroutine 等待
+const logPipelineBuffer = 1024
+
+// logs 当前运行的日志管道，由 setupLogging 创建
+var logs *LogPipeline
+
+// logEntry 一次写入；flushed 不为 nil 时为 Flush 的标记，写完之前的日志后关闭
+type logEntry struct {
+	w       io.Writer
+	data    []byte
+	flushed chan struct{}
+}
+
+// LogPipeline 由单个 goroutine 按提交顺序写入标准错误和日志文件，并发任务的日志不会交错或写坏文件；
+// 为 nil 或已关闭时直接写入
+type LogPipeline struct {
+	mu      sync.RWMutex
+	closed  bool
+	entries chan logEntry
+	done    chan struct{}
+}
+
+// NewLogPipeline 创建日志管道并启动写入 goroutine
+func NewLogPipeline() *LogPipeline {
+	p := &LogPipeline{entries: make(chan logEntry, logPipelineBuffer), done: make(chan struct{})}
+	go p.run()
+	return p
+}
+
+func (p *LogPipeline) run() {
+	defer close(p.done)
+	for entry := range p.entries {
+		if entry.flushed != nil {
+			if syncer, ok := entry.w.(interface{ Sync() error }); ok {
+				syncer.Sync()
+			}
+			close(entry.flushed)
+			continue
+		}
+		entry.w.Write(entry.data)
+	}
+}
+
+// Writer 返回经过管道写入 w 的 Writer，每次 Write 的内容作为一条完整的日志写入
+func (p *LogPipeline) Writer(w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	return pipelineWriter{pipeline: p, w: w}
+}
+
+// Flush 等待已提交的日志全部写入，w 为文件时同步到磁盘
+func (p *LogPipeline) Flush(w io.Writer) {
+	if p == nil {
+		return
+	}
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	p.entries <- logEntry{w: w, flushed: flushed}
+	p.mu.RUnlock()
+	<-flushed
+}
+
+// Close 写完缓冲中的日志后停止写入 goroutine，之后的日志直接写入（如退出前的 log.Fatal）
+func (p *LogPipeline) Close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.entries)
+	p.mu.Unlock()
+	<-p.done
+}
+
+// closeLogsOnSignal 收到 Ctrl+C 或 SIGTERM 时写完缓冲中的日志后退出，避免丢失中断前的日志；返回的函数停止监听
+func closeLogsOnSignal() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Printf("🛑 收到退出信号(%v)，写完日志后退出", sig)
+			logs.Close()
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// pipelineWriter 提交到日志管道的 Writer
+type pipelineWriter struct {
+	pipeline *LogPipeline
+	w        io.Writer
+}
+
+func (pw pipelineWriter) Write(data []byte) (int, error) {
+	p := pw.pipeline
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return pw.w.Write(data)
+	}
+	// 调用方可能复用 data，提交副本
+	p.entries <- logEntry{w: pw.w, data: append([]byte(nil), data...)}
+	return len(data), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 登录方式
+const (
+	LoginModeQR       = "qr"        // 打开浏览器扫码登录（默认）：本机有界面时显示浏览器，无界面时通过 PNG 文件或HTTP提供二维码
+	LoginModeAuthFile = "auth-file" // 读取 auth login 保存的认证状态文件，不打开浏览器
+	LoginModeCDP      = "cdp"       // 连接已打开的 Chrome（--remote-debugging-port），复用其中的登录状态，未登录时在该浏览器中扫码
+)
+
+// validateLoginOptions 校验登录方式及其所需参数
+func validateLoginOptions(options LoginOptions) error {
+	switch options.Mode {
+	case "", LoginModeQR:
+		return nil
+	case LoginModeAuthFile:
+		if options.AuthFile == "" {
+			return fmt.Errorf("-login=auth-file 需要指定 -auth-file")
+		}
+		return nil
+	case LoginModeCDP:
+		if options.CDPURL == "" {
+			return fmt.Errorf("-login=cdp 需要指定 -cdp-url(例如 http://127.0.0.1:9222)")
+		}
+		return nil
+	}
+	return fmt.Errorf("不支持的登录方式: %s(可选 qr、auth-file、cdp)", options.Mode)
+}
+
+// loadAuthFileLogin 从认证状态文件读取登录状态；运行中重新登录时重新读取，可由外部定时执行 auth login 刷新文件
+func loadAuthFileLogin(options LoginOptions) (*PageState, error) {
+	log.Printf("📁 从认证状态文件读取登录状态: %s", options.AuthFile)
+	authState, err := LoadAuthStateFile(options.AuthFile, options.Auth.Key)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ 已读取认证状态 (Cookies=%d个)", len(authState.Cookies))
+	return authState, nil
+}
+
+// cdpLogin 通过 CDP 连接已打开的浏览器，在其中打开创建页面，已登录时直接保存认证状态，否则等待在该浏览器中扫码；
+// 结束时只断开连接，不关闭用户的浏览器
+func cdpLogin(options LoginOptions) (*PageState, error) {
+	pw, err := playwright.Run()
+	if err != nil {
+		return nil, fmt.Errorf("启动Playwright失败: %v", err)
+	}
+	defer pw.Stop()
+
+	log.Printf("🔌 连接浏览器: %s", options.CDPURL)
+	browser, err := pw.Chromium.ConnectOverCDP(options.CDPURL)
+	if err != nil {
+		return nil, fmt.Errorf("连接浏览器失败: %v", err)
+	}
+	defer browser.Close()
+
+	contexts := browser.Contexts()
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("浏览器中没有可用的上下文")
+	}
+	context := contexts[0]
+
+	onQRCode, stop := loginQRHandler(options)
+	defer stop()
+	page, err := GenerateLoginPage(&context, onQRCode)
+	if err != nil {
+		return nil, err
+	}
+	defer (*page).Close()
+
+	pageState, err := SaveAuthState(*page, context)
+	if err != nil {
+		return nil, fmt.Errorf("保存认证状态失败: %v", err)
+	}
+	log.Println("✅ 已从浏览器读取认证状态")
+	return pageState, nil
+}
+
+// loginQRHandler 组合二维码的输出方式（HTTP、PNG 文件、调用方回调）并通知操作人员扫码，返回的函数用于停止HTTP服务
+func loginQRHandler(options LoginOptions) (QRCodeHandler, func()) {
+	var onQRCode QRCodeHandler
+	stop := func() {}
+
+	// 无界面环境通过HTTP提供二维码
+	if options.QRAddr != "" {
+		qrServer := NewQRCodeServer(options.QRAddr)
+		qrServer.Start()
+		stop = qrServer.Stop
+		onQRCode = qrServer.Update
+	}
+	// 二维码写入 PNG 文件，可通过共享目录或其他工具转发
+	if options.QRFile != "" {
+		writeFile := func(png []byte) {
+			if err := os.WriteFile(options.QRFile, png, 0644); err != nil {
+				log.Printf("⚠️ 写入二维码文件失败: %v", err)
+			}
+		}
+		onQRCode = combineQRHandlers(onQRCode, writeFile)
+	}
+
+	// 通知操作人员扫码
+	message := "请在10分钟内用微信扫码登录视频号"
+	if options.Account != "" {
+		message += " " + options.Account
+		log.Printf("📱 请用视频号 %s 的管理员微信扫码", options.Account)
+	}
+	if options.QRAddr != "" {
+		message += fmt.Sprintf("，扫码页面: http://%s/", qrDisplayAddr(options.QRAddr))
+	}
+	if options.QRFile != "" {
+		message += fmt.Sprintf("，二维码图片: %s", options.QRFile)
+	}
+	options.Notifier.Notify(NotifyEvent{Type: EventOnLoginRequired, Title: "需要扫码登录", Message: message})
+	return options.Hooks.loginRequired(onQRCode), stop
+}
+
+// combineQRHandlers 依次调用两个二维码回调，任一为空时返回另一个
+func combineQRHandlers(first QRCodeHandler, second QRCodeHandler) QRCodeHandler {
+	switch {
+	case first == nil:
+		return second
+	case second == nil:
+		return first
+	}
+	return func(png []byte) {
+		first(png)
+		second(png)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultVideoExtensions 视频位置默认允许的视频格式
+var defaultVideoExtensions = []string{".mp4", ".mov", ".m4v", ".webm", ".mkv", ".avi", ".flv", ".wmv", ".mpg", ".mpeg"}
+
+// defaultMinVideoKB 视频文件默认的最小大小(KB)，更小的多为占位文件或未写完的文件
+const defaultMinVideoKB = 1
+
+// mediaRules 本次运行校验视频位置的规则，启动时按配置文件 media 设置；解析任务时校验，浏览器启动前就能发现填错的行
+var mediaRules = newMediaValidator(MediaConfig{})
+
+// MediaConfig 视频位置的文件格式和最小大小（配置文件 media）
+type MediaConfig struct {
+	Extensions []string `yaml:"extensions"`  // 允许的视频扩展名，如 [mp4, mov]，为空时为 defaultVideoExtensions
+	MinSizeKB  int64    `yaml:"min_size_kb"` // 视频文件的最小大小(KB)，0 为默认的 1KB
+}
+
+// Validate 检查扩展名和最小大小
+func (c MediaConfig) Validate() error {
+	for _, ext := range c.Extensions {
+		if normalizeExtension(ext) == "." {
+			return fmt.Errorf("extensions 中有空的扩展名")
+		}
+		if isImagePath("x" + normalizeExtension(ext)) {
+			return fmt.Errorf("extensions 只用于视频，图片格式 %s 不需要配置", ext)
+		}
+	}
+	if c.MinSizeKB < 0 {
+		return fmt.Errorf("min_size_kb 不能为负数: %d", c.MinSizeKB)
+	}
+	return nil
+}
+
+// normalizeExtension 统一为小写、带点的扩展名
+func normalizeExtension(ext string) string {
+	return "." + strings.TrimPrefix(strings.ToLower(strings.TrimSpace(ext)), ".")
+}
+
+// mediaValidator 视频位置的规则：视频扩展名在 extensions 中，文件不小于 minSize 字节
+type mediaValidator struct {
+	extensions []string
+	minSize    int64
+}
+
+// newMediaValidator 按配置创建视频位置的规则
+func newMediaValidator(config MediaConfig) mediaValidator {
+	v := mediaValidator{minSize: config.MinSizeKB << 10}
+	for _, ext := range config.Extensions {
+		v.extensions = append(v.extensions, normalizeExtension(ext))
+	}
+	if len(v.extensions) == 0 {
+		v.extensions = defaultVideoExtensions
+	}
+	if v.minSize == 0 {
+		v.minSize = defaultMinVideoKB << 10
+	}
+	return v
+}
+
+// CheckExtension 检查视频的扩展名，图片由图文动态的规则检查；不需要文件已存在
+func (v mediaValidator) CheckExtension(path string) error {
+	if isImagePath(path) {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, allowed := range v.extensions {
+		if ext == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("不支持的视频格式 %q: %s，可选: %s（配置文件 media.extensions）",
+		ext, filepath.Base(path), strings.Join(v.extensions, " "))
+}
+
+// CheckSize 检查已存在的视频或图片文件的大小，0 字节的文件总是报错
+func (v mediaValidator) CheckSize(path string) error {
+	info, err := os.Stat(resolvePath(path))
+	if err != nil {
+		return nil
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("文件为空(0 字节): %s", path)
+	}
+	if !isImagePath(path) && info.Size() < v.minSize {
+		return fmt.Errorf("视频文件只有 %d 字节，小于 %dKB，可能是占位文件: %s", info.Size(), v.minSize>>10, path)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// musicColumn 音乐列的表头，放在J列之后，为空时不添加音乐
+const musicColumn = "音乐"
+
+// 音乐选择入口、搜索框、搜索结果和确认按钮
+var (
+	musicOpenChain = SelectorChain{Name: "music_open", Selectors: []string{
+		".post-music-wrap .music-display",
+		"[class*='music-wrap'] [class*='display']",
+		"text=添加音乐",
+		"text=选择音乐",
+	}}
+	musicSearchChain = SelectorChain{Name: "music_search", Selectors: []string{
+		"input[placeholder*='搜索音乐']",
+		"input[placeholder*='搜索歌曲']",
+		"[class*='music'] input[type='text']",
+	}}
+	musicResultChain = SelectorChain{Name: "music_result", Selectors: []string{
+		".music-list .music-item",
+		"[class*='music-item']",
+		"[class*='song-item']",
+	}}
+	musicConfirmChain = SelectorChain{Name: "music_confirm", Selectors: []string{
+		"button:has-text('使用')",
+		"button:has-text('确定')",
+	}}
+)
+
+// musicVerifiedSelectors 结果中表示平台认证（正版曲库）的标记
+var musicVerifiedSelectors = []string{
+	"[class*='verified']",
+	"[class*='official']",
+	"[class*='copyright']",
+	":text('正版')",
+}
+
+// selectMusic 打开音乐选择，按歌名搜索并选择第一个平台认证的结果
+func selectMusic(page playwright.Page, name string) error {
+	if err := clickFirstVisible(page, musicOpenChain); err != nil {
+		return fmt.Errorf("未找到音乐选择入口")
+	}
+	time.Sleep(1 * time.Second)
+
+	search, err := musicSearchChain.FindVisible(page)
+	if err != nil {
+		return fmt.Errorf("未找到音乐搜索框")
+	}
+	if err := search.Fill(name); err != nil {
+		return fmt.Errorf("填写音乐名称失败: %v", err)
+	}
+	if err := search.Press("Enter"); err != nil {
+		return fmt.Errorf("搜索音乐失败: %v", err)
+	}
+	time.Sleep(2 * time.Second)
+
+	item, err := firstVerifiedMusic(page)
+	if err != nil {
+		return err
+	}
+	title, _ := item.TextContent()
+	if err := item.Click(); err != nil {
+		return fmt.Errorf("选择音乐失败: %v", err)
+	}
+	// 部分版本需要再点击"使用"确认
+	clickFirstVisible(page, musicConfirmChain)
+	log.Printf("🎵 已选择音乐: %s", strings.Join(strings.Fields(title), " "))
+	time.Sleep(1 * time.Second)
+	return nil
+}
+
+// firstVerifiedMusic 返回搜索结果中第一个平台认证的音乐
+func firstVerifiedMusic(page playwright.Page) (playwright.Locator, error) {
+	items, err := musicResultChain.Find(page)
+	if err != nil {
+		return nil, fmt.Errorf("未找到音乐搜索结果")
+	}
+	count, _ := items.Count()
+	for i := 0; i < count; i++ {
+		item := items.Nth(i)
+		for _, verified := range musicVerifiedSelectors {
+			if n, _ := item.Locator(verified).Count(); n > 0 {
+				return item, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("搜索结果中未找到平台认证的音乐(共 %d 个结果)", count)
+}
+
+// clickFirstVisible 点击选择器链中第一个可见的元素
+func clickFirstVisible(page playwright.Page, chain SelectorChain) error {
+	locator, err := chain.FindVisible(page)
+	if err != nil {
+		return err
+	}
+	return locator.Click()
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 通知事件类型
+const (
+	EventOnFailure        = "on_failure"         // 任务失败
+	EventOnFinish         = "on_finish"          // 本次运行结束
+	EventOnLoginRequired  = "on_login_required"  // 需要扫码登录
+	EventOnCaptcha        = "on_captcha"         // 任务中出现安全验证，需要人工处理
+	EventOnRetryExhausted = "on_retry_exhausted" // 重试队列中的任务达到最大次数仍失败，不再重试
+	EventOnQuotaWarning   = "on_quota_warning"   // 账号的发表数量接近配额上限
+	EventOnAccountBreaker = "on_account_breaker" // 账号连续登录失效或出现安全验证，已熔断
+)
+
+// NotifyEvent 通知事件
+type NotifyEvent struct {
+	Type    string       `json:"type"`
+	Time    time.Time    `json:"time"`
+	Title   string       `json:"title"`
+	Message string       `json:"message"`
+	Summary *RunSummary  `json:"summary,omitempty"` // 运行结束时的汇总
+	Results []TaskResult `json:"results,omitempty"` // 相关任务结果
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Notify(event NotifyEvent) error
+}
+
+// NotifierConfig 单个通知渠道的配置，同一类型可配置多个
+type NotifierConfig struct {
+	Type      string   `yaml:"type"`      // console | file | webhook | email
+	Events    []string `yaml:"events"`    // 订阅的事件，为空时订阅全部
+	Campaigns []string `yaml:"campaigns"` // 只通知这些投放活动的任务（如按客户分别通知），为空时不过滤
+
+	// file
+	Path string `yaml:"path"`
+
+	// webhook
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+
+	// email
+	SMTPHost string   `yaml:"smtp_host"`
+	SMTPPort int      `yaml:"smtp_port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// filteredNotifier 按订阅事件过滤的通知渠道
+type filteredNotifier struct {
+	name      string
+	notifier  Notifier
+	events    map[string]bool
+	campaigns campaignFilter
+}
+
+// Notifications 同时发送到多个通知渠道，为 nil 时不发送
+type Notifications struct {
+	notifiers []filteredNotifier
+}
+
+// NewNotifications 根据配置创建通知渠道
+func NewNotifications(configs []NotifierConfig) (*Notifications, error) {
+	n := &Notifications{}
+	for i, config := range configs {
+		notifier, err := newNotifier(config)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个通知配置(%s)无效: %v", i+1, config.Type, err)
+		}
+
+		events := make(map[string]bool)
+		for _, event := range config.Events {
+			switch event {
+			case EventOnFailure, EventOnFinish, EventOnLoginRequired, EventOnCaptcha, EventOnRetryExhausted, EventOnQuotaWarning, EventOnAccountBreaker:
+				events[event] = true
+			default:
+				return nil, fmt.Errorf("第%d个通知配置(%s)的事件无效: %s", i+1, config.Type, event)
+			}
+		}
+		n.notifiers = append(n.notifiers, filteredNotifier{
+			name:      config.Type,
+			notifier:  notifier,
+			events:    events,
+			campaigns: parseCampaignFilter(strings.Join(config.Campaigns, ",")),
+		})
+	}
+	return n, nil
+}
+
+// newNotifier 按类型创建通知渠道
+func newNotifier(config NotifierConfig) (Notifier, error) {
+	switch config.Type {
+	case "console":
+		return consoleNotifier{}, nil
+	case "file":
+		if config.Path == "" {
+			return nil, fmt.Errorf("缺少 path")
+		}
+		return &fileNotifier{path: config.Path}, nil
+	case "webhook":
+		return newWebhookNotifier(config)
+	case "email":
+		return newEmailNotifier(config)
+	default:
+		return nil, fmt.Errorf("不支持的通知类型")
+	}
+}
+
+// Notify 发送事件到所有订阅了该事件的渠道，单个渠道失败不影响其他渠道
+func (n *Notifications) Notify(event NotifyEvent) {
+	if n == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, f := range n.notifiers {
+		if len(f.events) > 0 && !f.events[event.Type] {
+			continue
+		}
+		event, ok := event.forCampaigns(f.campaigns)
+		if !ok {
+			continue
+		}
+		if err := f.notifier.Notify(event); err != nil {
+			log.Printf("⚠️ 发送通知失败(%s): %v", f.name, err)
+		}
+	}
+}
+
+// consoleNotifier 输出到控制台
+type consoleNotifier struct{}
+
+func (consoleNotifier) Notify(event NotifyEvent) error {
+	log.Printf("🔔 [%s] %s\n%s", event.Type, event.Title, event.Message)
+	return nil
+}
+
+// fileNotifier 以 JSON Lines 格式追加到文件
+type fileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (f *fileNotifier) Notify(event NotifyEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("创建通知文件目录失败: %v", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开通知文件失败: %v", err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// failureEvent 任务失败通知
+func failureEvent(results ...TaskResult) NotifyEvent {
+	var lines []string
+	for _, result := range results {
+		line := fmt.Sprintf("第%d行 %s [%s]: %s", result.Task.RowIndex, filepath.Base(result.Task.VideoPath), result.ErrorCategory, result.Error)
+		if result.Task.Campaign != "" {
+			line = fmt.Sprintf("[%s] %s", result.Task.Campaign, line)
+		}
+		if result.Task.Note != "" {
+			line += fmt.Sprintf("（备注: %s）", result.Task.Note)
+		}
+		if result.Trace != "" {
+			line += "\n  步骤: " + result.Trace
+		}
+		lines = append(lines, line)
+	}
+	return NotifyEvent{
+		Type:    EventOnFailure,
+		Title:   fmt.Sprintf("%d 个视频上传失败", len(results)),
+		Message: strings.Join(lines, "\n"),
+		Results: results,
+	}
+}
+
+// finishEvent 运行结束通知，附带失败分类汇总
+func finishEvent(results []TaskResult) NotifyEvent {
+	summary := summarizeResults(results)
+	lines := summary.Lines()
+	if notes := noteLines(results); len(notes) > 0 {
+		lines = append(lines, "备注:")
+		lines = append(lines, notes...)
+	}
+	return NotifyEvent{
+		Type:    EventOnFinish,
+		Title:   fmt.Sprintf("视频上传完成: %d 成功, %d 失败", summary.Succeeded, summary.Failed),
+		Message: strings.Join(lines, "\n"),
+		Summary: &summary,
+		Results: results,
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// emailNotifier 通过 SMTP 发送邮件
+type emailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// newEmailNotifier 创建邮件通知，端口默认 25
+func newEmailNotifier(config NotifierConfig) (*emailNotifier, error) {
+	if config.SMTPHost == "" || config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("缺少 smtp_host、from 或 to")
+	}
+	port := config.SMTPPort
+	if port == 0 {
+		port = 25
+	}
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.SMTPHost)
+	}
+	return &emailNotifier{
+		addr: config.SMTPHost + ":" + strconv.Itoa(port),
+		auth: auth,
+		from: config.From,
+		to:   config.To,
+	}, nil
+}
+
+func (e *emailNotifier) Notify(event NotifyEvent) error {
+	var msg strings.Builder
+	msg.WriteString("From: " + e.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(e.to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", event.Title) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(event.Time.Format("2006-01-02 15:04:05") + "\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(event.Message, "\n", "\r\n") + "\r\n")
+
+	if err := smtp.SendMail(e.addr, e.auth, e.from, e.to, []byte(msg.String())); err != nil {
+		return fmt.Errorf("发送邮件失败: %v", err)
+	}
+	return nil
+}
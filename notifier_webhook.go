@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookNotifier 以 JSON POST 到指定地址
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// newWebhookNotifier 创建 webhook 通知
+func newWebhookNotifier(config NotifierConfig) (*webhookNotifier, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("缺少 url")
+	}
+	return &webhookNotifier{
+		url:     config.URL,
+		headers: config.Headers,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (w *webhookNotifier) Notify(event NotifyEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", withoutURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求失败: %v", withoutURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// noteColumn 备注列的表头，放在J列之后：内容作为操作备注带入结果和通知，写 skip 或 跳过 时不处理该行
+const noteColumn = "备注"
+
+// noteCommentColumns 读取单元格批注作为操作备注的列（如在保存方式上批注"法务确认后再发"）
+var noteCommentColumns = []string{"定时发表", "定时时间", "保存方式"}
+
+// isSkipNote 备注是否标记跳过该行：skip、跳过，或以其开头并用冒号、空格附上原因（如"跳过：等待法务确认"）
+func isSkipNote(note string) bool {
+	word := strings.FieldsFunc(strings.ToLower(note), func(r rune) bool {
+		return r == ':' || r == '：' || r == ' '
+	})
+	return len(word) > 0 && (word[0] == "skip" || word[0] == "跳过")
+}
+
+// joinNotes 合并多条备注，忽略空备注
+func joinNotes(notes ...string) string {
+	var parts []string
+	for _, note := range notes {
+		if note = strings.TrimSpace(note); note != "" {
+			parts = append(parts, note)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// readCellNotes 读取任务表中定时发表、定时时间、保存方式列的单元格批注，返回行号 -> 备注；
+// 只支持 .xlsx/.xlsm，按表头定位列，与任务表版本无关
+func readCellNotes(path string) (map[int]string, error) {
+	if format, err := sniffWorkbookFormat(path); err != nil || format != workbookFormatOOXML {
+		return nil, err
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开Excel文件失败: %v", err)
+	}
+	defer f.Close()
+
+	sheet := taskSheetName(f)
+	comments, err := f.GetComments(sheet)
+	if err != nil || len(comments) == 0 {
+		return nil, err
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	columns := make(map[int]string)
+	for i, header := range rows[0] {
+		for _, name := range noteCommentColumns {
+			if strings.TrimSpace(header) == name {
+				columns[i+1] = name
+			}
+		}
+	}
+
+	notes := make(map[int]string)
+	for _, comment := range comments {
+		column, row, err := excelize.CellNameToCoordinates(comment.Cell)
+		if err != nil || row < 2 || columns[column] == "" {
+			continue
+		}
+		if text := commentText(comment); text != "" {
+			notes[row] = joinNotes(notes[row], columns[column]+": "+text)
+		}
+	}
+	return notes, nil
+}
+
+// commentText 批注的文字，去掉 Excel 自动加在开头的"作者:"
+func commentText(comment excelize.Comment) string {
+	text := comment.Text
+	for _, run := range comment.Paragraph {
+		text += run.Text
+	}
+	text = strings.TrimSpace(text)
+	if comment.Author != "" {
+		text = strings.TrimSpace(strings.TrimPrefix(text, comment.Author+":"))
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// applyNotes 将单元格批注合并到任务的操作备注，并在日志中列出有备注的任务
+func (s *TaskSource) applyNotes(tasks []VideoCreateTask) {
+	for i := range tasks {
+		task := &tasks[i]
+		task.Note = joinNotes(task.Note, s.Notes[task.RowIndex])
+		if task.Note != "" {
+			log.Printf("📝 第%d行备注: %s", task.RowIndex, task.Note)
+		}
+	}
+}
+
+// noteLines 有操作备注的任务，用于结果统计和通知
+func noteLines(results []TaskResult) []string {
+	var lines []string
+	for _, result := range results {
+		if result.Task.Note != "" {
+			lines = append(lines, fmt.Sprintf("第%d行 %s: %s", result.Task.RowIndex, filepath.Base(result.Task.VideoPath), result.Task.Note))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// authDataTimeout 请求登录状态接口的超时时间（毫秒）
+const authDataTimeout = 10000
+
+// 判断页面状态时依次使用：页面地址、不随界面语言变化的页面元素、登录状态接口，都无法判断时才匹配页面文本，
+// 界面语言为英文的账号也能正确判断
+
+// loggedInChain 只在登录后出现的元素（上传控件、左侧菜单中的账号信息）
+var loggedInChain = SelectorChain{Name: "logged_in", Selectors: []string{
+	"input[type='file']",
+	".ant-upload",
+	".account-info .name",
+	"[class*='account-info']",
+}}
+
+// uploadPageTexts 上传页面上的文本，地址和元素都无法判断时使用
+var uploadPageTexts = []string{"上传视频", "保存草稿", "发表", "创作", "Upload", "Save Draft", "Publish"}
+
+// loggedInTexts 登录后页面上的文本，loginPageTexts 登录页面上的文本
+var (
+	loggedInTexts  = []string{"保存草稿", "发表", "上传视频", "Save Draft", "Publish", "Upload"}
+	loginPageTexts = []string{"扫码登录", "请使用微信扫码登录", "Scan QR Code", "Log In", "Login"}
+)
+
+// isLoginURL 地址是否为登录页（路径中包含 login）
+func isLoginURL(pageURL string) bool {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(u.Path), "login")
+}
+
+// isCorrectPage 检查是否在正确的页面
+func isCorrectPage(page playwright.Page) bool {
+	currentURL := page.URL()
+
+	// 1. 页面地址：配置的创建页面（含备用入口），或平台上路径包含 create 的页面
+	if isLoginURL(currentURL) {
+		log.Printf("❌ 页面跳转到了登录页: %s", currentURL)
+		return false
+	}
+	if platformURLs.IsCreatePage(currentURL) ||
+		(strings.Contains(currentURL, platformURLs.Host()) && strings.Contains(currentURL, "create")) {
+		return true
+	}
+
+	// 2. 上传页面的元素（配置文件 navigation.ready_selectors）
+	for _, selector := range navigationRules.readySelectors {
+		if count, _ := page.Locator(selector).Count(); count > 0 {
+			log.Printf("✅ 页面包含上传元素: %s", selector)
+			return true
+		}
+	}
+
+	// 3. 最后才匹配页面文本
+	if text, ok := pageContainsText(page, uploadPageTexts); ok {
+		log.Printf("✅ 页面包含上传文本: %s", text)
+		return true
+	}
+
+	log.Printf("❌ 不在正确的上传页面，当前URL: %s", currentURL)
+	return false
+}
+
+// isLoggedIn 检查是否已登录
+func isLoggedIn(page playwright.Page) bool {
+	// 1. 跳转到登录页说明登录已失效
+	if isLoginURL(page.URL()) {
+		log.Printf("❌ 页面跳转到了登录页: %s", page.URL())
+		return false
+	}
+
+	// 2. 登录后才有的元素、登录二维码
+	if _, err := loggedInChain.FindVisible(page); err == nil {
+		return true
+	}
+	if _, err := qrCodeChain.FindVisible(page); err == nil {
+		log.Println("❌ 页面上有登录二维码")
+		return false
+	}
+
+	// 3. 登录状态接口
+	if loggedIn, known := checkAuthData(page); known {
+		return loggedIn
+	}
+
+	// 4. 最后才匹配页面文本
+	if _, ok := pageContainsText(page, loggedInTexts); ok {
+		return true
+	}
+	if text, ok := pageContainsText(page, loginPageTexts); ok {
+		log.Printf("❌ 页面包含登录文本: %s", text)
+		return false
+	}
+
+	// 默认认为已登录（避免误判）
+	return true
+}
+
+// checkAuthData 请求登录状态接口（配置文件 pages.auth_data，使用页面的 cookie），按返回的错误码判断是否已登录；
+// 接口不可用、返回服务端错误或返回内容无法识别时 known 为 false
+func checkAuthData(page playwright.Page) (loggedIn bool, known bool) {
+	endpoint := platformURLs.AuthDataAPI()
+	if endpoint == "" {
+		return false, false
+	}
+	response, err := page.Request().Post(endpoint, playwright.APIRequestContextPostOptions{
+		Data:    map[string]interface{}{},
+		Timeout: playwright.Float(authDataTimeout),
+	})
+	if err != nil {
+		log.Printf("⚠️ 请求登录状态接口失败: %v", err)
+		return false, false
+	}
+	defer response.Dispose()
+
+	if status := response.Status(); status == 401 || status == 403 {
+		log.Printf("❌ 登录状态接口返回 HTTP %d", status)
+		return false, true
+	} else if status >= 400 {
+		return false, false
+	}
+	var body map[string]interface{}
+	if err := response.JSON(&body); err != nil {
+		return false, false
+	}
+	code, ok := body["errCode"].(float64)
+	if !ok {
+		return false, false
+	}
+	if code != 0 {
+		log.Printf("❌ 登录状态接口返回 errCode=%v", code)
+	}
+	return code == 0, true
+}
+
+// pageContainsText 页面文本中包含 texts 中的哪一个
+func pageContainsText(page playwright.Page, texts []string) (string, bool) {
+	bodyText, err := page.Locator("body").TextContent()
+	if err != nil {
+		return "", false
+	}
+	for _, text := range texts {
+		if strings.Contains(bodyText, text) {
+			return text, true
+		}
+	}
+	return "", false
+}
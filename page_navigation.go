@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+const (
+	// navigationAttempts 导航最多尝试次数
+	navigationAttempts = 4
+	// defaultNavigationTimeout 首次导航的默认超时时间，超时后下一次放宽
+	defaultNavigationTimeout = 60 * time.Second
+	// defaultNavigationMaxTimeout 放宽后的默认超时上限
+	defaultNavigationMaxTimeout = 120 * time.Second
+	// defaultPageReadyTimeout 等待上传页面出现上传相关元素的默认最长时间
+	defaultPageReadyTimeout = 60 * time.Second
+	// pageReadyInterval 检查上传页面是否就绪的间隔
+	pageReadyInterval = 2 * time.Second
+	// navigationBackoff 第一次重试前的等待时间，之后每次翻倍
+	navigationBackoff = 5 * time.Second
+	// navigationMaxBackoff 重试等待时间上限
+	navigationMaxBackoff = 60 * time.Second
+)
+
+// navigationWaitStates 导航完成的标志（配置文件 navigation.wait_until）
+var navigationWaitStates = map[string]*playwright.WaitUntilState{
+	"commit":           playwright.WaitUntilStateCommit,
+	"domcontentloaded": playwright.WaitUntilStateDomcontentloaded,
+	"load":             playwright.WaitUntilStateLoad,
+	"networkidle":      playwright.WaitUntilStateNetworkidle,
+}
+
+// defaultReadySelectors 上传页面就绪时出现的元素，找到任意一个即可
+var defaultReadySelectors = []string{
+	"input[type='file']",
+	".ant-upload",
+	"button:has-text('保存草稿')",
+	"button:has-text('发表')",
+	"text=上传视频",
+	"[class*='upload']",
+}
+
+// NavigationConfig 页面导航和等待上传页面就绪的方式（配置文件 navigation）：网络慢（如经 VPN）时放宽超时，
+// 网络快时缩短等待；为 0 或空时使用默认值
+type NavigationConfig struct {
+	WaitUntil      string        `yaml:"wait_until"`      // 导航完成的标志: commit | domcontentloaded | load | networkidle，默认 domcontentloaded（不等待所有资源）
+	Timeout        time.Duration `yaml:"timeout"`         // 首次导航的超时时间，默认 60s，超时后下一次放宽
+	MaxTimeout     time.Duration `yaml:"max_timeout"`     // 放宽后的超时上限，默认 120s，小于 timeout 时按 timeout
+	ReadySelectors []string      `yaml:"ready_selectors"` // 判断上传页面就绪的元素，找到任意一个即可，默认为内置的上传控件和按钮
+	ReadyTimeout   time.Duration `yaml:"ready_timeout"`   // 等待上传页面就绪的最长时间，默认 60s（每 2 秒检查一次）
+}
+
+// Validate 检查导航配置
+func (c NavigationConfig) Validate() error {
+	if _, ok := navigationWaitStates[c.WaitUntil]; c.WaitUntil != "" && !ok {
+		return fmt.Errorf("wait_until 不支持 %s，可选: commit、domcontentloaded、load、networkidle", c.WaitUntil)
+	}
+	if c.Timeout < 0 || c.MaxTimeout < 0 || c.ReadyTimeout < 0 {
+		return fmt.Errorf("timeout、max_timeout、ready_timeout 不能为负数")
+	}
+	for _, selector := range c.ReadySelectors {
+		if strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("ready_selectors 中不能有空的选择器")
+		}
+	}
+	return nil
+}
+
+// navigationSettings 本次运行的导航等待方式
+type navigationSettings struct {
+	waitUntil      *playwright.WaitUntilState
+	timeout        time.Duration
+	maxTimeout     time.Duration
+	readySelectors []string
+	readyTimeout   time.Duration
+}
+
+// navigationRules 本次运行的导航等待方式，启动时按配置文件 navigation 设置
+var navigationRules = newNavigationSettings(NavigationConfig{})
+
+// newNavigationSettings 按配置确定导航等待方式，未配置的项使用默认值
+func newNavigationSettings(config NavigationConfig) navigationSettings {
+	s := navigationSettings{
+		waitUntil:      playwright.WaitUntilStateDomcontentloaded,
+		timeout:        defaultNavigationTimeout,
+		maxTimeout:     defaultNavigationMaxTimeout,
+		readySelectors: defaultReadySelectors,
+		readyTimeout:   defaultPageReadyTimeout,
+	}
+	if state, ok := navigationWaitStates[config.WaitUntil]; ok {
+		s.waitUntil = state
+	}
+	if config.Timeout > 0 {
+		s.timeout = config.Timeout
+	}
+	if config.MaxTimeout > 0 {
+		s.maxTimeout = config.MaxTimeout
+	}
+	s.maxTimeout = max(s.maxTimeout, s.timeout)
+	if len(config.ReadySelectors) > 0 {
+		s.readySelectors = config.ReadySelectors
+	}
+	if config.ReadyTimeout > 0 {
+		s.readyTimeout = config.ReadyTimeout
+	}
+	return s
+}
+
+// readyChecks 等待上传页面就绪时最多检查的次数
+func (s navigationSettings) readyChecks() int {
+	return max(int(s.readyTimeout/pageReadyInterval), 1)
+}
+
+// 导航失败类型
+const (
+	navigationErrorTimeout = "timeout" // 页面加载超时，多为网络慢
+	navigationErrorDNS     = "dns"     // 域名解析失败
+	navigationErrorNetwork = "network" // 连接被拒绝、重置、断网等
+	navigationErrorHTTP    = "http"    // 服务器返回错误状态码
+	navigationErrorOther   = "other"
+)
+
+// navigationError 单次导航失败的原因
+type navigationError struct {
+	kind   string
+	status int // HTTP 状态码，仅 kind 为 http 时有效
+	err    error
+}
+
+func (e *navigationError) Error() string {
+	return fmt.Sprintf("[%s] %v", e.kind, e.err)
+}
+
+// retryable 是否值得重试：HTTP 4xx（429 除外）重试也不会成功
+func (e *navigationError) retryable() bool {
+	if e.kind == navigationErrorHTTP {
+		return e.status >= 500 || e.status == 429
+	}
+	return true
+}
+
+// navigateWithRetry 导航到 url，失败时按指数退避重试；
+// 超时时放宽下一次的超时时间，DNS 和网络错误提示检查网络，HTTP 4xx 直接失败。
+// 最终错误包含每一次尝试的失败原因
+func navigateWithRetry(page playwright.Page, url string) error {
+	timeout := navigationRules.timeout
+	var causes []string
+	for attempt := 1; attempt <= navigationAttempts; attempt++ {
+		log.Printf("🌐 导航尝试 %d/%d: %s", attempt, navigationAttempts, url)
+		response, err := page.Goto(url, playwright.PageGotoOptions{
+			Timeout:   playwright.Float(float64(timeout.Milliseconds())),
+			WaitUntil: navigationRules.waitUntil,
+		})
+		navErr := checkNavigation(response, err)
+		if navErr == nil {
+			log.Println("✅ 页面导航成功")
+			return nil
+		}
+
+		causes = append(causes, fmt.Sprintf("第%d次%v", attempt, navErr))
+		log.Printf("⚠️ 导航失败 (尝试 %d/%d): %v", attempt, navigationAttempts, navErr)
+		if !navErr.retryable() || attempt == navigationAttempts {
+			break
+		}
+
+		switch navErr.kind {
+		case navigationErrorTimeout:
+			timeout = min(timeout*3/2, navigationRules.maxTimeout)
+			log.Printf("🐢 页面加载超时，下一次超时时间放宽到 %v", timeout)
+		case navigationErrorDNS, navigationErrorNetwork:
+			log.Println("📡 网络连接异常，请检查网络或 DNS 设置")
+		}
+		wait := navigationBackoffFor(attempt)
+		log.Printf("⏳ 等待 %v 后重试...", wait.Round(100*time.Millisecond))
+		time.Sleep(wait)
+	}
+	return fmt.Errorf("导航到 %s 失败(共%d次): %s", url, len(causes), strings.Join(causes, "; "))
+}
+
+// checkNavigation 判断导航结果，成功时返回 nil
+func checkNavigation(response playwright.Response, err error) *navigationError {
+	if err != nil {
+		return &navigationError{kind: classifyNavigationError(err), err: err}
+	}
+	if response != nil && response.Status() >= 400 {
+		return &navigationError{
+			kind:   navigationErrorHTTP,
+			status: response.Status(),
+			err:    fmt.Errorf("HTTP %d %s", response.Status(), response.StatusText()),
+		}
+	}
+	return nil
+}
+
+// classifyNavigationError 按 Chromium 的网络错误码区分失败类型
+func classifyNavigationError(err error) string {
+	message := err.Error()
+	switch {
+	case errors.Is(err, playwright.ErrTimeout), strings.Contains(message, "ERR_TIMED_OUT"):
+		return navigationErrorTimeout
+	case strings.Contains(message, "ERR_NAME_NOT_RESOLVED"), strings.Contains(message, "ERR_NAME_RESOLUTION_FAILED"):
+		return navigationErrorDNS
+	case strings.Contains(message, "ERR_CONNECTION_"), strings.Contains(message, "ERR_INTERNET_DISCONNECTED"),
+		strings.Contains(message, "ERR_NETWORK_CHANGED"), strings.Contains(message, "ERR_ADDRESS_UNREACHABLE"),
+		strings.Contains(message, "ERR_PROXY_CONNECTION_FAILED"):
+		return navigationErrorNetwork
+	default:
+		return navigationErrorOther
+	}
+}
+
+// navigationBackoffFor 第 attempt 次失败后的等待时间：指数增长并加入 ±20% 的随机抖动
+func navigationBackoffFor(attempt int) time.Duration {
+	wait := navigationBackoff << (attempt - 1)
+	if wait > navigationMaxBackoff || wait <= 0 {
+		wait = navigationMaxBackoff
+	}
+	jitter := time.Duration(rand.Int64N(int64(wait)*2/5)) - wait/5
+	return wait + jitter
+}
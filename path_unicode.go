@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldedSeparators 中文输入法下容易输成全角的路径分隔符
+var foldedSeparators = strings.NewReplacer("／", "/", "＼", `\`)
+
+// resolvePath 查找路径对应的磁盘上的文件：先按原样查找，找不到时依次尝试 NFC、NFD 形式，
+// 再按规范化后的名称（全角半角、组合字符、emoji 变体选择符统一，Windows 和 macOS 不区分大小写）逐级匹配目录中的文件，
+// 返回实际路径；Excel 中的文件名与磁盘上的 Unicode 形式不一致（如从 macOS 复制的文件）时也能找到。找不到时返回原路径
+func resolvePath(path string) string {
+	if path == "" {
+		return path
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return path
+	}
+	for _, candidate := range []string{norm.NFC.String(path), norm.NFD.String(path)} {
+		if _, err := os.Lstat(candidate); err == nil {
+			return candidate
+		}
+	}
+	if resolved, ok := matchPath(path); ok {
+		return resolved
+	}
+	return path
+}
+
+// matchPath 从根目录逐级按 pathKey 匹配每一级的名称，某一级没有或有多个匹配时失败
+func matchPath(path string) (string, bool) {
+	path = foldedSeparators.Replace(path)
+	if runtime.GOOS == "windows" {
+		// 全角的盘符和冒号，如 Ｄ：\视频
+		if runes := []rune(path); len(runes) >= 2 && runes[1] == '：' {
+			path = norm.NFKC.String(string(runes[:2])) + string(runes[2:])
+		}
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	volume := filepath.VolumeName(abs)
+	current := volume + string(filepath.Separator)
+	for _, name := range strings.Split(strings.Trim(abs[len(volume):], string(filepath.Separator)), string(filepath.Separator)) {
+		if name == "" {
+			continue
+		}
+		candidate := filepath.Join(current, name)
+		if _, err := os.Lstat(candidate); err == nil {
+			current = candidate
+			continue
+		}
+		entries, err := os.ReadDir(current)
+		if err != nil {
+			return "", false
+		}
+		match := ""
+		for _, entry := range entries {
+			if pathKey(entry.Name()) != pathKey(name) {
+				continue
+			}
+			if match != "" {
+				return "", false
+			}
+			match = entry.Name()
+		}
+		if match == "" {
+			return "", false
+		}
+		current = filepath.Join(current, match)
+	}
+	return current, true
+}
+
+// pathKey 比较文件名用的规范形式：NFKC（全角字母数字符号转为半角、组合字符合并），去掉 emoji 变体选择符，
+// Windows 和 macOS 的文件名默认不区分大小写
+func pathKey(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '\ufe0e' || r == '\ufe0f' {
+			return -1
+		}
+		return r
+	}, name)
+	name = norm.NFKC.String(name)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// resolveMediaPaths 将媒体列中的每个文件替换为磁盘上的实际路径（相对路径按 baseDir 解析），多个文件用 ; 连接
+func resolveMediaPaths(value string, baseDir string) string {
+	paths := splitMediaPaths(value)
+	changed := false
+	for i, path := range paths {
+		located, fallback := relativeToBase(path, baseDir)
+		if fallback {
+			log.Printf("⚠️ %s 在任务文件目录 %s 中不存在，按当前目录查找；建议改为相对于任务文件的路径或指定 -base-dir", path, baseDir)
+		}
+		if resolved := resolvePath(located); resolved != located {
+			log.Printf("🔤 文件名与磁盘上的 Unicode 形式不一致，已匹配到: %s", resolved)
+			located = resolved
+		}
+		if located != path {
+			paths[i] = located
+			changed = true
+		}
+	}
+	if !changed {
+		return value
+	}
+	return strings.Join(paths, ";")
+}
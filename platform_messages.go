@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// platformMessageMaxRunes 错误信息中保留的平台提示长度，[class*='error'] 匹配到较大的容器时截断
+const platformMessageMaxRunes = 120
+
+// uploadErrorSelectors 上传过程中的错误提示：先匹配提示框、弹窗中的错误，再按文字和错误样式匹配
+var uploadErrorSelectors = []string{
+	".ant-message-error",
+	".weui-desktop-message--error",
+	".ant-upload-list-item-error",
+	".ant-alert-error",
+	".upload-error",
+	"text=上传失败",
+	"text=格式不支持",
+	"text=文件过大",
+	"text=已达上限",
+	"text=网络错误",
+	"[class*='error']",
+}
+
+// actionErrorSelectors 保存草稿、发表后的错误提示
+var actionErrorSelectors = []string{
+	".ant-message-error",
+	".weui-desktop-message--error",
+	"text=保存失败",
+	"text=发表失败",
+	"text=操作失败",
+	"text=已达上限",
+	"text=网络错误",
+	"[class*='error']",
+}
+
+// messageContainerSelectors 错误元素本身没有文字（如图标）时，读取当前显示的提示框、弹窗的文字
+var messageContainerSelectors = []string{
+	".ant-message-notice",
+	".weui-desktop-toast",
+	".weui-desktop-dialog__bd",
+	".ant-modal-confirm-content",
+	"[role='alert']",
+}
+
+// platformMessageRules 平台提示对应的失败分类，中英文界面的提示都匹配；优先于 errorCategoryRules，
+// 同样是"上传过程中出现错误"，文件过大、格式不支持重试也不会成功
+var platformMessageRules = []categoryRule{
+	{ErrorCategoryDailyLimit, []string{"发表次数已达上限", "次数已达上限", "daily limit", "limit reached"}},
+	{ErrorCategoryInvalidInput, []string{"文件过大", "文件大小超过", "超过大小限制", "file too large", "file size exceeds",
+		"格式不支持", "不支持该格式", "不支持此格式", "unsupported format", "format not supported"}},
+}
+
+// platformMessagePattern 错误信息中平台提示的原文
+var platformMessagePattern = regexp.MustCompile(`平台提示「(.+?)」`)
+
+// pageErrorMessage 按顺序查找可见的错误提示，返回提示本身的文字（不是整个页面的文字）；
+// 找到错误元素但读不到文字时返回空字符串
+func pageErrorMessage(page playwright.Page, selectors []string) (string, bool) {
+	for _, selector := range selectors {
+		locator := page.Locator(selector).First()
+		if visible, _ := locator.IsVisible(); !visible {
+			continue
+		}
+		text, _ := locator.InnerText()
+		message := compactMessage(text)
+		if message == "" {
+			message = visibleNotice(page)
+		}
+		log.Printf("🚨 检测到错误提示: %s - %s", selector, message)
+		return message, true
+	}
+	return "", false
+}
+
+// visibleNotice 当前显示的提示框、弹窗的文字
+func visibleNotice(page playwright.Page) string {
+	for _, selector := range messageContainerSelectors {
+		locator := page.Locator(selector).First()
+		if visible, _ := locator.IsVisible(); !visible {
+			continue
+		}
+		if text, _ := locator.InnerText(); compactMessage(text) != "" {
+			return compactMessage(text)
+		}
+	}
+	return ""
+}
+
+// compactMessage 合并提示文字中的空白和换行，过长时截断
+func compactMessage(text string) string {
+	message := strings.Join(strings.Fields(text), " ")
+	if runes := []rune(message); len(runes) > platformMessageMaxRunes {
+		message = string(runes[:platformMessageMaxRunes]) + "…"
+	}
+	return message
+}
+
+// platformError 带平台提示原文的错误，没有读到提示文字时只有 reason
+func platformError(reason string, message string) error {
+	if message == "" {
+		return errors.New(reason)
+	}
+	return fmt.Errorf("%s: 平台提示「%s」", reason, message)
+}
+
+// platformMessage 从错误信息中取出平台提示的原文
+func platformMessage(errText string) string {
+	if match := platformMessagePattern.FindStringSubmatch(errText); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// platformMessageCategory 按平台提示的原文判断失败分类，没有平台提示或不是已知的提示时为空
+func platformMessageCategory(errText string) string {
+	message := platformMessage(errText)
+	if message == "" {
+		return ""
+	}
+	return matchCategory(platformMessageRules, message)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 平台页面的默认地址，路径相对于平台地址
+const (
+	defaultPlatformBaseURL = "https://channels.weixin.qq.com"
+	defaultCreatePath      = "/platform/post/create"
+	defaultImageCreatePath = "/platform/post/finderNewLifeCreate"
+	defaultPostListPath    = "/platform/post/list"
+	defaultHomePath        = "/platform"
+	defaultAuthDataPath    = "/cgi-bin/mmfinderassistant-bin/auth/auth_data"
+)
+
+// PagesConfig 平台页面地址（配置文件 pages）：平台切换发表入口时改配置即可，无需等待新版本；
+// 测试时用 targets 和 -target 指向模拟服务器。各页面可写完整地址或相对于平台地址的路径
+type PagesConfig struct {
+	BaseURL          string            `yaml:"base_url"`          // 平台地址，默认 https://channels.weixin.qq.com
+	Create           string            `yaml:"create"`            // 视频创建页面，默认 /platform/post/create
+	CreateAlternates []string          `yaml:"create_alternates"` // 备用的创建页面入口，创建页面打不开或没有上传控件时依次尝试
+	ImageCreate      string            `yaml:"image_create"`      // 图文创建页面，默认 /platform/post/finderNewLifeCreate
+	PostList         string            `yaml:"post_list"`         // 作品管理页面，默认 /platform/post/list
+	Home             string            `yaml:"home"`              // 首页（检查登录状态），默认 /platform
+	AuthData         string            `yaml:"auth_data"`         // 登录状态接口，页面元素无法判断是否已登录时请求，默认 /cgi-bin/mmfinderassistant-bin/auth/auth_data
+	Targets          map[string]string `yaml:"targets"`           // 环境名称 -> 平台地址（如 test: http://127.0.0.1:8080），-target 选择后替换 base_url
+}
+
+// Validate 检查页面地址
+func (c PagesConfig) Validate() error {
+	if err := validatePlatformURL(c.BaseURL); err != nil {
+		return fmt.Errorf("base_url %v", err)
+	}
+	for name, base := range c.Targets {
+		if base == "" {
+			return fmt.Errorf("targets.%s 不能为空", name)
+		}
+		if err := validatePlatformURL(base); err != nil {
+			return fmt.Errorf("targets.%s %v", name, err)
+		}
+	}
+	for _, page := range append([]string{c.Create, c.ImageCreate, c.PostList, c.Home, c.AuthData}, c.CreateAlternates...) {
+		if page == "" || strings.HasPrefix(page, "/") {
+			continue
+		}
+		if err := validatePlatformURL(page); err != nil {
+			return fmt.Errorf("%s %v", page, err)
+		}
+	}
+	return nil
+}
+
+// validatePlatformURL 检查完整地址，为空时不检查
+func validatePlatformURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("需要为 http:// 或 https:// 开头的完整地址，或以 / 开头的路径: %s", value)
+	}
+	return nil
+}
+
+// platformPages 本次运行使用的平台页面地址
+type platformPages struct {
+	mu          sync.Mutex
+	base        string
+	create      []string // 创建页面和备用入口，第一个为当前使用的入口
+	imageCreate string
+	postList    string
+	home        string
+	authData    string
+}
+
+// platformURLs 平台页面地址，默认为正式平台，按配置文件 pages 和 -target 设置
+var platformURLs = newPlatformPages()
+
+// newPlatformPages 正式平台的页面地址
+func newPlatformPages() *platformPages {
+	p := &platformPages{}
+	p.Configure(PagesConfig{}, "")
+	return p
+}
+
+// Configure 按配置和环境名称确定各页面的完整地址，target 为空时使用 base_url
+func (p *platformPages) Configure(config PagesConfig, target string) error {
+	base := defaultPlatformBaseURL
+	if config.BaseURL != "" {
+		base = config.BaseURL
+	}
+	if target != "" {
+		targetBase, ok := config.Targets[target]
+		if !ok {
+			return fmt.Errorf("配置文件 pages.targets 中没有环境 %s", target)
+		}
+		base = targetBase
+	}
+	base = strings.TrimSuffix(base, "/")
+	resolve := func(value string, fallback string) string {
+		if value == "" {
+			value = fallback
+		}
+		if strings.HasPrefix(value, "/") {
+			return base + value
+		}
+		return value
+	}
+
+	create := []string{resolve(config.Create, defaultCreatePath)}
+	for _, alternate := range config.CreateAlternates {
+		if page := resolve(alternate, ""); page != "" && !slices.Contains(create, page) {
+			create = append(create, page)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.base = base
+	p.create = create
+	p.imageCreate = resolve(config.ImageCreate, defaultImageCreatePath)
+	p.postList = resolve(config.PostList, defaultPostListPath)
+	p.home = resolve(config.Home, defaultHomePath)
+	p.authData = resolve(config.AuthData, defaultAuthDataPath)
+	return nil
+}
+
+// CreatePage 当前使用的视频创建页面
+func (p *platformPages) CreatePage() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.create[0]
+}
+
+// ImageCreatePage 图文创建页面
+func (p *platformPages) ImageCreatePage() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.imageCreate
+}
+
+// PostListPage 作品管理页面
+func (p *platformPages) PostListPage() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.postList
+}
+
+// HomePage 首页，未登录时会跳转到登录页
+func (p *platformPages) HomePage() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.home
+}
+
+// AuthDataAPI 登录状态接口
+func (p *platformPages) AuthDataAPI() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.authData
+}
+
+// IsCreatePage pageURL 是否为视频、图文创建页面或备用入口（忽略查询参数）
+func (p *platformPages) IsCreatePage(pageURL string) bool {
+	pageURL, _, _ = strings.Cut(pageURL, "?")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Contains(p.create, pageURL) || pageURL == p.imageCreate
+}
+
+// IsPlatformPage pageURL 是否为登录后的平台页面
+func (p *platformPages) IsPlatformPage(pageURL string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.HasPrefix(pageURL, p.base+"/platform/")
+}
+
+// Host 平台地址的主机名
+func (p *platformPages) Host() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if u, err := url.Parse(p.base); err == nil {
+		return u.Host
+	}
+	return p.base
+}
+
+// createPages 创建页面和备用入口，当前使用的入口在前
+func (p *platformPages) createPages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.create)
+}
+
+// useCreatePage 之后优先使用可用的创建页面入口
+func (p *platformPages) useCreatePage(page string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i := slices.Index(p.create, page); i > 0 {
+		p.create = append([]string{page}, slices.Delete(p.create, i, i+1)...)
+	}
+}
+
+// navigateCreatePage 打开视频创建页面：打不开或没有上传控件时依次尝试备用入口，
+// 可用的入口在本次运行中优先使用；跳转到登录页时不尝试备用入口
+func navigateCreatePage(page playwright.Page) error {
+	candidates := platformURLs.createPages()
+	var lastErr error
+	for i, candidate := range candidates {
+		if err := navigateWithRetry(page, candidate); err != nil {
+			lastErr = err
+		} else if len(candidates) == 1 || isUploadPageReady(page) || !isLoggedIn(page) {
+			if i > 0 {
+				log.Printf("✅ 使用备用创建页面入口: %s", candidate)
+				platformURLs.useCreatePage(candidate)
+			}
+			return nil
+		} else {
+			lastErr = fmt.Errorf("创建页面没有上传控件: %s", candidate)
+		}
+		if i < len(candidates)-1 {
+			log.Printf("⚠️ 创建页面 %s 不可用，尝试备用入口: %v", candidate, lastErr)
+		}
+	}
+	return lastErr
+}
@@ -7,8 +7,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// playwrightChromiumRevision 当前 playwright-go 版本所需的 Chromium 版本目录
+const playwrightChromiumRevision = "chromium-1169"
+
 // isPlaywrightInstalled 确保浏览器环境就绪
 func isPlaywrightInstalled() error {
 	log.Println("🔍 检查浏览器环境...")
@@ -44,7 +48,7 @@ func isPlaywrightAlreadyInstalled() bool {
 	}
 
 	// 检查是否有 Chromium 浏览器
-	chromiumPath := filepath.Join(playwrightPath, "chromium-1169")
+	chromiumPath := filepath.Join(playwrightPath, playwrightChromiumRevision)
 	if _, err := os.Stat(chromiumPath); err != nil {
 		log.Printf("❌ Chromium 浏览器不存在: %s", chromiumPath)
 		return false
@@ -86,6 +90,11 @@ func installPlaywrightFromZip() error {
 		return fmt.Errorf("解压失败: %v", err)
 	}
 
+	// 按离线包校验清单检查文件完整性
+	if err := verifyBundleManifest(targetDir); err != nil {
+		return fmt.Errorf("离线包校验失败: %v", err)
+	}
+
 	log.Println("✅ ZIP 文件解压完成")
 	return nil
 }
@@ -123,7 +132,10 @@ func unzip(src, dest string) error {
 	// 遍历 ZIP 文件中的每个文件/目录
 	for _, f := range r.File {
 		// 构建目标路径
-		fpath := filepath.Join(dest, f.Name)
+		fpath, err := unzipPath(dest, f.Name)
+		if err != nil {
+			return err
+		}
 
 		// 检查是否是目录
 		if f.FileInfo().IsDir() {
@@ -138,6 +150,14 @@ func unzip(src, dest string) error {
 			return err
 		}
 
+		// 符号链接：内容为链接目标，只允许指向包内
+		if f.Mode()&os.ModeSymlink != 0 {
+			if err := unzipSymlink(f, fpath); err != nil {
+				return err
+			}
+			continue
+		}
+
 		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
 		if err != nil {
 			return err
@@ -164,3 +184,41 @@ func unzip(src, dest string) error {
 
 	return nil
 }
+
+// unzipPath ZIP 中的 name 解压到 dest 下的路径：不能越出 dest（如含 ../ 的条目名），
+// 也不能位于已解压的符号链接之下，否则会经过链接写到它指向的目录
+func unzipPath(dest, name string) (string, error) {
+	fpath := filepath.Join(dest, name)
+	rel, err := filepath.Rel(dest, fpath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("ZIP 中的 %s 越出解压目录", name)
+	}
+	for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+		if info, err := os.Lstat(filepath.Join(dest, dir)); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("ZIP 中的 %s 位于符号链接 %s 之下", name, filepath.ToSlash(dir))
+		}
+	}
+	return fpath, nil
+}
+
+// unzipSymlink 按 ZIP 中保存的链接目标创建符号链接
+func unzipSymlink(f *zip.File, fpath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	target, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	if !symlinkInside(f.Name, string(target)) {
+		return fmt.Errorf("符号链接 %s 指向解压目录之外: %s", f.Name, target)
+	}
+	os.Remove(fpath)
+	if err := os.Symlink(filepath.FromSlash(string(target)), fpath); err != nil {
+		return fmt.Errorf("创建符号链接 %s 失败: %v", f.Name, err)
+	}
+	log.Printf("   🔗 解压: %s -> %s", f.Name, target)
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// platformMaxVideoSize 视频号网页端单个视频大小上限
+	platformMaxVideoSize int64 = 20 << 30
+	// platformMaxVideoDuration 视频号网页端单个视频时长上限
+	platformMaxVideoDuration = 8 * time.Hour
+	// tempSpaceReserve 临时目录除视频暂存外预留的空间（浏览器缓存等）
+	tempSpaceReserve int64 = 500 << 20
+)
+
+// preflightCheck 运行前检查：临时目录磁盘空间是否足够、视频是否超出平台大小/时长限制
+func preflightCheck(videoCreateTasks []VideoCreateTask, tempDir string, workers int) error {
+	log.Println("🔍 运行前检查磁盘空间和视频大小...")
+
+	var largest int64
+	var warnings []string
+	for _, task := range videoCreateTasks {
+		// 图文动态的图片不受视频大小和时长限制
+		if task.ContentType == ContentTypeImage {
+			continue
+		}
+		info, err := os.Stat(task.VideoPath)
+		if os.IsNotExist(err) {
+			// 配置了前置命令时视频可能在任务开始前才准备好
+			log.Printf("⚠️ 第%d行: 视频文件尚不存在，跳过检查: %s", task.RowIndex, task.VideoPath)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("第%d行: 读取视频文件失败: %v", task.RowIndex, err)
+		}
+		size := info.Size()
+		if size > largest {
+			largest = size
+		}
+
+		if size > platformMaxVideoSize {
+			warnings = append(warnings, fmt.Sprintf("第%d行: %s 大小 %s 超过平台限制 %s",
+				task.RowIndex, filepath.Base(task.VideoPath), formatBytes(size), formatBytes(platformMaxVideoSize)))
+		}
+		if duration, err := probeVideoDuration(task.VideoPath); err == nil && duration > platformMaxVideoDuration {
+			warnings = append(warnings, fmt.Sprintf("第%d行: %s 时长 %v 超过平台限制 %v",
+				task.RowIndex, filepath.Base(task.VideoPath), duration.Round(time.Second), platformMaxVideoDuration))
+		}
+	}
+
+	if len(warnings) > 0 {
+		log.Printf("⚠️ 以下 %d 个视频超出平台限制，上传时可能失败:\n%s", len(warnings), strings.Join(warnings, "\n"))
+	}
+
+	// 每个并发任务最多暂存/转码一个视频
+	required := largest*int64(workers) + tempSpaceReserve
+	free, err := freeDiskSpace(tempDir)
+	if err != nil {
+		log.Printf("⚠️ 无法检查磁盘空间: %v", err)
+		return nil
+	}
+	log.Printf("💾 临时目录可用空间: %s, 预计需要: %s", formatBytes(int64(free)), formatBytes(required))
+	if int64(free) < required {
+		return fmt.Errorf("临时目录 %s 磁盘空间不足: 可用 %s, 需要 %s", tempDir, formatBytes(int64(free)), formatBytes(required))
+	}
+
+	log.Println("✅ 运行前检查通过")
+	return nil
+}
+
+// probeVideoDuration 读取 MP4/MOV 文件 moov/mvhd 中的时长，不支持的格式返回错误
+func probeVideoDuration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	moovOffset, moovSize, err := findMP4Box(f, 0, info.Size(), "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhdOffset, _, err := findMP4Box(f, moovOffset, moovOffset+moovSize, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+
+	// mvhd: version(1) flags(3) 之后按版本区分字段长度
+	header := make([]byte, 32)
+	if _, err := f.ReadAt(header, mvhdOffset); err != nil {
+		return 0, err
+	}
+	var timescale, duration uint64
+	if header[0] == 1 {
+		timescale = uint64(binary.BigEndian.Uint32(header[20:24]))
+		duration = binary.BigEndian.Uint64(header[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(header[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("无效的时间刻度")
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// findMP4Box 在 [start, end) 范围内查找指定类型的box，返回box内容的偏移和长度
+func findMP4Box(r io.ReaderAt, start, end int64, boxType string) (int64, int64, error) {
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return 0, 0, err
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0: // 延伸到文件末尾
+			size = end - offset
+		case 1: // 64位长度
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return 0, 0, err
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize {
+			return 0, 0, fmt.Errorf("无效的box长度")
+		}
+		if string(header[4:8]) == boxType {
+			return offset + headerSize, size - headerSize, nil
+		}
+		offset += size
+	}
+	return 0, 0, fmt.Errorf("未找到 %s", boxType)
+}
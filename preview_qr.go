@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// previewQRChain 手机预览弹窗中的二维码
+var previewQRChain = SelectorChain{Name: "preview_qrcode", Selectors: []string{
+	".weui-desktop-dialog img[src*='qrcode']",
+	".weui-desktop-dialog canvas",
+	".weui-desktop-dialog img",
+	"[role='dialog'] canvas",
+	"[role='dialog'] img",
+	".preview-dialog img",
+}}
+
+// previewSheetHead 预览二维码汇总页面的开头：每个二维码一页，打印或另存为 PDF 时每页一个
+const previewSheetHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>手机预览二维码</title>
+<style>
+body { font-family: sans-serif; margin: 0; }
+section { min-height: 100vh; box-sizing: border-box; padding: 24px; text-align: center; page-break-after: always; }
+section img { width: 320px; max-width: 90%%; image-rendering: pixelated; }
+.meta { color: #555; max-width: 640px; margin: 8px auto; word-break: break-all; }
+nav a { margin: 0 12px; }
+@media print { nav { display: none; } }
+</style>
+</head>
+<body>
+<p style="text-align:center">共 %d 个作品，生成于 %s，依次用手机微信扫码预览</p>
+`
+
+// capturePreviewQR 手机预览后截取弹窗中的二维码，保存到 output-dir/preview/ 目录，返回文件路径
+func capturePreviewQR(page playwright.Page, result *TaskResult, outputDir string) (string, error) {
+	locator, err := previewQRChain.FindVisible(page)
+	if err != nil {
+		return "", fmt.Errorf("未找到预览二维码: %v", err)
+	}
+	png, err := locator.Screenshot(playwright.LocatorScreenshotOptions{Timeout: playwright.Float(5000)})
+	if err != nil {
+		return "", fmt.Errorf("截取预览二维码失败: %v", err)
+	}
+	dir := filepath.Join(outputDir, "preview")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建预览二维码目录失败: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("row%d_%s.png", result.Task.RowIndex, time.Now().Format("20060102_150405")))
+	if err := os.WriteFile(path, png, 0644); err != nil {
+		return "", fmt.Errorf("保存预览二维码失败: %v", err)
+	}
+	return path, nil
+}
+
+// writePreviewSheet 将本次运行的所有预览二维码汇总到一个 HTML 页面（图片内嵌，可直接发给审核人员），
+// 每个作品一页，附行号、文件名和描述；没有预览二维码时不生成，返回空字符串
+func writePreviewSheet(results []TaskResult, outputDir string) (string, error) {
+	var previews []TaskResult
+	for _, result := range results {
+		if result.PreviewQR != "" {
+			previews = append(previews, result)
+		}
+	}
+	if len(previews) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, previewSheetHead, len(previews), time.Now().Format("2006-01-02 15:04"))
+	for i, result := range previews {
+		png, err := os.ReadFile(result.PreviewQR)
+		if err != nil {
+			log.Printf("⚠️ 读取第%d行的预览二维码失败: %v", result.Task.RowIndex, err)
+			continue
+		}
+		fmt.Fprintf(&b, "<section id=\"p%d\">\n<h2>%d / %d - 第%d行 %s</h2>\n", i+1, i+1, len(previews),
+			result.Task.RowIndex, html.EscapeString(filepath.Base(result.Task.VideoPath)))
+		fmt.Fprintf(&b, "<img src=\"data:image/png;base64,%s\" alt=\"预览二维码\">\n", base64.StdEncoding.EncodeToString(png))
+		if result.Task.ShortTitle != "" {
+			fmt.Fprintf(&b, "<p class=\"meta\">短标题: %s</p>\n", html.EscapeString(result.Task.ShortTitle))
+		}
+		fmt.Fprintf(&b, "<p class=\"meta\">%s</p>\n", html.EscapeString(result.Task.Description))
+		b.WriteString("<nav>")
+		if i > 0 {
+			fmt.Fprintf(&b, "<a href=\"#p%d\">上一个</a>", i)
+		}
+		if i < len(previews)-1 {
+			fmt.Fprintf(&b, "<a href=\"#p%d\">下一个</a>", i+2)
+		}
+		b.WriteString("</nav>\n</section>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	path := filepath.Join(outputDir, fmt.Sprintf("preview_qr_%s.html", time.Now().Format("20060102_150405")))
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("写入预览二维码汇总失败: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// defaultPublishedWindow 重试时在作品管理中查找多久之内发表的相同作品
+const defaultPublishedWindow = 30 * time.Minute
+
+// errAlreadyPublished 重试时在作品管理中找到了相同的作品，不再点击发表
+var errAlreadyPublished = errors.New("作品已发表")
+
+// PublishCheckOptions 重试时的发表前检查：上一次执行在点击发表后超时或崩溃时作品可能已经发表，
+// 再次点击发表会重复发表，因此先到作品管理中查找相同的作品
+type PublishCheckOptions struct {
+	Window time.Duration // 查找该时间内发表的作品，为 0 时不检查
+	Retry  bool          // 本次处理的任务都是重试（retry 子命令、队列重新投递的消息）
+}
+
+// enabled 任务是否需要在发表前检查：重试的任务，或本次运行中崩溃恢复、重新登录后再次执行的任务
+func (o PublishCheckOptions) enabled(result *TaskResult) bool {
+	return o.Window > 0 && result.Task.Action == "publish" && (o.Retry || result.Attempts > 1)
+}
+
+// findPublishedPost 在新标签页中打开作品管理，查找描述（为空时用短标题）相同、在 window 内发表的作品；
+// 定时发表的作品按定时时间比较。返回找到的作品的说明，没有找到时返回空字符串
+func findPublishedPost(page playwright.Page, description string, shortTitle string, scheduledAt time.Time, window time.Duration) (string, error) {
+	keyword := postKeyword(description)
+	if keyword == "" {
+		keyword = postKeyword(shortTitle)
+	}
+	if keyword == "" {
+		log.Println("⚠️ 描述和短标题为空，无法在作品管理中查找是否已发表")
+		return "", nil
+	}
+
+	listPage, err := page.Context().NewPage()
+	if err != nil {
+		return "", fmt.Errorf("打开作品管理失败: %v", err)
+	}
+	defer listPage.Close()
+
+	log.Printf("🔍 重试前在作品管理中查找是否已发表: %s", keyword)
+	if err := navigateWithRetry(listPage, platformURLs.PostListPage()); err != nil {
+		return "", fmt.Errorf("打开作品管理失败: %v", err)
+	}
+	time.Sleep(3 * time.Second)
+
+	text, found := findPostText(listPage, keyword)
+	if !found {
+		log.Printf("✅ 作品管理中没有相同的作品，继续发表: %s", keyword)
+		return "", nil
+	}
+	match := scheduledTimePattern.FindString(text)
+	if match == "" {
+		log.Printf("⚠️ 作品管理中有相同的作品但没有显示时间，无法判断是否为本次发表，继续发表: %s", keyword)
+		return "", nil
+	}
+	listed, err := parseDisplayedSchedule(match)
+	if err != nil {
+		log.Printf("⚠️ 作品管理中的作品时间 %q 无法解析，继续发表: %v", match, err)
+		return "", nil
+	}
+
+	if !scheduledAt.IsZero() {
+		if !sameScheduleMinute(listed, scheduledAt) {
+			log.Printf("✅ 作品管理中相同的作品定时时间为 %s，不是本次发表，继续发表", listed.Format(scheduleDisplayLayout))
+			return "", nil
+		}
+		return fmt.Sprintf("作品管理中已有定时 %s 发表的作品: %s", listed.Format(scheduleDisplayLayout), keyword), nil
+	}
+	// 列表只显示到分钟
+	if listed.Before(time.Now().Add(-window).Truncate(time.Minute)) {
+		log.Printf("✅ 作品管理中相同的作品发表于 %s，早于 %s 之内，继续发表", listed.Format(scheduleDisplayLayout), window)
+		return "", nil
+	}
+	return fmt.Sprintf("作品管理中已有 %s 发表的作品: %s", listed.Format(scheduleDisplayLayout), keyword), nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// qrCodePage 扫码页面，定时刷新以获取最新的二维码
+const qrCodePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="5">
+<title>视频号扫码登录</title>
+</head>
+<body style="text-align:center;font-family:sans-serif">
+<h3>请使用微信扫码登录视频号</h3>
+<img src="/qr.png?t=%d" alt="二维码加载中..." style="max-width:90%%">
+<p>更新时间: %s</p>
+</body>
+</html>`
+
+// QRCodeServer 通过HTTP提供登录二维码，供无界面环境（容器/服务器）扫码
+type QRCodeServer struct {
+	mu      sync.RWMutex
+	png     []byte
+	updated time.Time
+	server  *http.Server
+}
+
+// NewQRCodeServer 创建二维码HTTP服务
+func NewQRCodeServer(addr string) *QRCodeServer {
+	s := &QRCodeServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/qr.png", s.handleQRCode)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	s.server = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// Start 后台启动HTTP服务
+func (s *QRCodeServer) Start() {
+	go func() {
+		log.Printf("📱 扫码页面已启动: http://%s/", qrDisplayAddr(s.server.Addr))
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ 扫码页面服务异常: %v", err)
+		}
+	}()
+}
+
+// qrDisplayAddr 未指定主机时用本机主机名显示扫码地址，方便通知中直接打开
+func qrDisplayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		if hostname, err := os.Hostname(); err == nil {
+			return hostname + addr
+		}
+	}
+	return addr
+}
+
+// Stop 关闭HTTP服务
+func (s *QRCodeServer) Stop() {
+	if err := s.server.Close(); err != nil {
+		log.Printf("⚠️ 关闭扫码页面服务失败: %v", err)
+	}
+}
+
+// Update 更新二维码图片，可直接作为 QRCodeHandler 使用
+func (s *QRCodeServer) Update(png []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.png = png
+	s.updated = time.Now()
+}
+
+func (s *QRCodeServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	updated := s.updated
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, qrCodePage, updated.UnixNano(), updated.Format("2006-01-02 15:04:05"))
+}
+
+func (s *QRCodeServer) handleQRCode(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	png := s.png
+	s.mu.RUnlock()
+
+	if len(png) == 0 {
+		http.Error(w, "二维码尚未生成", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
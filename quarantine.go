@@ -0,0 +1,309 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultQuarantineAfter 同一行因任务本身的问题失败多少次后隔离
+const defaultQuarantineAfter = 2
+
+// QuarantineEntry 因任务本身的问题失败的行，失败次数达到阈值后隔离
+type QuarantineEntry struct {
+	Source        string    `json:"source"`      // 任务文件的绝对路径
+	Fingerprint   string    `json:"fingerprint"` // 行内容和视频文件大小、修改时间的摘要，变化后视为已修改
+	RowIndex      int       `json:"row_index"`   // 按行号对应任务文件中的行，内容相同的行分别记录
+	VideoPath     string    `json:"video_path,omitempty"`
+	Failures      int       `json:"failures"`
+	Category      string    `json:"category"`
+	Reason        string    `json:"reason"`
+	LastFailedAt  time.Time `json:"last_failed_at"`
+	QuarantinedAt time.Time `json:"quarantined_at"` // 为零值时尚未隔离
+}
+
+// quarantined 是否已隔离
+func (e QuarantineEntry) quarantined() bool {
+	return !e.QuarantinedAt.IsZero()
+}
+
+// Quarantine 跨运行保存的隔离列表（JSON 文件）：多次校验失败或因任务本身的问题上传失败的行被隔离，
+// 之后运行同一任务文件时跳过，该行或视频文件修改后自动解除隔离；为 nil 时不隔离
+type Quarantine struct {
+	mu      sync.Mutex
+	path    string
+	after   int
+	Entries []QuarantineEntry `json:"entries"`
+
+	source string         // 本次运行的任务文件
+	rows   map[int]string // 本次运行的行号 -> 指纹
+	videos map[int]string // 本次运行的行号 -> 视频位置
+}
+
+// OpenQuarantine 打开隔离列表，after 为隔离前允许失败的次数，不大于 0 时不启用
+func OpenQuarantine(path string, after int) (*Quarantine, error) {
+	if after <= 0 {
+		return nil, nil
+	}
+	q := &Quarantine{path: path, after: after}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开隔离列表失败: %v", err)
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("解析隔离列表失败: %v", err)
+	}
+	return q, nil
+}
+
+// Scan 计算任务文件各行的指纹，返回需要跳过的已隔离行；已修改或删除的行解除隔离。
+// 标准输入和数据库任务没有固定的文件，不隔离
+func (q *Quarantine) Scan(source *TaskSource) map[int]bool {
+	if q == nil || source.Path == stdinSource || source.Format == TaskFormatSQL {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.source = source.Path
+	if abs, err := filepath.Abs(source.Path); err == nil {
+		q.source = abs
+	}
+	q.rows = make(map[int]string)
+	q.videos = make(map[int]string)
+	for i, row := range source.Rows[1:] {
+		q.rows[i+2] = rowFingerprint(row, source.BaseDir)
+		if video := taskRowCells(row).get(colVideoPath); video != "" {
+			q.videos[i+2] = video
+		}
+	}
+
+	skip := make(map[int]bool)
+	kept := q.Entries[:0]
+	for _, entry := range q.Entries {
+		if entry.Source != q.source {
+			kept = append(kept, entry)
+			continue
+		}
+		row := entry.RowIndex
+		if q.rows[row] != entry.Fingerprint {
+			if entry.quarantined() {
+				log.Printf("♻️ 第%d行或其视频文件已修改，解除隔离", row)
+			}
+			continue
+		}
+		if entry.quarantined() {
+			skip[row] = true
+			log.Printf("🚫 第%d行已隔离(失败%d次[%s]: %s)，跳过；修改该行或视频文件后自动恢复",
+				row, entry.Failures, entry.Category, entry.Reason)
+		}
+		kept = append(kept, entry)
+	}
+	q.Entries = kept
+	return skip
+}
+
+// RecordInvalid 记录校验失败的行，返回未隔离、仍需修正的行
+func (q *Quarantine) RecordInvalid(rowErrors []rowError) []rowError {
+	if q == nil || q.rows == nil {
+		return rowErrors
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var remaining []rowError
+	for _, rowErr := range rowErrors {
+		if !q.record(rowErr.Row, ErrorCategoryInvalidInput, rowErr.Err.Error()) {
+			remaining = append(remaining, rowErr)
+		}
+	}
+	return remaining
+}
+
+// RecordResults 记录任务结果：成功的行清除失败次数，因任务本身的问题失败的行增加失败次数
+func (q *Quarantine) RecordResults(results []TaskResult) {
+	if q == nil || q.rows == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, result := range results {
+		if result.Success {
+			if index := q.find(result.Task.RowIndex); index >= 0 {
+				q.Entries = append(q.Entries[:index], q.Entries[index+1:]...)
+			}
+			continue
+		}
+		// 与重试队列相同：重试也不会成功的失败才是任务本身的问题
+		if !retryableCategory(result.ErrorCategory) {
+			q.record(result.Task.RowIndex, result.ErrorCategory, result.Error)
+		}
+	}
+}
+
+// record 增加一行的失败次数，达到阈值时隔离，返回该行是否已隔离
+func (q *Quarantine) record(row int, category string, reason string) bool {
+	fingerprint, ok := q.rows[row]
+	if !ok {
+		return false
+	}
+	index := q.find(row)
+	if index < 0 {
+		q.Entries = append(q.Entries, QuarantineEntry{Source: q.source, Fingerprint: fingerprint, RowIndex: row, VideoPath: q.videos[row]})
+		index = len(q.Entries) - 1
+	}
+	entry := &q.Entries[index]
+	entry.Failures++
+	entry.Category = category
+	entry.Reason = reason
+	entry.LastFailedAt = time.Now()
+	if !entry.quarantined() && entry.Failures >= q.after {
+		entry.QuarantinedAt = entry.LastFailedAt
+		log.Printf("🚫 第%d行已失败%d次[%s]，已隔离，之后运行时跳过；修改该行或视频文件后自动恢复", row, entry.Failures, category)
+	}
+	return entry.quarantined()
+}
+
+// find 查找本次任务文件中该行的记录（行号和指纹都相同），找不到时返回 -1
+func (q *Quarantine) find(row int) int {
+	fingerprint := q.rows[row]
+	for i, entry := range q.Entries {
+		if entry.Source == q.source && entry.RowIndex == row && entry.Fingerprint == fingerprint {
+			return i
+		}
+	}
+	return -1
+}
+
+// Report 打印本次任务文件中已隔离的行及原因
+func (q *Quarantine) Report() {
+	if q == nil || q.rows == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var quarantined []QuarantineEntry
+	for _, entry := range q.Entries {
+		if entry.Source == q.source && entry.quarantined() {
+			quarantined = append(quarantined, entry)
+		}
+	}
+	if len(quarantined) == 0 {
+		return
+	}
+	log.Printf("🚫 ===== 已隔离的行(%d) =====", len(quarantined))
+	for _, entry := range quarantined {
+		log.Printf("🚫 第%d行 %s - 失败%d次[%s]: %s", entry.RowIndex, filepath.Base(entry.VideoPath), entry.Failures, entry.Category, entry.Reason)
+	}
+	log.Printf("💡 隔离列表: %s，修改该行或视频文件后自动恢复，也可执行 quarantine clear 解除隔离", q.path)
+}
+
+// Save 写入隔离列表（先写临时文件再替换，避免写一半），q 为 nil 时不写入
+func (q *Quarantine) Save() error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.Entries == nil {
+		q.Entries = []QuarantineEntry{}
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化隔离列表失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("创建隔离列表目录失败: %v", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入隔离列表失败: %v", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("写入隔离列表失败: %v", err)
+	}
+	return nil
+}
+
+// rowFingerprint 行内容和视频文件（大小、修改时间）的摘要，文件不存在时也计入，补上文件后视为已修改
+func rowFingerprint(row []string, baseDir string) string {
+	hash := sha256.New()
+	for _, cell := range row {
+		fmt.Fprintf(hash, "%q,", strings.TrimSpace(cell))
+	}
+	if video := taskRowCells(row).get(colVideoPath); video != "" {
+		for _, path := range splitMediaPaths(video) {
+			located, _ := relativeToBase(path, baseDir)
+			if info, err := os.Stat(resolvePath(located)); err == nil {
+				fmt.Fprintf(hash, "|%s:%d:%d", path, info.Size(), info.ModTime().UnixNano())
+			} else {
+				fmt.Fprintf(hash, "|%s:missing", path)
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// runQuarantineCommand 处理 quarantine 子命令：list 列出已隔离的行，clear 解除隔离（可只解除指定任务文件的行）
+func runQuarantineCommand(args []string) error {
+	usage := fmt.Errorf("用法: quarantine list [-file=隔离列表] | quarantine clear [-file=隔离列表] [任务文件]")
+	if len(args) == 0 {
+		return usage
+	}
+	fs := flag.NewFlagSet("quarantine "+args[0], flag.ExitOnError)
+	path := fs.String("file", "quarantine.json", "隔离列表文件(上传时为 output-dir/quarantine.json)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	q, err := OpenQuarantine(*path, defaultQuarantineAfter)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		count := 0
+		for _, entry := range q.Entries {
+			if !entry.quarantined() {
+				continue
+			}
+			count++
+			fmt.Printf("%s 第%d行 %s\n    失败%d次[%s] 隔离于 %s: %s\n", entry.Source, entry.RowIndex, entry.VideoPath,
+				entry.Failures, entry.Category, entry.QuarantinedAt.Format("2006-01-02 15:04"), entry.Reason)
+		}
+		fmt.Printf("共 %d 行已隔离\n", count)
+		return nil
+	case "clear":
+		source := ""
+		if fs.NArg() > 0 {
+			if source, err = filepath.Abs(fs.Arg(0)); err != nil {
+				return err
+			}
+		}
+		kept := q.Entries[:0]
+		for _, entry := range q.Entries {
+			if source != "" && entry.Source != source {
+				kept = append(kept, entry)
+			}
+		}
+		log.Printf("✅ 已解除 %d 条隔离记录", len(q.Entries)-len(kept))
+		q.Entries = kept
+		return q.Save()
+	default:
+		return usage
+	}
+}
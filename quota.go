@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultQuotaWarnAt 发表数量达到上限的该比例时警告
+const defaultQuotaWarnAt = 0.8
+
+// QuotaLimit 单个账号的发表数量上限，为 0 时不限制
+type QuotaLimit struct {
+	Daily  int `yaml:"daily"`  // 每天最多发表的作品数（按发表日期，定时发表按定时时间）
+	Weekly int `yaml:"weekly"` // 每周（周一至周日）最多发表的作品数
+}
+
+// QuotaConfig 发表配额（配置文件 quota）：按上传历史统计每个账号每天、每周的发表数量，
+// 多人共用一个账号时共用同一个上传历史文件，避免合计超发
+type QuotaConfig struct {
+	Daily    int                   `yaml:"daily"`    // 所有账号默认的每天上限
+	Weekly   int                   `yaml:"weekly"`   // 所有账号默认的每周上限
+	WarnAt   float64               `yaml:"warn_at"`  // 达到上限的该比例时警告并发送 on_quota_warning 通知，默认 0.8
+	Accounts map[string]QuotaLimit `yaml:"accounts"` // 按账号（视频号名称）单独设置，覆盖默认上限
+}
+
+// Validate 检查配额配置
+func (c QuotaConfig) Validate() error {
+	if c.WarnAt < 0 || c.WarnAt > 1 {
+		return fmt.Errorf("warn_at 需要在 0 到 1 之间")
+	}
+	for account, limit := range c.Accounts {
+		if limit.Daily < 0 || limit.Weekly < 0 {
+			return fmt.Errorf("账号 %s 的 daily、weekly 不能为负数", account)
+		}
+	}
+	if c.Daily < 0 || c.Weekly < 0 {
+		return fmt.Errorf("daily、weekly 不能为负数")
+	}
+	return nil
+}
+
+// limit 账号的上限，未单独设置时使用默认上限
+func (c QuotaConfig) limit(account string) QuotaLimit {
+	if limit, ok := c.Accounts[account]; ok {
+		return limit
+	}
+	return QuotaLimit{Daily: c.Daily, Weekly: c.Weekly}
+}
+
+// warnAt 上限的警告比例
+func (c QuotaConfig) warnAt() float64 {
+	if c.WarnAt > 0 {
+		return c.WarnAt
+	}
+	return defaultQuotaWarnAt
+}
+
+// quotaUsage 账号按发表日期统计的发表数量
+type quotaUsage struct {
+	daily  map[string]int // 日期 -> 数量
+	weekly map[string]int // 周一的日期 -> 数量
+}
+
+// dayKey 发表日期
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// weekKey 发表日期所在周的周一
+func weekKey(t time.Time) string {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset).Format("2006-01-02")
+}
+
+// add 计入一个在 t 发表的作品
+func (u *quotaUsage) add(t time.Time) {
+	u.daily[dayKey(t)]++
+	u.weekly[weekKey(t)]++
+}
+
+// publishTime 作品的发表时间：定时发表为定时时间，否则为 now
+func publishTime(schedule bool, scheduleTime string, now time.Time) time.Time {
+	if schedule {
+		if t, err := time.ParseInLocation("2006/01/02 15:04", scheduleTime, time.Local); err == nil {
+			return t
+		}
+	}
+	return now
+}
+
+// accountUsage 统计上传历史中账号已发表的作品（保存草稿、手机预览不计入）
+func accountUsage(history *UploadHistory, account string) *quotaUsage {
+	usage := &quotaUsage{daily: make(map[string]int), weekly: make(map[string]int)}
+	for _, record := range history.Records() {
+		if record.Account != account || record.Action != "publish" {
+			continue
+		}
+		usage.add(publishTime(record.ScheduleTime != "", record.ScheduleTime, record.UploadedAt))
+	}
+	return usage
+}
+
+// applyQuota 按配额筛选本次要发表的任务：会超过每天或每周上限的发表任务不上传，
+// 返回保留的任务和接近上限的警告；配额未设置时原样返回
+func applyQuota(tasks []VideoCreateTask, history *UploadHistory, account string, config QuotaConfig) ([]VideoCreateTask, []string) {
+	limit := config.limit(account)
+	if limit.Daily == 0 && limit.Weekly == 0 {
+		return tasks, nil
+	}
+	usage := accountUsage(history, account)
+	now := time.Now()
+	var (
+		kept     []VideoCreateTask
+		warnings []string
+		warned   = make(map[string]bool)
+	)
+	for _, task := range tasks {
+		if task.Action != "publish" {
+			kept = append(kept, task)
+			continue
+		}
+		t := publishTime(task.Schedule, task.ScheduleTime, now)
+		day, week := dayKey(t), weekKey(t)
+		if limit.Daily > 0 && usage.daily[day] >= limit.Daily {
+			log.Printf("⛔ 第%d行: 账号 %s 在 %s 已发表 %d 个作品，达到每天上限 %d，不上传", task.RowIndex, account, day, usage.daily[day], limit.Daily)
+			continue
+		}
+		if limit.Weekly > 0 && usage.weekly[week] >= limit.Weekly {
+			log.Printf("⛔ 第%d行: 账号 %s 在 %s 开始的一周已发表 %d 个作品，达到每周上限 %d，不上传", task.RowIndex, account, week, usage.weekly[week], limit.Weekly)
+			continue
+		}
+		usage.add(t)
+		kept = append(kept, task)
+
+		if warning := quotaWarning("每天", day, usage.daily[day], limit.Daily, config.warnAt()); warning != "" && !warned["d"+day] {
+			warned["d"+day] = true
+			warnings = append(warnings, warning)
+		}
+		if warning := quotaWarning("每周", week+" 开始的一周", usage.weekly[week], limit.Weekly, config.warnAt()); warning != "" && !warned["w"+week] {
+			warned["w"+week] = true
+			warnings = append(warnings, warning)
+		}
+	}
+	for _, warning := range warnings {
+		log.Printf("⚠️ 账号 %s %s", account, warning)
+	}
+	return kept, warnings
+}
+
+// quotaWarning 发表数量达到上限的 warnAt 比例时的警告，未达到时返回空字符串
+func quotaWarning(period string, when string, count int, limit int, warnAt float64) string {
+	if limit == 0 || count < int(math.Ceil(float64(limit)*warnAt)) {
+		return ""
+	}
+	if count >= limit {
+		return fmt.Sprintf("%s 发表数量 %d 已达到%s上限 %d", when, count, period, limit)
+	}
+	return fmt.Sprintf("%s 发表数量 %d 接近%s上限 %d", when, count, period, limit)
+}
+
+// quotaEvent 接近发表上限的通知
+func quotaEvent(account string, warnings []string) NotifyEvent {
+	return NotifyEvent{
+		Type:    EventOnQuotaWarning,
+		Title:   fmt.Sprintf("账号 %s 发表数量接近上限", account),
+		Message: strings.Join(warnings, "\n"),
+	}
+}
+
+// runQuotaCommand 处理 quota 子命令：按上传历史显示各账号今天、本周的发表数量和上限
+func runQuotaCommand(args []string) error {
+	fs := flag.NewFlagSet("quota", flag.ExitOnError)
+	path := fs.String("file", "upload_history.jsonl", "上传历史文件(上传时为 output-dir/upload_history.jsonl)")
+	configPath := fs.String("config", "", "配置文件路径(YAML), 读取 quota 中的上限")
+	account := fs.String("account", "", "只显示该账号(视频号名称)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	history, err := OpenUploadHistory(*path)
+	if err != nil {
+		return err
+	}
+
+	var accounts []string
+	for _, record := range history.Records() {
+		if record.Account != "" && !slices.Contains(accounts, record.Account) {
+			accounts = append(accounts, record.Account)
+		}
+	}
+	for name := range config.Quota.Accounts {
+		if !slices.Contains(accounts, name) {
+			accounts = append(accounts, name)
+		}
+	}
+	slices.Sort(accounts)
+
+	now := time.Now()
+	fmt.Printf("%-20s %-12s %-12s\n", "账号", "今天", "本周")
+	for _, name := range accounts {
+		if *account != "" && name != *account {
+			continue
+		}
+		usage := accountUsage(history, name)
+		limit := config.Quota.limit(name)
+		fmt.Printf("%-20s %-12s %-12s\n", name,
+			formatQuota(usage.daily[dayKey(now)], limit.Daily), formatQuota(usage.weekly[weekKey(now)], limit.Weekly))
+	}
+	return nil
+}
+
+// formatQuota 已发表数量/上限，未设置上限时只显示数量
+func formatQuota(count int, limit int) string {
+	if limit == 0 {
+		return fmt.Sprintf("%d", count)
+	}
+	return fmt.Sprintf("%d/%d", count, limit)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// ReloginFunc 重新登录并返回新的认证状态，如扫码登录
+type ReloginFunc func() (*PageState, error)
+
+// reauthenticator 运行中登录失效时暂停任务、重新登录，并把新的认证状态恢复到所有浏览器；
+// 多个并发任务同时发现登录失效时只登录一次
+type reauthenticator struct {
+	mu          sync.Mutex
+	login       ReloginFunc
+	sessions    []*browserSession
+	channelName string // 首次登录的视频号，重新登录后必须一致
+	generation  int    // 每次重新登录成功后加一
+	err         error  // 重新登录失败后不再重试，后续任务直接失败
+}
+
+// newReauthenticator 未提供重新登录方式时返回 nil，登录失效的任务直接失败
+func newReauthenticator(login ReloginFunc, sessions []*browserSession, authState *PageState) *reauthenticator {
+	if login == nil {
+		return nil
+	}
+	r := &reauthenticator{login: login, sessions: sessions}
+	if authState != nil {
+		r.channelName = authState.ChannelName
+	}
+	return r
+}
+
+// Generation 当前的登录代数，任务开始前记录，用于判断其他任务是否已重新登录
+func (r *reauthenticator) Generation() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+// wait 正在重新登录时等待登录完成，避免新任务用失效的登录状态开始
+func (r *reauthenticator) wait() {
+	if r != nil {
+		r.mu.Lock()
+		r.mu.Unlock()
+	}
+}
+
+// canRetry 任务是否因登录失效而失败且可以重新登录后重试
+func (r *reauthenticator) canRetry(errMessage string) bool {
+	return r != nil && classifyError(errMessage) == ErrorCategoryLoginExpired
+}
+
+// Reauth 重新登录并恢复认证状态；代数已变化说明其他任务已完成重新登录
+func (r *reauthenticator) Reauth(generation int) error {
+	if r == nil {
+		return fmt.Errorf("未配置重新登录")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation != generation {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	log.Println("🔐 登录信息失效，暂停任务并重新登录...")
+	authState, err := r.login()
+	if err != nil {
+		r.err = fmt.Errorf("重新登录失败: %v", err)
+		return r.err
+	}
+	if r.channelName != "" && authState.ChannelName != "" && authState.ChannelName != r.channelName {
+		r.err = fmt.Errorf("重新登录的视频号(%s)与原视频号(%s)不一致", authState.ChannelName, r.channelName)
+		return r.err
+	}
+	for _, session := range r.sessions {
+		if err := session.Restore(authState); err != nil {
+			return fmt.Errorf("恢复登录状态失败: %v", err)
+		}
+	}
+	r.generation++
+	log.Println("✅ 重新登录成功，继续处理剩余任务")
+	return nil
+}
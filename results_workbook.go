@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 结果工作簿中的工作表：任务表默认为 Sheet1（不存在时为第一个工作表）
+const (
+	resultsTaskSheet    = "Sheet1"
+	resultsSummarySheet = "汇总"
+)
+
+// 结果行的背景色（与Excel内置"好"、"差"样式一致）
+const (
+	resultSuccessColor = "C6EFCE"
+	resultFailureColor = "FFC7CE"
+)
+
+// resultColumns 追加到任务表末尾的结果列
+var resultColumns = []string{"上传结果", "失败分类", "失败步骤", "错误信息", "耗时(秒)", "上传耗时(秒)", "上传速度(MB/s)", "执行次数", "视频号", "显示位置", "定时调整", "操作备注", "步骤记录", "执行记录"}
+
+// WriteResultsWorkbook 将结果写回Excel副本：任务表每行追加结果列，并增加"汇总"工作表。
+// 保存到 outputDir/log 下，返回文件路径
+func WriteResultsWorkbook(source *TaskSource, outputDir string, results []TaskResult) (string, error) {
+	return writeResultsWorkbook(source, outputDir, results, false)
+}
+
+// writeResultsWorkbook onlyResultRows 为 true 时删除不属于 results 的数据行（按账号拆分报告时使用）
+func writeResultsWorkbook(source *TaskSource, outputDir string, results []TaskResult, onlyResultRows bool) (string, error) {
+	f, err := openWorkbookForResults(source)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var rowMap map[int]int
+	if onlyResultRows {
+		if rowMap, err = keepResultRows(f, results); err != nil {
+			return "", err
+		}
+	}
+	if err := writeResultColumns(f, results, rowMap); err != nil {
+		return "", err
+	}
+	if err := writeSummarySheet(f, results); err != nil {
+		return "", err
+	}
+
+	logDir := filepath.Join(outputDir, "log")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return "", fmt.Errorf("创建日志目录失败: %v", err)
+	}
+	// 启用宏的工作簿保留 .xlsm，其余统一保存为 .xlsx
+	ext := ".xlsx"
+	if strings.EqualFold(filepath.Ext(source.Path), ".xlsm") {
+		ext = ".xlsm"
+	}
+	path := filepath.Join(logDir, fmt.Sprintf("%s_结果_%s%s", source.Name(), time.Now().Format("20060102_150405"), ext))
+	if err := f.SaveAs(path); err != nil {
+		return "", fmt.Errorf("保存结果文件失败: %v", err)
+	}
+
+	log.Printf("✅ 结果已写入: %s", path)
+	return path, nil
+}
+
+// writeResultColumns 在任务表表头之后追加结果列，按行号写入每个任务的结果；
+// 成功行标绿、失败行标红，失败原因以批注形式附在"上传结果"单元格上
+func writeResultColumns(f *excelize.File, results []TaskResult, rowMap map[int]int) error {
+	sheet := taskSheetName(f)
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return fmt.Errorf("读取%s失败: %v", sheet, err)
+	}
+	firstColumn := 1
+	if len(rows) > 0 {
+		firstColumn = len(rows[0]) + 1
+	}
+
+	header := make([]interface{}, len(resultColumns))
+	for i, column := range resultColumns {
+		header[i] = column
+	}
+	if err := setRow(f, sheet, firstColumn, 1, header); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		row := result.Task.RowIndex
+		if rowMap != nil {
+			row = rowMap[row]
+		}
+		status, message := "成功", result.Error
+		switch {
+		case !result.Success:
+			status = "失败"
+		case result.ManualSchedule != "":
+			status, message = "已存草稿(需手动定时)", result.ManualSchedule
+		case result.AlreadyPublished != "":
+			status, message = "已发表(重试未再发表)", result.AlreadyPublished
+		case result.Staged:
+			status = "已填写(待手动发表)"
+		case result.UploadOnly:
+			status = "已存草稿(待补充信息)"
+		}
+		var uploadSeconds, uploadRate interface{}
+		if d, ok := uploadDuration(result); ok {
+			uploadSeconds = roundSeconds(d)
+			uploadRate = math.Round(uploadSpeed(result.UploadBytes, d)*100) / 100
+		}
+		values := []interface{}{
+			status,
+			result.ErrorCategory,
+			result.FailedStep,
+			message,
+			roundSeconds(result.Duration),
+			uploadSeconds, uploadRate,
+			result.Attempts,
+			result.ChannelName,
+			result.Location,
+			result.ScheduleAdjustment,
+			result.Task.Note,
+			result.Trace,
+			result.attemptTrace(),
+		}
+		if err := setRow(f, sheet, firstColumn, row, values); err != nil {
+			return err
+		}
+
+		color := resultSuccessColor
+		if !result.Success {
+			color = resultFailureColor
+		}
+		if err := fillRow(f, sheet, row, firstColumn+len(resultColumns)-1, color); err != nil {
+			return err
+		}
+		if !result.Success {
+			statusCell, _ := excelize.CoordinatesToCellName(firstColumn, row)
+			if err := f.AddComment(sheet, excelize.Comment{
+				Author: "uploader",
+				Cell:   statusCell,
+				Text:   fmt.Sprintf("[%s] %s", result.ErrorCategory, result.Error),
+				Width:  300,
+				Height: 120,
+			}); err != nil {
+				return fmt.Errorf("添加第%d行批注失败: %v", row, err)
+			}
+		}
+	}
+	return nil
+}
+
+// keepResultRows 删除任务表中不属于 results 的数据行，返回原行号到新行号的映射
+func keepResultRows(f *excelize.File, results []TaskResult) (map[int]int, error) {
+	sheet := taskSheetName(f)
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("读取%s失败: %v", sheet, err)
+	}
+	keep := make(map[int]bool)
+	for _, result := range results {
+		keep[result.Task.RowIndex] = true
+	}
+
+	// 从下往上删除，前面的行号不受影响
+	for row := len(rows); row >= 2; row-- {
+		if keep[row] {
+			continue
+		}
+		if err := f.RemoveRow(sheet, row); err != nil {
+			return nil, fmt.Errorf("删除第%d行失败: %v", row, err)
+		}
+	}
+
+	rowMap := make(map[int]int)
+	next := 2
+	for row := 2; row <= len(rows); row++ {
+		if keep[row] {
+			rowMap[row] = next
+			next++
+		}
+	}
+	return rowMap, nil
+}
+
+// fillRow 设置一行单元格的背景色，保留单元格原有的字体、边框和数字格式
+func fillRow(f *excelize.File, sheet string, row int, lastColumn int, color string) error {
+	for column := 1; column <= lastColumn; column++ {
+		cell, _ := excelize.CoordinatesToCellName(column, row)
+		styleID, err := f.GetCellStyle(sheet, cell)
+		if err != nil {
+			return fmt.Errorf("读取%s样式失败: %v", cell, err)
+		}
+		style, err := f.GetStyle(styleID)
+		if err != nil {
+			return fmt.Errorf("读取%s样式失败: %v", cell, err)
+		}
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}}
+		// NewStyle 会复用相同的样式，不会随行数增多
+		newID, err := f.NewStyle(style)
+		if err != nil {
+			return fmt.Errorf("创建样式失败: %v", err)
+		}
+		if err := f.SetCellStyle(sheet, cell, cell, newID); err != nil {
+			return fmt.Errorf("设置%s样式失败: %v", cell, err)
+		}
+	}
+	return nil
+}
+
+// writeSummarySheet 写入"汇总"工作表：总体统计和可直接用于制作图表的分类统计表
+func writeSummarySheet(f *excelize.File, results []TaskResult) error {
+	if index, _ := f.GetSheetIndex(resultsSummarySheet); index >= 0 {
+		if err := f.DeleteSheet(resultsSummarySheet); err != nil {
+			return fmt.Errorf("删除旧的汇总表失败: %v", err)
+		}
+	}
+	if _, err := f.NewSheet(resultsSummarySheet); err != nil {
+		return fmt.Errorf("创建汇总表失败: %v", err)
+	}
+
+	summary := summarizeResults(results)
+	table := [][]interface{}{
+		{"项目", "值"},
+		{"任务总数", summary.Total},
+		{"成功", summary.Succeeded},
+		{"失败", summary.Failed},
+		{"总耗时(秒)", roundSeconds(summary.Elapsed)},
+		{"任务累计耗时(秒)", roundSeconds(summary.TaskTime)},
+	}
+	if u := summary.Upload; u != nil {
+		table = append(table,
+			[]interface{}{"上传成功任务数", u.Count},
+			[]interface{}{"上传总大小(MB)", math.Round(float64(u.Bytes)/bytesPerMB*10) / 10},
+			[]interface{}{"平均上传耗时(秒)", roundSeconds(u.Average)},
+			[]interface{}{"P95上传耗时(秒)", roundSeconds(u.P95)},
+			[]interface{}{"平均上传速度(MB/s)", math.Round(u.Speed*100) / 100},
+		)
+	}
+	if len(summary.Campaigns) > 0 {
+		table = append(table, []interface{}{}, []interface{}{"投放活动", "成功", "失败"})
+		for _, c := range summary.Campaigns {
+			table = append(table, []interface{}{c.Name, c.Succeeded, c.Failed})
+		}
+	}
+	table = append(table,
+		[]interface{}{},
+		[]interface{}{"失败分类", "次数"},
+	)
+	for _, entry := range summary.ByCategory {
+		table = append(table, []interface{}{entry.Name, entry.Count})
+	}
+	table = append(table, []interface{}{}, []interface{}{"失败阶段", "次数"})
+	for _, entry := range summary.ByPhase {
+		table = append(table, []interface{}{entry.Name, entry.Count})
+	}
+	table = append(table, []interface{}{}, []interface{}{"高频错误", "次数"})
+	for _, entry := range summary.TopErrors {
+		table = append(table, []interface{}{entry.Name, entry.Count})
+	}
+	table = append(table, []interface{}{}, []interface{}{"步骤", "次数", "总耗时(秒)", "平均耗时(秒)", "失败次数"})
+	for _, stats := range collectStepStats(results) {
+		table = append(table, []interface{}{
+			stats.name, stats.count, roundSeconds(stats.total),
+			roundSeconds(stats.total / time.Duration(stats.count)), stats.failures,
+		})
+	}
+
+	for i, values := range table {
+		if err := setRow(f, resultsSummarySheet, 1, i+1, values); err != nil {
+			return err
+		}
+	}
+	f.SetColWidth(resultsSummarySheet, "A", "A", 40)
+	f.SetColWidth(resultsSummarySheet, "B", "E", 14)
+	return nil
+}
+
+// setRow 从指定列开始写入一行
+func setRow(f *excelize.File, sheet string, column int, row int, values []interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	cell, err := excelize.CoordinatesToCellName(column, row)
+	if err != nil {
+		return fmt.Errorf("计算单元格位置失败: %v", err)
+	}
+	if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+		return fmt.Errorf("写入%s第%d行失败: %v", sheet, row, err)
+	}
+	return nil
+}
+
+// roundSeconds 转换为保留一位小数的秒数
+func roundSeconds(d time.Duration) float64 {
+	return float64(d.Round(100*time.Millisecond)) / float64(time.Second)
+}
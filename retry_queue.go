@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 重试队列的默认设置
+const (
+	defaultRetryMaxAttempts = 5
+	defaultRetryBackoff     = 15 * time.Minute
+	defaultRetryMaxBackoff  = 6 * time.Hour
+)
+
+// RetryQueueConfig 重试队列配置（配置文件 retry_queue），为 0 时使用默认值
+type RetryQueueConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"` // 包括第一次上传在内最多执行的次数，默认 5
+	Backoff     time.Duration `yaml:"backoff"`      // 第一次失败后的等待时间，之后每次失败翻倍，默认 15m
+	MaxBackoff  time.Duration `yaml:"max_backoff"`  // 等待时间的上限，默认 6h
+}
+
+// Validate 检查重试队列配置
+func (c RetryQueueConfig) Validate() error {
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts 不能为负数")
+	}
+	if c.Backoff < 0 || c.MaxBackoff < 0 {
+		return fmt.Errorf("backoff、max_backoff 不能为负数")
+	}
+	return nil
+}
+
+// maxAttempts 最多执行的次数
+func (c RetryQueueConfig) maxAttempts() int {
+	if c.MaxAttempts > 0 {
+		return c.MaxAttempts
+	}
+	return defaultRetryMaxAttempts
+}
+
+// backoff 第 attempts 次失败后到下次重试的等待时间：backoff * 2^(attempts-1)，不超过 max_backoff
+func (c RetryQueueConfig) backoff(attempts int) time.Duration {
+	delay, limit := c.Backoff, c.MaxBackoff
+	if delay == 0 {
+		delay = defaultRetryBackoff
+	}
+	if limit == 0 {
+		limit = defaultRetryMaxBackoff
+	}
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// retryColumn 重试列的表头，放在J列之后，如"max_attempts=3 backoff=30m max_backoff=2h"，未填写的项按配置文件 retry_queue
+const retryColumn = "重试"
+
+// parseTaskRetry 解析重试列，为空时返回 nil
+func parseTaskRetry(value string) (*RetryQueueConfig, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	policy := &RetryQueueConfig{}
+	for _, field := range fields {
+		name, setting, ok := strings.Cut(field, "=")
+		var err error
+		switch name {
+		case "max_attempts":
+			policy.MaxAttempts, err = strconv.Atoi(setting)
+		case "backoff":
+			policy.Backoff, err = time.ParseDuration(setting)
+		case "max_backoff":
+			policy.MaxBackoff, err = time.ParseDuration(setting)
+		default:
+			ok = false
+		}
+		if !ok || err != nil {
+			return nil, fmt.Errorf("重试格式应为 max_attempts=次数 backoff=时长 max_backoff=时长: %s", field)
+		}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("重试设置错误: %v", err)
+	}
+	return policy, nil
+}
+
+// forTask 任务的重试设置：任务的重试列（清单中的 retry）填写的项覆盖配置文件
+func (c RetryQueueConfig) forTask(task VideoCreateTask) RetryQueueConfig {
+	policy, err := parseTaskRetry(task.Retry)
+	if err != nil || policy == nil {
+		return c
+	}
+	if policy.MaxAttempts > 0 {
+		c.MaxAttempts = policy.MaxAttempts
+	}
+	if policy.Backoff > 0 {
+		c.Backoff = policy.Backoff
+	}
+	if policy.MaxBackoff > 0 {
+		c.MaxBackoff = policy.MaxBackoff
+	}
+	return c
+}
+
+// RetryEntry 重试队列中的一个失败任务
+type RetryEntry struct {
+	Key           string          `json:"key"` // 与重复行判断相同：视频 + 发表时间
+	Task          VideoCreateTask `json:"task"`
+	SourceFile    string          `json:"source_file,omitempty"`
+	Account       string          `json:"account,omitempty"`
+	Attempts      int             `json:"attempts"`        // 已执行的次数（包括第一次上传）
+	NextAttemptAt time.Time       `json:"next_attempt_at"` // 到该时间后 retry 子命令才会重试
+	FirstFailedAt time.Time       `json:"first_failed_at"`
+	LastError     string          `json:"last_error"`
+	ErrorCategory string          `json:"error_category,omitempty"`
+	History       []TaskAttempt   `json:"history,omitempty"` // 各次运行中每次执行的记录
+}
+
+// describe 任务来源的文字描述，用于日志和通知
+func (e RetryEntry) describe() string {
+	source := ""
+	if e.SourceFile != "" {
+		source = filepath.Base(e.SourceFile) + " "
+	}
+	return fmt.Sprintf("%s第%d行 %s", source, e.Task.RowIndex, filepath.Base(e.Task.VideoPath))
+}
+
+// RetryQueue 跨运行保存的失败任务（JSON 文件，每次运行结束后更新）：
+// 失败的任务加入队列，按退避时间由 retry 子命令重试，成功后移出，达到最大次数后移出并发送 on_retry_exhausted 通知
+type RetryQueue struct {
+	mu      sync.Mutex
+	path    string
+	config  RetryQueueConfig
+	Entries []RetryEntry `json:"entries"`
+}
+
+// OpenRetryQueue 打开重试队列，文件不存在时视为空队列
+func OpenRetryQueue(path string, config RetryQueueConfig) (*RetryQueue, error) {
+	q := &RetryQueue{path: path, config: config}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开重试队列失败: %v", err)
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("解析重试队列失败: %v", err)
+	}
+	return q, nil
+}
+
+// Due 到达重试时间的任务，按重试时间排序
+func (q *RetryQueue) Due(now time.Time) []RetryEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []RetryEntry
+	for _, entry := range q.Entries {
+		if !entry.NextAttemptAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	return due
+}
+
+// NextAttemptAt 队列中最早的重试时间，队列为空时返回零值
+func (q *RetryQueue) NextAttemptAt() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var next time.Time
+	for _, entry := range q.Entries {
+		if next.IsZero() || entry.NextAttemptAt.Before(next) {
+			next = entry.NextAttemptAt
+		}
+	}
+	return next
+}
+
+// Len 队列中的任务数
+func (q *RetryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.Entries)
+}
+
+// Record 按任务结果更新队列：成功的任务移出；可重试的失败加入队列或增加次数并按退避时间安排下次重试；
+// 达到最大次数或重试也不会成功的任务移出队列，返回这些任务。q 为 nil 时不记录
+func (q *RetryQueue) Record(results []TaskResult, sourceFile string) []RetryEntry {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var retired []RetryEntry
+	for _, result := range results {
+		key := duplicateKey(result.Task)
+		index := q.find(key)
+		if result.Success {
+			if index >= 0 {
+				log.Printf("✅ %s 重试成功，已移出重试队列", q.Entries[index].describe())
+				q.remove(index)
+			}
+			continue
+		}
+
+		if index < 0 {
+			if !retryableCategory(result.ErrorCategory) {
+				continue
+			}
+			q.Entries = append(q.Entries, RetryEntry{
+				Key:           key,
+				Task:          result.Task,
+				SourceFile:    sourceFile,
+				FirstFailedAt: now,
+			})
+			index = len(q.Entries) - 1
+		}
+		entry := &q.Entries[index]
+		entry.Attempts++
+		entry.Account = result.ChannelName
+		entry.LastError = result.Error
+		entry.ErrorCategory = result.ErrorCategory
+		entry.History = append(entry.History, result.History...)
+
+		config := q.config.forTask(result.Task)
+		switch {
+		case !retryableCategory(result.ErrorCategory):
+			log.Printf("🗑️ %s 失败[%s]，重试也不会成功，已移出重试队列", entry.describe(), result.ErrorCategory)
+		case entry.Attempts >= config.maxAttempts():
+			log.Printf("🗑️ %s 已执行 %d 次仍失败，已移出重试队列", entry.describe(), entry.Attempts)
+		default:
+			entry.NextAttemptAt = now.Add(config.backoff(entry.Attempts))
+			log.Printf("🔁 %s 第%d次失败，已加入重试队列，%s 后可重试",
+				entry.describe(), entry.Attempts, entry.NextAttemptAt.Format("2006-01-02 15:04"))
+			continue
+		}
+		retired = append(retired, *entry)
+		q.remove(index)
+	}
+	return retired
+}
+
+// Retire 将任务移出队列（如视频已被删除、重试时校验失败），返回被移出的任务
+func (q *RetryQueue) Retire(key string, reason string) (RetryEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	index := q.find(key)
+	if index < 0 {
+		return RetryEntry{}, false
+	}
+	entry := q.Entries[index]
+	entry.LastError = reason
+	entry.ErrorCategory = classifyError(reason)
+	log.Printf("🗑️ %s 无法重试，已移出重试队列: %s", entry.describe(), reason)
+	q.remove(index)
+	return entry, true
+}
+
+// find 按任务 key 查找，找不到时返回 -1
+func (q *RetryQueue) find(key string) int {
+	for i, entry := range q.Entries {
+		if entry.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove 删除第 index 个任务
+func (q *RetryQueue) remove(index int) {
+	q.Entries = append(q.Entries[:index], q.Entries[index+1:]...)
+}
+
+// Save 写入队列文件（先写临时文件再替换，避免写一半），q 为 nil 时不写入
+func (q *RetryQueue) Save() error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.Entries == nil {
+		q.Entries = []RetryEntry{}
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化重试队列失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("创建重试队列目录失败: %v", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入重试队列失败: %v", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("写入重试队列失败: %v", err)
+	}
+	return nil
+}
+
+// retryTasks 校验到期的任务，按重试顺序编号；无法重试的任务（如视频已删除）移出队列并返回
+func retryTasks(queue *RetryQueue, due []RetryEntry, checkVideos bool) ([]VideoCreateTask, []RetryEntry) {
+	var (
+		tasks   []VideoCreateTask
+		retired []RetryEntry
+	)
+	for _, entry := range due {
+		body, err := json.Marshal(entry.Task)
+		if err == nil {
+			var task VideoCreateTask
+			// 与结果表中的行号一致：表头占第1行
+			if task, err = parseQueueTask(body, len(tasks)+2, checkVideos); err == nil {
+				log.Printf("🔁 第%d行: 重试 %s(已执行 %d 次，上次失败: %s)", task.RowIndex, entry.describe(), entry.Attempts, entry.LastError)
+				tasks = append(tasks, task)
+				continue
+			}
+		}
+		if entry, ok := queue.Retire(entry.Key, err.Error()); ok {
+			retired = append(retired, entry)
+		}
+	}
+	return tasks, retired
+}
+
+// retryExhaustedEvent 任务移出重试队列的通知
+func retryExhaustedEvent(entries []RetryEntry) NotifyEvent {
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%s 执行 %d 次 [%s]: %s", entry.describe(), entry.Attempts, entry.ErrorCategory, entry.LastError))
+	}
+	return NotifyEvent{
+		Type:    EventOnRetryExhausted,
+		Title:   fmt.Sprintf("%d 个任务不再重试，已移出重试队列", len(entries)),
+		Message: strings.Join(lines, "\n"),
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 任务状态
+const (
+	TaskStatusPending = "pending"
+	TaskStatusRunning = "running"
+	TaskStatusSuccess = "success"
+	TaskStatusFailed  = "failed"
+)
+
+// taskState 单个任务的运行状态
+type taskState struct {
+	RowIndex      int    `json:"row_index"`
+	VideoPath     string `json:"video_path"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	ErrorCategory string `json:"error_category,omitempty"`
+	FailedStep    string `json:"failed_step,omitempty"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// RunState 本次运行的任务状态，每个任务结束后写入状态文件，异常退出时可据此了解进度
+type RunState struct {
+	mu        sync.Mutex
+	path      string
+	StartedAt string      `json:"started_at"`
+	Tasks     []taskState `json:"tasks"`
+	Summary   *RunSummary `json:"summary,omitempty"` // 全部任务结束后写入
+}
+
+// NewRunState 创建运行状态，状态文件与日志文件放在同一目录
+func NewRunState(outputDir string, videoCreateTasks []VideoCreateTask) *RunState {
+	now := time.Now()
+	state := &RunState{
+		path: filepath.Join(outputDir, "log", fmt.Sprintf("wechat_channel_uploader_%s.state.json",
+			now.Format("20060102_150405"))),
+		StartedAt: now.Format(time.RFC3339),
+		Tasks:     make([]taskState, len(videoCreateTasks)),
+	}
+	for i, task := range videoCreateTasks {
+		state.Tasks[i] = taskState{
+			RowIndex:  task.RowIndex,
+			VideoPath: task.VideoPath,
+			Status:    TaskStatusPending,
+			UpdatedAt: state.StartedAt,
+		}
+	}
+	return state
+}
+
+// Start 标记任务开始执行
+func (s *RunState) Start(index int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Tasks[index].Status = TaskStatusRunning
+	s.Tasks[index].UpdatedAt = time.Now().Format(time.RFC3339)
+}
+
+// Finish 记录任务结果并写入状态文件
+func (s *RunState) Finish(index int, result TaskResult) {
+	s.mu.Lock()
+	state := &s.Tasks[index]
+	state.Status = TaskStatusSuccess
+	state.Error = ""
+	state.ErrorCategory = ""
+	state.FailedStep = ""
+	if !result.Success {
+		state.Status = TaskStatusFailed
+		state.Error = result.Error
+		state.ErrorCategory = result.ErrorCategory
+		state.FailedStep = result.FailedStep
+	}
+	state.UpdatedAt = time.Now().Format(time.RFC3339)
+	s.mu.Unlock()
+
+	s.Flush()
+}
+
+// SetSummary 记录运行汇总并写入状态文件
+func (s *RunState) SetSummary(summary RunSummary) {
+	s.mu.Lock()
+	s.Summary = &summary
+	s.mu.Unlock()
+
+	s.Flush()
+}
+
+// Flush 将当前状态写入状态文件（先写临时文件再替换，避免写一半）
+func (s *RunState) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		log.Printf("⚠️ 序列化运行状态失败: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		log.Printf("⚠️ 创建状态文件目录失败: %v", err)
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Printf("⚠️ 写入状态文件失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		log.Printf("⚠️ 写入状态文件失败: %v", err)
+	}
+}
+
+// runTaskSafely 执行单个任务并记录开始时间和耗时；捕获panic：记录堆栈、将任务标记为内部错误、保存状态文件后继续后续任务
+func runTaskSafely(ctx context.Context, state *RunState, options ProcessOptions, index int, videoCreateTask VideoCreateTask, run func(result *TaskResult)) (result TaskResult) {
+	state.Start(index)
+	options.Hooks.taskStarted(videoCreateTask)
+	ctx, span := tracer.Start(ctx, "task", trace.WithAttributes(taskSpanAttributes(TaskResult{Task: videoCreateTask})...))
+	result = TaskResult{Task: videoCreateTask, StartedAt: time.Now(), ctx: ctx, hooks: options.Hooks}
+	defer bindTaskLog(&result)()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("💥 第%d行任务发生内部错误: %v\n%s", videoCreateTask.RowIndex, r, debug.Stack())
+			result.Success = false
+			result.Error = fmt.Sprintf("内部错误: %v", r)
+			result.ErrorCategory = ErrorCategoryInternal
+		}
+		result.Duration = time.Since(result.StartedAt)
+		result.endAttempt()
+		result.classify()
+		state.Finish(index, result)
+
+		span.SetAttributes(taskSpanAttributes(result)...)
+		var err error
+		if !result.Success {
+			err = errors.New(result.Error)
+		}
+		endSpan(span, err)
+	}()
+	runWithTaskCommands(&result, options.Commands, run)
+	return result
+}
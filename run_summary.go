@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// topErrorCount 汇总中显示的高频错误条数
+const topErrorCount = 5
+
+// topSlowCount 汇总中显示的最慢任务和最慢步骤条数
+const topSlowCount = 5
+
+// countEntry 名称及其出现次数
+type countEntry struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// slowTask 耗时最长的任务
+type slowTask struct {
+	Row      int           `json:"row"`
+	Video    string        `json:"video"`
+	Duration time.Duration `json:"duration"`
+}
+
+// slowStep 平均耗时最长的步骤，页面改版后某个步骤变慢（如每行合集选择多出几十秒）时排在前面
+type slowStep struct {
+	Name    string        `json:"name"`
+	Count   int           `json:"count"`
+	Average time.Duration `json:"average"`
+	Max     time.Duration `json:"max"`
+}
+
+// RunSummary 本次运行的结果汇总，按失败分类、失败阶段和错误信息统计
+type RunSummary struct {
+	Total      int             `json:"total"`
+	Succeeded  int             `json:"succeeded"`
+	Failed     int             `json:"failed"`
+	Manual     int             `json:"manual_schedule,omitempty"`   // 定时失败已存草稿、需手动定时的任务，计入成功
+	Published  int             `json:"already_published,omitempty"` // 重试时发现已发表、没有再次发表的任务，计入成功
+	Retried    int             `json:"retried_succeeded,omitempty"` // 执行多次才成功的任务（崩溃恢复、重新登录后重试）
+	Elapsed    time.Duration   `json:"elapsed"`                     // 从第一个任务开始到最后一个任务结束
+	TaskTime   time.Duration   `json:"task_time"`                   // 各任务耗时之和
+	ByCategory []countEntry    `json:"by_category,omitempty"`
+	ByPhase    []countEntry    `json:"by_phase,omitempty"`
+	TopErrors  []countEntry    `json:"top_errors,omitempty"`
+	Upload     *UploadStats    `json:"upload,omitempty"`    // 文件上传耗时和速度，没有任务上传成功时为 nil
+	Campaigns  []campaignCount `json:"campaigns,omitempty"` // 按投放活动统计，没有任务填写投放活动时为空
+
+	SlowestTasks []slowTask `json:"slowest_tasks,omitempty"` // 耗时最长的任务
+	SlowestSteps []slowStep `json:"slowest_steps,omitempty"` // 平均耗时最长的步骤
+}
+
+// summarizeResults 汇总任务结果
+func summarizeResults(results []TaskResult) RunSummary {
+	summary := RunSummary{Total: len(results)}
+	categories := make(map[string]int)
+	phases := make(map[string]int)
+	errors := make(map[string]int)
+	var first, last time.Time
+	for _, result := range results {
+		if !result.StartedAt.IsZero() {
+			if first.IsZero() || result.StartedAt.Before(first) {
+				first = result.StartedAt
+			}
+			if end := result.StartedAt.Add(result.Duration); end.After(last) {
+				last = end
+			}
+			summary.TaskTime += result.Duration
+		}
+		if result.Success {
+			summary.Succeeded++
+			if result.ManualSchedule != "" {
+				summary.Manual++
+			}
+			if result.AlreadyPublished != "" {
+				summary.Published++
+			}
+			if result.Attempts > 1 {
+				summary.Retried++
+			}
+			continue
+		}
+		summary.Failed++
+
+		category := result.ErrorCategory
+		if category == "" {
+			category = classifyError(result.Error)
+		}
+		categories[category]++
+		phase := stepPhase(result.FailedStep)
+		if phase == "" {
+			phase = "unknown"
+		}
+		phases[phase]++
+		errors[result.Error]++
+	}
+
+	if !first.IsZero() {
+		summary.Elapsed = last.Sub(first)
+	}
+	summary.ByCategory = sortedCounts(categories, 0)
+	summary.ByPhase = sortedCounts(phases, 0)
+	summary.TopErrors = sortedCounts(errors, topErrorCount)
+	summary.Upload = collectUploadStats(results)
+	summary.Campaigns = countCampaigns(results)
+	summary.SlowestTasks = slowestTasks(results, topSlowCount)
+	summary.SlowestSteps = slowestSteps(results, topSlowCount)
+	return summary
+}
+
+// slowestTasks 耗时最长的 limit 个任务，没有执行的任务除外
+func slowestTasks(results []TaskResult, limit int) []slowTask {
+	var tasks []slowTask
+	for _, result := range results {
+		if result.Duration > 0 {
+			tasks = append(tasks, slowTask{Row: result.Task.RowIndex, Video: filepath.Base(result.Task.VideoPath), Duration: result.Duration})
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Duration > tasks[j].Duration })
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks
+}
+
+// slowestSteps 平均耗时最长的 limit 个步骤
+func slowestSteps(results []TaskResult, limit int) []slowStep {
+	var steps []slowStep
+	for _, stats := range collectStepStats(results) {
+		steps = append(steps, slowStep{Name: stats.name, Count: stats.count, Average: stats.total / time.Duration(stats.count), Max: stats.max})
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Average > steps[j].Average })
+	if len(steps) > limit {
+		steps = steps[:limit]
+	}
+	return steps
+}
+
+// sortedCounts 按次数从多到少排序，limit 大于0时只保留前 limit 条
+func sortedCounts(counts map[string]int, limit int) []countEntry {
+	entries := make([]countEntry, 0, len(counts))
+	for name, count := range counts {
+		entries = append(entries, countEntry{Name: name, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// Lines 汇总的文本形式，控制台、日志文件和通知共用
+func (s RunSummary) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("总计: %d 成功, %d 失败", s.Succeeded, s.Failed),
+		fmt.Sprintf("总耗时: %v, 任务累计耗时: %v", s.Elapsed.Round(time.Second), s.TaskTime.Round(time.Second)),
+	}
+	if u := s.Upload; u != nil {
+		lines = append(lines, fmt.Sprintf("文件上传: %d 个任务共 %.1fMB, 平均耗时: %v, P95: %v, 平均速度: %.2fMB/s",
+			u.Count, float64(u.Bytes)/bytesPerMB, u.Average.Round(100*time.Millisecond), u.P95.Round(100*time.Millisecond), u.Speed))
+	}
+	if len(s.Campaigns) > 0 {
+		parts := make([]string, 0, len(s.Campaigns))
+		for _, c := range s.Campaigns {
+			parts = append(parts, fmt.Sprintf("%s %d成功/%d失败", c.Name, c.Succeeded, c.Failed))
+		}
+		lines = append(lines, "投放活动: "+strings.Join(parts, ", "))
+	}
+	if len(s.SlowestTasks) > 0 {
+		parts := make([]string, 0, len(s.SlowestTasks))
+		for _, t := range s.SlowestTasks {
+			parts = append(parts, fmt.Sprintf("第%d行 %s %v", t.Row, t.Video, t.Duration.Round(time.Second)))
+		}
+		lines = append(lines, "最慢的任务: "+strings.Join(parts, ", "))
+	}
+	if len(s.SlowestSteps) > 0 {
+		parts := make([]string, 0, len(s.SlowestSteps))
+		for _, step := range s.SlowestSteps {
+			parts = append(parts, fmt.Sprintf("%s 平均%v(最长%v, %d次)", step.Name,
+				step.Average.Round(100*time.Millisecond), step.Max.Round(100*time.Millisecond), step.Count))
+		}
+		lines = append(lines, "最慢的步骤: "+strings.Join(parts, ", "))
+	}
+	if s.Manual > 0 {
+		lines = append(lines, fmt.Sprintf("需手动定时: %d 个任务定时发表失败，已保存草稿", s.Manual))
+	}
+	if s.Retried > 0 {
+		lines = append(lines, fmt.Sprintf("重试后成功: %d 个任务执行多次才成功（见结果中的执行记录）", s.Retried))
+	}
+	if s.Published > 0 {
+		lines = append(lines, fmt.Sprintf("已发表: %d 个重试任务在作品管理中已有相同作品，没有再次发表", s.Published))
+	}
+	if s.Failed == 0 {
+		return lines
+	}
+	lines = append(lines, "失败分类: "+joinCounts(s.ByCategory))
+	lines = append(lines, "失败阶段: "+joinCounts(s.ByPhase))
+	lines = append(lines, "高频错误:")
+	for _, entry := range s.TopErrors {
+		lines = append(lines, fmt.Sprintf("  %d次 %s", entry.Count, entry.Name))
+	}
+	return lines
+}
+
+// joinCounts 将统计拼成 "名称 次数, ..." 的形式
+func joinCounts(entries []countEntry) string {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		parts = append(parts, fmt.Sprintf("%s %d", entry.Name, entry.Count))
+	}
+	return strings.Join(parts, ", ")
+}
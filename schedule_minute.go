@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 时间选择器中没有指定分钟时的处理方式
+const (
+	MinuteRoundCeil  = "ceil"  // 取之后最近的可选分钟（默认，不早于指定时间发表）
+	MinuteRoundFloor = "floor" // 取之前最近的可选分钟
+	MinuteRoundFail  = "fail"  // 任务失败
+)
+
+// 设置定时发表失败时的处理方式
+const (
+	ScheduleFallbackFail  = "fail"  // 任务失败（默认）
+	ScheduleFallbackDraft = "draft" // 取消定时并保存草稿，结果标记为需手动定时
+)
+
+// ScheduleConfig 定时发表设置
+type ScheduleConfig struct {
+	MinuteRounding string        `yaml:"minute_rounding"` // ceil | floor | fail，为空时为 ceil
+	Fallback       string        `yaml:"fallback"`        // fail | draft，为空时为 fail
+	MinLead        time.Duration `yaml:"min_lead"`        // 定时时间至少晚于当前时间多久，默认 0
+	MaxAhead       time.Duration `yaml:"max_ahead"`       // 定时时间最多晚于当前时间多久，为 0 时为平台的 30 天（720h）
+}
+
+// validateScheduleFallback 校验设置定时发表失败时的处理方式
+func validateScheduleFallback(fallback string) error {
+	switch fallback {
+	case "", ScheduleFallbackFail, ScheduleFallbackDraft:
+		return nil
+	}
+	return fmt.Errorf("不支持的定时失败处理方式: %s(可选 fail、draft)", fallback)
+}
+
+// minuteSelection 选择分钟的方式，以及分钟被调整时的回调
+type minuteSelection struct {
+	Rounding string
+	OnAdjust func(requested int, actual int) // 可为空
+}
+
+// validateMinuteRounding 校验分钟调整方式
+func validateMinuteRounding(rounding string) error {
+	switch rounding {
+	case "", MinuteRoundCeil, MinuteRoundFloor, MinuteRoundFail:
+		return nil
+	}
+	return fmt.Errorf("不支持的分钟调整方式: %s(可选 ceil、floor、fail)", rounding)
+}
+
+// roundMinute 按调整方式从可选分钟中选出最接近 minute 的值
+func roundMinute(minute int, available []int, rounding string) (int, error) {
+	sorted := append([]int(nil), available...)
+	sort.Ints(sorted)
+	for _, value := range sorted {
+		if value == minute {
+			return minute, nil
+		}
+	}
+	if len(sorted) == 0 {
+		return 0, fmt.Errorf("未找到可选的分钟")
+	}
+
+	switch rounding {
+	case "", MinuteRoundCeil:
+		for _, value := range sorted {
+			if value > minute {
+				return value, nil
+			}
+		}
+		return 0, fmt.Errorf("分钟 %02d 之后没有可选分钟(最大 %02d)，请调整定时时间或使用 floor", minute, sorted[len(sorted)-1])
+	case MinuteRoundFloor:
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if sorted[i] < minute {
+				return sorted[i], nil
+			}
+		}
+		return 0, fmt.Errorf("分钟 %02d 之前没有可选分钟(最小 %02d)，请调整定时时间或使用 ceil", minute, sorted[0])
+	case MinuteRoundFail:
+		return 0, fmt.Errorf("未找到分钟选项: %02d", minute)
+	default:
+		return 0, validateMinuteRounding(rounding)
+	}
+}
+
+// availableMinutes 读取时间选择器中可选的分钟
+func availableMinutes(page playwright.Page) ([]int, error) {
+	texts, err := page.Locator(".weui-desktop-picker__time__minute li:not(.weui-desktop-picker__disabled)").AllTextContents()
+	if err != nil {
+		return nil, fmt.Errorf("读取分钟选项失败: %v", err)
+	}
+	var minutes []int
+	for _, text := range texts {
+		if minute, err := strconv.Atoi(strings.TrimSpace(text)); err == nil {
+			minutes = append(minutes, minute)
+		}
+	}
+	return minutes, nil
+}
+
+// resolveMinute 确定实际选择的分钟，指定分钟不可选时按调整方式取最近的可选值
+func resolveMinute(page playwright.Page, minute int, selection minuteSelection) (int, error) {
+	available, err := availableMinutes(page)
+	if err != nil {
+		return 0, err
+	}
+	actual, err := roundMinute(minute, available, selection.Rounding)
+	if err != nil {
+		return 0, err
+	}
+	if actual != minute {
+		log.Printf("⚠️ 时间选择器没有 %02d 分，按 %s 调整为 %02d 分", minute, selection.Rounding, actual)
+		if selection.OnAdjust != nil {
+			selection.OnAdjust(minute, actual)
+		}
+	}
+	return actual, nil
+}
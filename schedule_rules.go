@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultScheduleMaxAhead 平台允许定时发表的最远时间
+const defaultScheduleMaxAhead = 30 * 24 * time.Hour
+
+// scheduleRules 本次运行校验定时时间的规则，启动时按配置文件 schedule 和命令行参数设置；
+// Excel、CSV、JSON、数据库和队列中的任务都在解析时校验，执行前再校验一次
+var scheduleRules = newScheduleValidator(ScheduleConfig{})
+
+// scheduleValidator 定时发表的规则：定时时间需晚于当前时间 minLead、不超过 maxAhead，且不能只保存草稿
+type scheduleValidator struct {
+	minLead  time.Duration
+	maxAhead time.Duration
+}
+
+// newScheduleValidator 按配置创建定时规则，max_ahead 为 0 时为平台的 30 天
+func newScheduleValidator(config ScheduleConfig) scheduleValidator {
+	v := scheduleValidator{minLead: config.MinLead, maxAhead: config.MaxAhead}
+	if v.maxAhead == 0 {
+		v.maxAhead = defaultScheduleMaxAhead
+	}
+	return v
+}
+
+// validateScheduleWindow 校验定时时间范围的配置
+func validateScheduleWindow(config ScheduleConfig) error {
+	if config.MinLead < 0 || config.MaxAhead < 0 {
+		return fmt.Errorf("定时时间范围不能为负数")
+	}
+	v := newScheduleValidator(config)
+	if v.minLead >= v.maxAhead {
+		return fmt.Errorf("min_lead(%v) 需要小于 max_ahead(%v)", v.minLead, v.maxAhead)
+	}
+	return nil
+}
+
+// Validate 校验任务的定时设置，定时发表时返回统一为 年/月/日 时:分 格式的定时时间，不定时时原样返回
+func (v scheduleValidator) Validate(schedule bool, scheduleTime string, action string, now time.Time) (string, error) {
+	if !schedule {
+		return scheduleTime, nil
+	}
+	if scheduleTime == "" {
+		return scheduleTime, fmt.Errorf("定时发表时定时时间不能为空")
+	}
+	if action == "save_draft" {
+		return scheduleTime, fmt.Errorf("定时发表方式必须以发表方式保存")
+	}
+	targetTime, err := parseScheduleTime(scheduleTime)
+	if err != nil {
+		return scheduleTime, err
+	}
+	earliest, latest := now.Add(v.minLead), now.Add(v.maxAhead)
+	if targetTime.Before(earliest) || targetTime.After(latest) {
+		return scheduleTime, fmt.Errorf("定时时间超出范围: 需要在 %s 到 %s 之间: %s",
+			earliest.Format("2006/01/02 15:04"), latest.Format("2006/01/02 15:04"), scheduleTime)
+	}
+	return targetTime.Format("2006/01/02 15:04"), nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// scheduleDisplayLayout 比较定时时间时使用的格式，只比较到分钟
+const scheduleDisplayLayout = "2006/01/02 15:04"
+
+// scheduledTimePattern 匹配页面上显示的定时时间，如 2025/11/20 09:30、2025-11-20 09:30、2025年11月20日 09:30
+var scheduledTimePattern = regexp.MustCompile(`\d{4}\s*[/\-年]\s*\d{1,2}\s*[/\-月]\s*\d{1,2}\s*日?\s+\d{1,2}:\d{2}`)
+
+// postItemSelectors 作品管理列表中的作品
+var postItemSelectors = []string{
+	".post-feed-item",
+	"[class*='post-feed-item']",
+	"[class*='post-item']",
+}
+
+// verifyScheduleInput 读取日期输入框中最终显示的定时时间，与期望不一致时返回错误
+func verifyScheduleInput(page playwright.Page, expected time.Time) (time.Time, error) {
+	value, err := page.Locator("input[placeholder='请选择发表时间']").First().InputValue()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("定时时间校验失败: 读取日期输入框失败: %v", err)
+	}
+	displayed, err := parseDisplayedSchedule(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("定时时间校验失败: 日期输入框显示 %q: %v", value, err)
+	}
+	if !sameScheduleMinute(displayed, expected) {
+		return displayed, fmt.Errorf("定时时间校验失败: 期望 %s，日期输入框显示 %s",
+			expected.Format(scheduleDisplayLayout), displayed.Format(scheduleDisplayLayout))
+	}
+	log.Printf("✅ 日期输入框显示的定时时间正确: %s", displayed.Format(scheduleDisplayLayout))
+	return displayed, nil
+}
+
+// verifyScheduledPost 发表后打开作品管理，找到刚发表的作品并核对列表中显示的定时时间；
+// 列表中找不到作品时只记录警告，找到且时间不一致时返回错误
+func verifyScheduledPost(page playwright.Page, description string, expected time.Time) error {
+	keyword := postKeyword(description)
+	if keyword == "" {
+		log.Println("⚠️ 描述为空，无法在作品管理中查找作品，跳过定时时间核对")
+		return nil
+	}
+
+	log.Printf("🔍 在作品管理中核对定时时间: %s", expected.Format(scheduleDisplayLayout))
+	// 刚发表的作品可能稍后才出现在列表中
+	for attempt := 1; attempt <= 3; attempt++ {
+		if err := navigateWithRetry(page, platformURLs.PostListPage()); err != nil {
+			return fmt.Errorf("打开作品管理失败: %v", err)
+		}
+		time.Sleep(3 * time.Second)
+
+		text, found := findPostText(page, keyword)
+		if found {
+			match := scheduledTimePattern.FindString(text)
+			if match == "" {
+				log.Printf("⚠️ 作品管理中的作品没有显示定时时间，跳过核对: %s", keyword)
+				return nil
+			}
+			listed, err := parseDisplayedSchedule(match)
+			if err != nil {
+				return fmt.Errorf("定时时间校验失败: 作品管理显示 %q: %v", match, err)
+			}
+			if !sameScheduleMinute(listed, expected) {
+				return fmt.Errorf("定时时间校验失败: 期望 %s，作品管理显示 %s",
+					expected.Format(scheduleDisplayLayout), listed.Format(scheduleDisplayLayout))
+			}
+			log.Printf("✅ 作品管理显示的定时时间正确: %s", listed.Format(scheduleDisplayLayout))
+			return nil
+		}
+		log.Printf("⏳ 作品管理中暂未找到作品，稍后重试 (%d/3)", attempt)
+	}
+	log.Printf("⚠️ 作品管理中未找到作品，无法核对定时时间: %s", keyword)
+	return nil
+}
+
+// findPostText 返回作品管理列表中第一个包含关键字的作品的文本
+func findPostText(page playwright.Page, keyword string) (string, bool) {
+	for _, selector := range postItemSelectors {
+		items := page.Locator(selector).Filter(playwright.LocatorFilterOptions{HasText: keyword})
+		if count, _ := items.Count(); count == 0 {
+			continue
+		}
+		text, err := items.First().TextContent()
+		if err != nil {
+			continue
+		}
+		return strings.Join(strings.Fields(text), " "), true
+	}
+	return "", false
+}
+
+// postKeyword 用描述的第一行前20个字符在作品列表中查找作品，列表中的描述可能被截断
+func postKeyword(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) > 20 {
+		runes = runes[:20]
+	}
+	return string(runes)
+}
+
+// parseDisplayedSchedule 解析页面上显示的定时时间
+func parseDisplayedSchedule(value string) (time.Time, error) {
+	value = strings.NewReplacer("年", "/", "月", "/", "日", " ", "-", "/").Replace(value)
+	return parseScheduleTime(value)
+}
+
+// sameScheduleMinute 两个时间按显示的日期和钟点比较到分钟
+func sameScheduleMinute(a time.Time, b time.Time) bool {
+	return a.Format(scheduleDisplayLayout) == b.Format(scheduleDisplayLayout)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 任务表和配置文件的格式版本：列或配置项的位置、含义变化时版本加一，并补充从上一版本升级的迁移，
+// 避免旧的任务表和配置文件在新版本中被静默地按错误的列或配置项读取
+
+// taskSheetVersion 当前任务表版本，记录在模板的自定义文档属性中：
+//
+//	1 - 未记录版本的任务表（包括 CSV 和旧版 .xls），A-J列按位置读取
+//	2 - A-J列的表头与位置一致，J列之后按表头识别内容类型、音乐和自定义表单步骤的列
+const taskSheetVersion = 2
+
+// taskSheetVersionProperty 记录任务表版本的自定义文档属性
+const taskSheetVersionProperty = "WechatUploaderSchema"
+
+// configVersion 当前配置文件版本，对应配置文件的 version 字段，未填写时为 1
+const configVersion = 1
+
+// taskSheetMigrations 任务表迁移：taskSheetMigrations[v] 把 v 版本的行升级到 v+1
+var taskSheetMigrations = map[int]func(rows [][]string) [][]string{
+	1: alignTaskColumns,
+}
+
+// configMigrations 配置文件迁移：configMigrations[v] 把 v 版本的配置升级到 v+1，配置项改名时在这里补充
+var configMigrations = map[int]func(doc map[string]interface{}) error{}
+
+// taskSheetFileVersion 读取Excel任务表记录的版本，旧版 .xls 和未记录版本时为 1
+func taskSheetFileVersion(path string) (int, error) {
+	format, err := sniffWorkbookFormat(path)
+	if err != nil {
+		return 0, err
+	}
+	if format == workbookFormatBIFF {
+		return 1, nil
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("打开Excel文件失败: %v", err)
+	}
+	defer f.Close()
+
+	props, err := f.GetCustomProps()
+	if err != nil {
+		return 0, fmt.Errorf("读取任务表版本失败: %v", err)
+	}
+	for _, prop := range props {
+		if prop.Name != taskSheetVersionProperty {
+			continue
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(prop.Value)))
+		if err != nil || version < 1 {
+			return 0, fmt.Errorf("任务表版本格式错误: %v", prop.Value)
+		}
+		return version, nil
+	}
+	return 1, nil
+}
+
+// migrateTaskRows 把旧版本任务表的行升级到当前版本，并检查A-J列的表头与位置是否一致
+func migrateTaskRows(rows [][]string, version int) ([][]string, error) {
+	if len(rows) == 0 {
+		return rows, nil
+	}
+	if version > taskSheetVersion {
+		return nil, fmt.Errorf("任务表版本为 %d，高于程序支持的版本 %d，请升级程序", version, taskSheetVersion)
+	}
+	for v := version; v < taskSheetVersion; v++ {
+		if migrate, ok := taskSheetMigrations[v]; ok {
+			rows = migrate(rows)
+		}
+	}
+
+	var mismatched []string
+	for i, name := range taskColumns {
+		header := ""
+		if i < len(rows[0]) {
+			header = strings.TrimSpace(rows[0][i])
+		}
+		if header != name {
+			mismatched = append(mismatched, fmt.Sprintf("%c列应为\"%s\"，实际为\"%s\"", 'A'+i, name, header))
+		}
+	}
+	if len(mismatched) > 0 {
+		return nil, fmt.Errorf("任务表表头与版本 %d 的模板不一致: %s；请按模板调整列的顺序，不要在J列之前插入或删除列",
+			taskSheetVersion, strings.Join(mismatched, "，"))
+	}
+	return rows, nil
+}
+
+// alignTaskColumns 版本 1 → 2：按表头把A-J列调整到模板中的位置，找不到表头的列按原位置读取，
+// 其余列移到J列之后，避免插入、删除或调换列后被静默地按错误的列读取
+func alignTaskColumns(rows [][]string) [][]string {
+	headers := rows[0]
+	known := make(map[string]bool, len(taskColumns))
+	for _, name := range taskColumns {
+		known[name] = true
+	}
+	index := make(map[string]int)
+	for i, header := range headers {
+		name := strings.TrimSpace(header)
+		if _, ok := index[name]; !ok && name != "" {
+			index[name] = i
+		}
+	}
+
+	// 每个任务列取自原表的哪一列，-1 表示缺少该列
+	sources := make([]int, len(taskColumns))
+	used := make(map[int]bool)
+	changed := false
+	for i, name := range taskColumns {
+		sources[i] = -1
+		if j, ok := index[name]; ok {
+			sources[i] = j
+			used[j] = true
+			if j != i {
+				changed = true
+				log.Printf("🔧 任务表的\"%s\"列在%s，已按表头调整到%c列", name, columnLetter(j), 'A'+i)
+			}
+		}
+	}
+	for i, name := range taskColumns {
+		if sources[i] >= 0 {
+			continue
+		}
+		header := ""
+		if i < len(headers) {
+			header = strings.TrimSpace(headers[i])
+		}
+		if !used[i] && !known[header] {
+			sources[i] = i
+			used[i] = true
+			changed = true
+			if header != "" {
+				log.Printf("⚠️ 任务表中没有\"%s\"列，按%c列(表头\"%s\")读取", name, 'A'+i, header)
+			}
+			continue
+		}
+		changed = true
+		log.Printf("⚠️ 任务表中没有\"%s\"列，按空值处理", name)
+	}
+	if !changed {
+		return rows
+	}
+	log.Printf("💡 任务表的列与模板不一致，已按表头自动调整；建议复制新版模板 channel-video-uploader.xlsx 并填入任务")
+
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	var rest []int
+	for j := 0; j < width; j++ {
+		if !used[j] {
+			rest = append(rest, j)
+		}
+	}
+
+	aligned := make([][]string, len(rows))
+	for r, row := range rows {
+		cell := func(j int) string {
+			if j >= 0 && j < len(row) {
+				return row[j]
+			}
+			return ""
+		}
+		out := make([]string, 0, len(taskColumns)+len(rest))
+		for i, j := range sources {
+			if r == 0 {
+				out = append(out, taskColumns[i])
+			} else {
+				out = append(out, cell(j))
+			}
+		}
+		for _, j := range rest {
+			out = append(out, cell(j))
+		}
+		// 去掉行尾的空单元格，与 excelize 的 GetRows 保持一致
+		for len(out) > 0 && out[len(out)-1] == "" {
+			out = out[:len(out)-1]
+		}
+		aligned[r] = out
+	}
+	return aligned
+}
+
+// columnLetter 列序号（从 0 开始）对应的列名
+func columnLetter(index int) string {
+	name, err := excelize.ColumnNumberToName(index + 1)
+	if err != nil {
+		return strconv.Itoa(index + 1)
+	}
+	return name + "列"
+}
+
+// migrateConfig 检查配置文件版本，旧版本按 configMigrations 升级后返回新的内容
+func migrateConfig(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	version := 1
+	if value, ok := doc["version"]; ok {
+		if version, ok = value.(int); !ok || version < 1 {
+			return nil, fmt.Errorf("配置文件 version 必须是正整数")
+		}
+	}
+	if version > configVersion {
+		return nil, fmt.Errorf("配置文件版本为 %d，高于程序支持的版本 %d，请升级程序", version, configVersion)
+	}
+	if version == configVersion {
+		return data, nil
+	}
+	for v := version; v < configVersion; v++ {
+		if migrate, ok := configMigrations[v]; ok {
+			if err := migrate(doc); err != nil {
+				return nil, fmt.Errorf("配置文件从版本 %d 升级失败: %v", v, err)
+			}
+		}
+	}
+	doc["version"] = configVersion
+	log.Printf("🔧 配置文件已从版本 %d 升级到 %d，建议按上面的提示修改配置文件并设置 version: %d", version, configVersion, configVersion)
+	return yaml.Marshal(doc)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SelectorChain 同一个元素的多个候选选择器：第一个为主选择器，页面改版后依次尝试后面的备用选择器，
+// 命中的选择器记入 selectorStats
+type SelectorChain struct {
+	Name      string   // 统计中的名称
+	Selectors []string // 以 // 开头的按 xpath 处理
+}
+
+// locator 返回第 index 个选择器的定位器
+func (c SelectorChain) locator(page playwright.Page, index int) playwright.Locator {
+	selector := c.Selectors[index]
+	if strings.HasPrefix(selector, "//") {
+		return page.Locator("xpath=" + selector)
+	}
+	return page.Locator(selector)
+}
+
+// Find 返回第一个在页面上有元素的选择器的定位器
+func (c SelectorChain) Find(page playwright.Page) (playwright.Locator, error) {
+	return c.find(page, func(locator playwright.Locator) bool {
+		count, err := locator.Count()
+		return err == nil && count > 0
+	})
+}
+
+// FindVisible 返回第一个元素可见的选择器的定位器（取第一个元素）
+func (c SelectorChain) FindVisible(page playwright.Page) (playwright.Locator, error) {
+	locator, err := c.find(page, func(locator playwright.Locator) bool {
+		visible, err := locator.First().IsVisible()
+		return err == nil && visible
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locator.First(), nil
+}
+
+func (c SelectorChain) find(page playwright.Page, match func(playwright.Locator) bool) (playwright.Locator, error) {
+	for i := range c.Selectors {
+		locator := c.locator(page, i)
+		if match(locator) {
+			selectorStats.record(c, i)
+			return locator, nil
+		}
+	}
+	selectorStats.record(c, -1)
+	return nil, fmt.Errorf("未找到%s元素: %s", c.Name, strings.Join(c.Selectors, ", "))
+}
+
+// Try 依次对页面上有元素的选择器执行 fn，直到某个选择器成功，全部失败时返回最后一个错误
+func (c SelectorChain) Try(page playwright.Page, fn func(locator playwright.Locator) error) error {
+	lastErr := fmt.Errorf("未找到%s元素: %s", c.Name, strings.Join(c.Selectors, ", "))
+	for i := range c.Selectors {
+		locator := c.locator(page, i)
+		if count, err := locator.Count(); err != nil || count == 0 {
+			continue
+		}
+		if err := fn(locator); err != nil {
+			lastErr = err
+			continue
+		}
+		selectorStats.record(c, i)
+		return nil
+	}
+	selectorStats.record(c, -1)
+	return lastErr
+}
+
+// Text 返回第一个文本不为空的元素的文本（去掉首尾空白）
+func (c SelectorChain) Text(page playwright.Page) (string, error) {
+	var text string
+	err := c.Try(page, func(locator playwright.Locator) error {
+		content, err := locator.First().TextContent()
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(content) == "" {
+			return fmt.Errorf("%s元素的文本为空", c.Name)
+		}
+		text = strings.TrimSpace(content)
+		return nil
+	})
+	return text, err
+}
+
+// chainStats 单个选择器链的累计命中情况
+type chainStats struct {
+	Primary         string         `json:"primary"`                     // 统计时的主选择器，变更后重新统计
+	Hits            map[string]int `json:"hits"`                        // 各选择器命中次数
+	Misses          int            `json:"misses"`                      // 所有选择器都未命中的次数
+	LastPrimaryHit  time.Time      `json:"last_primary_hit,omitempty"`  // 主选择器最近一次命中
+	LastFallbackHit time.Time      `json:"last_fallback_hit,omitempty"` // 备用选择器最近一次命中
+	LastFallback    string         `json:"last_fallback,omitempty"`     // 最近一次命中的备用选择器
+}
+
+// SelectorStats 各选择器链的命中统计，跨运行累计保存到文件，为 nil 时不记录
+type SelectorStats struct {
+	mu     sync.Mutex
+	path   string
+	chains map[string]*chainStats
+	warned map[string]bool // 本次运行已提示过主选择器未命中的链
+}
+
+// selectorStats 当前运行的选择器统计，由 OpenSelectorStats 设置
+var selectorStats *SelectorStats
+
+// OpenSelectorStats 读取累计的选择器统计，并提示主选择器在最近的运行中已不再命中的链
+func OpenSelectorStats(path string) *SelectorStats {
+	s := &SelectorStats{path: path, chains: make(map[string]*chainStats), warned: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &s.chains); err != nil {
+			log.Printf("⚠️ 选择器统计文件格式错误，重新统计: %v", err)
+			s.chains = make(map[string]*chainStats)
+		}
+	} else if !os.IsNotExist(err) {
+		log.Printf("⚠️ 读取选择器统计失败: %v", err)
+	}
+	for _, name := range s.staleChains() {
+		stats := s.chains[name]
+		log.Printf("⚠️ 选择器 %s 的主选择器在最近的运行中未命中，最近使用的备用选择器: %s (%s)，页面可能已改版",
+			name, stats.LastFallback, stats.LastFallbackHit.Format("2006-01-02 15:04"))
+	}
+	return s
+}
+
+// staleChains 返回最近一次命中的是备用选择器的链
+func (s *SelectorStats) staleChains() []string {
+	var names []string
+	for name, stats := range s.chains {
+		if stats.LastFallbackHit.After(stats.LastPrimaryHit) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// record 记录命中的选择器，index 为 -1 表示全部未命中；本次运行第一次使用备用选择器时提示
+func (s *SelectorStats) record(chain SelectorChain, index int) {
+	if s == nil || len(chain.Selectors) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.chains[chain.Name]
+	if stats == nil || stats.Primary != chain.Selectors[0] {
+		stats = &chainStats{Primary: chain.Selectors[0], Hits: make(map[string]int)}
+		s.chains[chain.Name] = stats
+	}
+	if stats.Hits == nil {
+		stats.Hits = make(map[string]int)
+	}
+	switch {
+	case index < 0:
+		stats.Misses++
+	case index == 0:
+		stats.Hits[chain.Selectors[0]]++
+		stats.LastPrimaryHit = time.Now()
+	default:
+		selector := chain.Selectors[index]
+		stats.Hits[selector]++
+		stats.LastFallbackHit = time.Now()
+		stats.LastFallback = selector
+		if !s.warned[chain.Name] {
+			s.warned[chain.Name] = true
+			log.Printf("⚠️ 选择器 %s 的主选择器未命中，使用了备用选择器 #%d: %s", chain.Name, index+1, selector)
+		}
+	}
+}
+
+// Save 保存累计的统计
+func (s *SelectorStats) Save() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.MarshalIndent(s.chains, "", "  ")
+	if err == nil {
+		err = os.WriteFile(s.path, data, 0644)
+	}
+	if err != nil {
+		log.Printf("⚠️ 保存选择器统计失败: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// selectorPackTimeout 下载选择器包的超时时间
+const selectorPackTimeout = 15 * time.Second
+
+// selectorPackUsage selectors 子命令用法
+const selectorPackUsage = "用法: selectors export|keygen|sign [参数]"
+
+// builtinChains 可由选择器包替换的选择器链
+var builtinChains = []*SelectorChain{
+	&fileInputChain,
+	&shortTitleChain,
+	&timingChain,
+	&locationDisplayChain,
+	&musicOpenChain,
+	&musicSearchChain,
+	&musicResultChain,
+	&musicConfirmChain,
+	&qrCodeChain,
+	&channelNameChain,
+	&loggedInChain,
+	&previewQRChain,
+	&coverEditChain,
+	&coverCropBoxChain,
+	&coverCropAreaChain,
+	&coverUploadChain,
+	&coverConfirmChain,
+	&draftTabChain,
+	&draftItemChain,
+}
+
+// SelectorPackConfig 选择器包的下载地址和验证签名的公钥
+type SelectorPackConfig struct {
+	URL       string `yaml:"url"`        // 选择器包地址，签名文件为该地址加 .sig
+	PublicKey string `yaml:"public_key"` // Ed25519 公钥（base64）
+}
+
+// SelectorPack 选择器包：按名称替换内置选择器链的候选选择器，平台页面改版后无需等待新版本
+type SelectorPack struct {
+	Version string              `json:"version"`
+	Chains  map[string][]string `json:"chains"`
+}
+
+// Validate 校验选择器包配置
+func (c SelectorPackConfig) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	if _, err := c.publicKey(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// publicKey 解析公钥
+func (c SelectorPackConfig) publicKey() (ed25519.PublicKey, error) {
+	if c.PublicKey == "" {
+		return nil, fmt.Errorf("配置了 url 时必须配置 public_key")
+	}
+	key, err := base64.StdEncoding.DecodeString(c.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public_key 不是有效的 Ed25519 公钥(base64)")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// UpdateSelectorPack 下载选择器包并验证签名，成功后缓存到 cachePath 并替换内置选择器；
+// 下载或验证失败时使用上次缓存的选择器包，都不可用时使用内置选择器
+func UpdateSelectorPack(config SelectorPackConfig, cachePath string) {
+	if config.URL == "" {
+		return
+	}
+	publicKey, err := config.publicKey()
+	if err != nil {
+		log.Printf("⚠️ 选择器包配置错误: %v", err)
+		return
+	}
+
+	data, signature, err := downloadSelectorPack(config.URL)
+	if err == nil {
+		err = verifySelectorPack(publicKey, data, signature)
+	}
+	if err == nil {
+		if err := writeSelectorPackCache(cachePath, data, signature); err != nil {
+			log.Printf("⚠️ 缓存选择器包失败: %v", err)
+		}
+	} else {
+		log.Printf("⚠️ 更新选择器包失败，使用上次缓存的选择器包: %v", err)
+		if data, signature, err = readSelectorPackCache(cachePath); err == nil {
+			err = verifySelectorPack(publicKey, data, signature)
+		}
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("⚠️ 缓存的选择器包不可用，使用内置选择器: %v", err)
+			}
+			return
+		}
+	}
+
+	var pack SelectorPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		log.Printf("⚠️ 选择器包格式错误，使用内置选择器: %v", err)
+		return
+	}
+	applySelectorPack(pack)
+}
+
+// downloadSelectorPack 下载选择器包和签名
+func downloadSelectorPack(url string) ([]byte, []byte, error) {
+	client := &http.Client{Timeout: selectorPackTimeout}
+	data, err := httpGetBody(client, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := httpGetBody(client, url+".sig")
+	if err != nil {
+		return nil, nil, fmt.Errorf("下载签名失败: %v", err)
+	}
+	return data, signature, nil
+}
+
+// httpGetBody 读取 GET 请求的响应内容，状态码不是 200 时返回错误
+func httpGetBody(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s 返回状态码 %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// verifySelectorPack 验证签名，签名文件内容为 base64 编码的 Ed25519 签名
+func verifySelectorPack(publicKey ed25519.PublicKey, data []byte, signature []byte) error {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("签名格式错误: %v", err)
+	}
+	if !ed25519.Verify(publicKey, data, decoded) {
+		return fmt.Errorf("选择器包签名验证失败")
+	}
+	return nil
+}
+
+// writeSelectorPackCache 缓存选择器包和签名，下次下载失败时使用
+func writeSelectorPackCache(path string, data []byte, signature []byte) error {
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(path+".sig", signature, 0644)
+}
+
+// readSelectorPackCache 读取缓存的选择器包和签名
+func readSelectorPackCache(path string) ([]byte, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, signature, nil
+}
+
+// applySelectorPack 用选择器包替换同名选择器链的候选选择器，未知的名称和空列表忽略
+func applySelectorPack(pack SelectorPack) {
+	chains := make(map[string]*SelectorChain, len(builtinChains))
+	for _, chain := range builtinChains {
+		chains[chain.Name] = chain
+	}
+	replaced := 0
+	for name, selectors := range pack.Chains {
+		chain, ok := chains[name]
+		if !ok {
+			log.Printf("⚠️ 选择器包中的 %s 不是可替换的选择器，已忽略", name)
+			continue
+		}
+		if len(selectors) == 0 {
+			continue
+		}
+		chain.Selectors = selectors
+		replaced++
+	}
+	log.Printf("🧩 已应用选择器包 %s，替换 %d 组选择器", pack.Version, replaced)
+}
+
+// runSelectorsCommand 处理 selectors 子命令：export 导出内置选择器作为选择器包模板，keygen 生成签名密钥，sign 为选择器包签名
+func runSelectorsCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(selectorPackUsage)
+	}
+	switch args[0] {
+	case "export":
+		pack := SelectorPack{Version: time.Now().Format("20060102"), Chains: make(map[string][]string)}
+		for _, chain := range builtinChains {
+			pack.Chains[chain.Name] = chain.Selectors
+		}
+		data, err := json.MarshalIndent(pack, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case "keygen":
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("生成密钥失败: %v", err)
+		}
+		fmt.Printf("public_key: %s\nprivate_key: %s\n", base64.StdEncoding.EncodeToString(publicKey), base64.StdEncoding.EncodeToString(privateKey))
+		return nil
+	case "sign":
+		fs := flag.NewFlagSet("selectors sign", flag.ExitOnError)
+		keyFile := fs.String("key", "", "私钥文件(内容为 keygen 输出的 private_key)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *keyFile == "" || fs.NArg() != 1 {
+			return fmt.Errorf("用法: selectors sign -key=private.key selectors.json")
+		}
+		return signSelectorPack(*keyFile, fs.Arg(0))
+	default:
+		return fmt.Errorf("未知的 selectors 操作: %s (可选 export/keygen/sign)", args[0])
+	}
+}
+
+// signSelectorPack 校验选择器包格式后签名，签名写入同名 .sig 文件
+func signSelectorPack(keyFile string, packFile string) error {
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("读取私钥失败: %v", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(keyData)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("私钥格式错误")
+	}
+	data, err := os.ReadFile(packFile)
+	if err != nil {
+		return fmt.Errorf("读取选择器包失败: %v", err)
+	}
+	var pack SelectorPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return fmt.Errorf("选择器包格式错误: %v", err)
+	}
+	signature := ed25519.Sign(ed25519.PrivateKey(key), data)
+	if err := os.WriteFile(packFile+".sig", []byte(base64.StdEncoding.EncodeToString(signature)), 0644); err != nil {
+		return fmt.Errorf("写入签名失败: %v", err)
+	}
+	log.Printf("✅ 已生成签名: %s.sig", packFile)
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultServiceName 默认服务名称
+const defaultServiceName = "channel-video-uploader"
+
+// serviceUsage service 子命令用法
+const serviceUsage = "用法: service install|uninstall|start|stop|run [-name 服务名] [-interval 30m] [-- 上传命令参数]"
+
+// defaultServiceInterval 服务两轮上传之间的默认间隔
+const defaultServiceInterval = 30 * time.Minute
+
+// ServiceConfig 系统服务配置
+type ServiceConfig struct {
+	Name      string
+	WorkDir   string        // 服务运行时的工作目录，相对路径的Excel/视频以此为准
+	RunArgs   []string      // 服务每一轮执行的上传命令参数
+	Interval  time.Duration // 一轮上传结束后等待多久开始下一轮
+	Bandwidth string        // 按时段的上传带宽上限，见 parseBandwidthSchedule
+	UnitDir   string        // systemd unit 文件目录（仅Linux）
+	PrintOnly bool          // 只输出 systemd unit 内容，不安装（仅Linux）
+}
+
+// runServiceCommand 处理 service 子命令：注册为 Windows 服务或生成 systemd unit
+func runServiceCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(serviceUsage)
+	}
+	action := args[0]
+
+	config := ServiceConfig{}
+	fs := flag.NewFlagSet("service "+action, flag.ExitOnError)
+	fs.StringVar(&config.Name, "name", defaultServiceName, "服务名称")
+	fs.StringVar(&config.WorkDir, "workdir", "", "服务工作目录(默认当前目录)")
+	fs.DurationVar(&config.Interval, "interval", defaultServiceInterval, "一轮上传结束后等待多久开始下一轮")
+	fs.StringVar(&config.Bandwidth, "bandwidth", "", "按时段的上传带宽上限(Mbps), 每轮开始时按当前时间设置 -max-upload-mbps, 例: 09:00-19:00=5,19:00-09:00=0")
+	fs.StringVar(&config.UnitDir, "unit-dir", "/etc/systemd/system", "systemd unit文件目录(仅Linux)")
+	fs.BoolVar(&config.PrintOnly, "print", false, "只输出systemd unit内容，不安装(仅Linux)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	// "--" 之后的参数原样传给上传命令
+	config.RunArgs = fs.Args()
+
+	if config.WorkDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("获取当前目录失败: %v", err)
+		}
+		config.WorkDir = wd
+	}
+
+	if config.Interval <= 0 {
+		return fmt.Errorf("-interval 必须大于 0")
+	}
+	if _, err := parseBandwidthSchedule(config.Bandwidth); err != nil {
+		return fmt.Errorf("-bandwidth 错误: %v", err)
+	}
+
+	switch action {
+	case "install":
+		if err := checkServiceRunArgs(config.RunArgs); err != nil {
+			return err
+		}
+		return installService(config)
+	case "uninstall":
+		return uninstallService(config)
+	case "start":
+		return startService(config)
+	case "stop":
+		return stopService(config)
+	case "run":
+		if err := os.Chdir(config.WorkDir); err != nil {
+			return fmt.Errorf("切换工作目录失败: %v", err)
+		}
+		return runAsService(config)
+	default:
+		return fmt.Errorf("不支持的服务操作: %s\n%s", action, serviceUsage)
+	}
+}
+
+// checkServiceRunArgs 检查服务的上传命令参数：服务没有桌面，不能弹出浏览器扫码，需要 -login=auth-file 或 -headless-login；
+// 每一轮都会重新读取任务表，需要 -only-new 跳过已成功上传的行（-queue 队列模式每条消息只消费一次，不需要）
+func checkServiceRunArgs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("安装服务需要指定上传命令参数，例: service install -- -file=tasks.xlsx -headless=true -login=auth-file -only-new")
+	}
+	login, _ := serviceArgValue(args, "login")
+	headlessLogin, _ := serviceArgValue(args, "headless-login")
+	if login != LoginModeAuthFile && !isTrueArg(headlessLogin) {
+		return fmt.Errorf("服务没有桌面，无法弹出浏览器扫码，上传命令参数需要 -login=auth-file（先执行 auth login 保存认证状态）或 -headless-login")
+	}
+	if queue, _ := serviceArgValue(args, "queue"); queue != "" {
+		return nil
+	}
+	if onlyNew, _ := serviceArgValue(args, "only-new"); !isTrueArg(onlyNew) {
+		return fmt.Errorf("服务每一轮都会重新读取任务表，上传命令参数需要 -only-new，否则重启或下一轮会重复发表已上传的行")
+	}
+	return nil
+}
+
+// serviceArgValue 在上传命令参数中查找 -name=value、--name=value 或 -name value，布尔参数只写 -name 时值为 true
+func serviceArgValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		key := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if key == arg {
+			continue
+		}
+		if value, ok := strings.CutPrefix(key, name+"="); ok {
+			return value, true
+		}
+		if key != name {
+			continue
+		}
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			return args[i+1], true
+		}
+		return "true", true
+	}
+	return "", false
+}
+
+// isTrueArg 布尔参数的值是否为 true，写法同 flag 包
+func isTrueArg(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "t", "true":
+		return true
+	}
+	return false
+}
+
+// runServiceLoop 服务的主循环：执行一轮上传命令，等待 config.Interval 后开始下一轮，直到 ctx 取消；
+// 一轮失败只记录日志，下一轮继续，已成功的行由 -only-new 跳过。ctx 取消时等待当前一轮处理完已开始的任务后返回，
+// 不中断正在进行的发表
+func runServiceLoop(ctx context.Context, config ServiceConfig) error {
+	bandwidth, err := parseBandwidthSchedule(config.Bandwidth)
+	if err != nil {
+		return fmt.Errorf("-bandwidth 错误: %v", err)
+	}
+	log.Printf("🔁 服务 %s 已启动，每轮结束后等待 %v 开始下一轮", config.Name, config.Interval)
+	for round := 1; ; round++ {
+		args := config.RunArgs
+		if mbps, ok := bandwidthAt(bandwidth, time.Now()); ok {
+			// 放在最后，覆盖上传命令参数中的 -max-upload-mbps
+			args = append(slices.Clip(args), fmt.Sprintf("-max-upload-mbps=%g", mbps))
+			log.Printf("▶️ 开始第 %d 轮上传，本时段上传带宽上限 %s", round, bandwidthLabel(mbps))
+		} else {
+			log.Printf("▶️ 开始第 %d 轮上传", round)
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- runServiceRound(ctx, args)
+		}()
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			log.Printf("🛑 收到停止请求，等待第 %d 轮正在执行的任务完成...", round)
+			err = <-done
+		}
+		if err != nil {
+			log.Printf("❌ 第 %d 轮上传失败: %v", round, err)
+		} else {
+			log.Printf("✅ 第 %d 轮上传结束", round)
+		}
+		if ctx.Err() != nil {
+			log.Println("🛑 收到停止请求，服务退出")
+			return nil
+		}
+
+		select {
+		case <-time.After(config.Interval):
+		case <-ctx.Done():
+			log.Println("🛑 收到停止请求，服务退出")
+			return nil
+		}
+	}
+}
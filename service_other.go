@@ -0,0 +1,130 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// systemdUnitTemplate systemd 服务模板，日志通过标准输出写入 journal；停止时等待正在执行的任务完成，超过 TimeoutStopSec 才强制结束
+const systemdUnitTemplate = `[Unit]
+Description=视频号视频上传服务 (%s)
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+WorkingDirectory=%s
+ExecStart=%s
+Restart=on-failure
+RestartSec=30
+TimeoutStopSec=30min
+StandardOutput=journal
+StandardError=journal
+SyslogIdentifier=%s
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// installService 生成 systemd unit 并设置开机启动
+func installService(config ServiceConfig) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+
+	// systemd 执行 service run，由 runServiceLoop 按间隔循环上传
+	runArgs := append([]string{"service", "run", "-name", config.Name, "-workdir", config.WorkDir,
+		"-interval", config.Interval.String(), "-bandwidth", config.Bandwidth, "--"}, config.RunArgs...)
+	execStart := []string{systemdQuote(exe)}
+	for _, arg := range runArgs {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+	unit := fmt.Sprintf(systemdUnitTemplate, config.Name, systemdQuote(config.WorkDir), strings.Join(execStart, " "), config.Name)
+
+	if config.PrintOnly {
+		fmt.Print(unit)
+		return nil
+	}
+
+	unitPath := filepath.Join(config.UnitDir, config.Name+".service")
+	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("写入unit文件失败: %v", err)
+	}
+	log.Printf("✅ 已生成 systemd unit: %s", unitPath)
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl("enable", config.Name); err != nil {
+		return err
+	}
+	log.Printf("✅ 服务 %s 已设置开机启动，日志查看: journalctl -u %s -f", config.Name, config.Name)
+	return nil
+}
+
+// uninstallService 停止并删除 systemd unit
+func uninstallService(config ServiceConfig) error {
+	if err := systemctl("disable", "--now", config.Name); err != nil {
+		log.Printf("⚠️ 停用服务失败: %v", err)
+	}
+
+	unitPath := filepath.Join(config.UnitDir, config.Name+".service")
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除unit文件失败: %v", err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	log.Printf("✅ 服务 %s 已卸载", config.Name)
+	return nil
+}
+
+// startService 启动服务
+func startService(config ServiceConfig) error {
+	return systemctl("start", config.Name)
+}
+
+// stopService 停止服务
+func stopService(config ServiceConfig) error {
+	return systemctl("stop", config.Name)
+}
+
+// runAsService 以服务方式运行，systemd stop 发送 SIGTERM 时退出循环
+func runAsService(config ServiceConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return runServiceLoop(ctx, config)
+}
+
+// systemctl 执行 systemctl 命令
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("执行 systemctl %s 失败: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// systemdQuote 按 systemd 规则转义参数
+func systemdQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;$") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	return `"` + arg + `"`
+}
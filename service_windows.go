@@ -0,0 +1,217 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// installService 注册为 Windows 服务（自动启动，失败自动重启）并注册事件日志来源
+func installService(config ServiceConfig) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败: %v", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(config.Name); err == nil {
+		s.Close()
+		return fmt.Errorf("服务 %s 已存在", config.Name)
+	}
+
+	args := append([]string{"service", "run", "-name", config.Name, "-workdir", config.WorkDir,
+		"-interval", config.Interval.String(), "-bandwidth", config.Bandwidth, "--"}, config.RunArgs...)
+	s, err := m.CreateService(config.Name, exe, mgr.Config{
+		DisplayName: "视频号视频上传服务 (" + config.Name + ")",
+		Description: "自动上传视频号视频",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("创建服务失败: %v", err)
+	}
+	defer s.Close()
+
+	// 失败后30秒自动重启
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+	}, 86400); err != nil {
+		log.Printf("⚠️ 设置失败重启策略失败: %v", err)
+	}
+	if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		log.Printf("⚠️ 设置失败重启策略失败: %v", err)
+	}
+
+	if err := eventlog.InstallAsEventCreate(config.Name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return fmt.Errorf("注册事件日志失败: %v", err)
+	}
+
+	log.Printf("✅ 服务 %s 已安装，日志请在事件查看器 -> Windows日志 -> 应用程序 中查看", config.Name)
+	return nil
+}
+
+// uninstallService 停止并删除 Windows 服务
+func uninstallService(config ServiceConfig) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败: %v", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(config.Name)
+	if err != nil {
+		return fmt.Errorf("服务 %s 不存在", config.Name)
+	}
+	defer s.Close()
+
+	if _, err := s.Control(svc.Stop); err != nil {
+		log.Printf("⚠️ 停止服务失败: %v", err)
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("删除服务失败: %v", err)
+	}
+	if err := eventlog.Remove(config.Name); err != nil {
+		log.Printf("⚠️ 删除事件日志来源失败: %v", err)
+	}
+
+	log.Printf("✅ 服务 %s 已卸载", config.Name)
+	return nil
+}
+
+// startService 启动服务
+func startService(config ServiceConfig) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败: %v", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(config.Name)
+	if err != nil {
+		return fmt.Errorf("服务 %s 不存在", config.Name)
+	}
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("启动服务失败: %v", err)
+	}
+	log.Printf("✅ 服务 %s 已启动", config.Name)
+	return nil
+}
+
+// stopService 停止服务
+func stopService(config ServiceConfig) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败: %v", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(config.Name)
+	if err != nil {
+		return fmt.Errorf("服务 %s 不存在", config.Name)
+	}
+	defer s.Close()
+
+	if _, err := s.Control(svc.Stop); err != nil {
+		return fmt.Errorf("停止服务失败: %v", err)
+	}
+	log.Printf("✅ 服务 %s 已停止", config.Name)
+	return nil
+}
+
+// runAsService 由服务管理器启动时运行服务，日志写入事件日志；命令行直接运行时按 Ctrl+C 退出循环
+func runAsService(config ServiceConfig) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return fmt.Errorf("检测服务环境失败: %v", err)
+	}
+	if !isService {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return runServiceLoop(ctx, config)
+	}
+
+	elog, err := eventlog.Open(config.Name)
+	if err != nil {
+		return fmt.Errorf("打开事件日志失败: %v", err)
+	}
+	defer elog.Close()
+	log.SetOutput(&eventLogWriter{elog: elog})
+
+	return svc.Run(config.Name, &uploaderService{config: config})
+}
+
+// uploaderService 实现 svc.Handler
+type uploaderService struct {
+	config ServiceConfig
+}
+
+// Execute 在后台按间隔循环执行上传命令，并响应服务管理器的停止请求
+func (s *uploaderService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- runServiceLoop(ctx, s.config)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Printf("❌ %v", err)
+				// 非零退出码触发失败重启策略
+				return true, 1
+			}
+			return false, 0
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				changes <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
+
+// eventLogWriter 将标准日志写入 Windows 事件日志
+type eventLogWriter struct {
+	elog *eventlog.Log
+}
+
+func (w *eventLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	var err error
+	switch {
+	case strings.Contains(msg, "❌"):
+		err = w.elog.Error(1, msg)
+	case strings.Contains(msg, "⚠️"):
+		err = w.elog.Warning(1, msg)
+	default:
+		err = w.elog.Info(1, msg)
+	}
+	return len(p), err
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// actionStage 保存方式"仅上传不提交"：上传视频并填写表单，不点击最终按钮，由操作人员检查后手动发表
+const actionStage = "stage"
+
+// stagedPollInterval 等待操作人员关闭保留的页面时检查的间隔
+const stagedPollInterval = 2 * time.Second
+
+// stagedPages 仅上传不提交的任务页面：显示浏览器时保持打开，运行结束前等待操作人员检查、手动发表并关闭页面；
+// 为 nil 时不保留
+type stagedPages struct {
+	mu    sync.Mutex
+	pages map[int]playwright.Page // 行号 -> 页面
+}
+
+// newStagedPages 显示浏览器时保留仅上传不提交的页面，无头模式下只保存截图
+func newStagedPages(headless bool) *stagedPages {
+	if headless {
+		return nil
+	}
+	return &stagedPages{pages: make(map[int]playwright.Page)}
+}
+
+// keep 保留任务页面，返回 false 表示不保留（无头模式）
+func (s *stagedPages) keep(row int, page playwright.Page) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[row] = page
+	log.Printf("⏸️ 第%d行仅上传不提交，页面保持打开，请在浏览器中检查后手动发表", row)
+	return true
+}
+
+// wait 等待操作人员关闭所有保留的页面，关闭前浏览器不会退出
+func (s *stagedPages) wait() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pages) == 0 {
+		return
+	}
+	log.Printf("⏸️ %d 个仅上传不提交的页面保持打开，检查并手动发表后关闭这些页面，全部关闭后程序继续", len(s.pages))
+	for len(s.pages) > 0 {
+		for row, page := range s.pages {
+			if page.IsClosed() {
+				log.Printf("✅ 第%d行的页面已关闭", row)
+				delete(s.pages, row)
+			}
+		}
+		time.Sleep(stagedPollInterval)
+	}
+}
+
+// saveStagedScreenshot 将填写完成的表单整页截图保存到 output-dir/staged/ 目录，供操作人员检查
+func saveStagedScreenshot(page playwright.Page, result *TaskResult, outputDir string) (string, error) {
+	dir := filepath.Join(outputDir, "staged")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建截图目录失败: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("row%d_%s.png", result.Task.RowIndex, time.Now().Format("20060102_150405")))
+	if _, err := page.Screenshot(playwright.PageScreenshotOptions{Path: playwright.String(path), FullPage: playwright.Bool(true)}); err != nil {
+		return "", fmt.Errorf("保存表单截图失败: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stealthScripts 在页面脚本执行前注入的反检测脚本，按名称可单独关闭
+var stealthScripts = map[string]string{
+	// 自动化控制的浏览器 navigator.webdriver 为 true
+	"webdriver": `Object.defineProperty(Navigator.prototype, 'webdriver', { get: () => false });`,
+
+	// 无头浏览器没有插件，正常的 Chrome 有内置的 PDF 查看器
+	"plugins": `(() => {
+	const mimeType = { type: 'application/pdf', suffixes: 'pdf', description: 'Portable Document Format' };
+	const names = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer', 'Microsoft Edge PDF Viewer', 'WebKit built-in PDF'];
+	const plugins = names.map((name) => {
+		const plugin = { name, filename: 'internal-pdf-viewer', description: 'Portable Document Format', length: 1, 0: mimeType };
+		plugin.item = (i) => plugin[i];
+		plugin.namedItem = (type) => (type === mimeType.type ? mimeType : null);
+		Object.setPrototypeOf(plugin, Plugin.prototype);
+		return plugin;
+	});
+	const pluginArray = Object.assign(Object.create(PluginArray.prototype), plugins, {
+		length: plugins.length,
+		item: (i) => plugins[i],
+		namedItem: (name) => plugins.find((plugin) => plugin.name === name) || null,
+		refresh: () => {},
+	});
+	const mimeTypes = Object.assign(Object.create(MimeTypeArray.prototype), { 0: mimeType, length: 1,
+		item: (i) => (i === 0 ? mimeType : null), namedItem: (type) => (type === mimeType.type ? mimeType : null) });
+	Object.defineProperty(Navigator.prototype, 'plugins', { get: () => pluginArray });
+	Object.defineProperty(Navigator.prototype, 'mimeTypes', { get: () => mimeTypes });
+	Object.defineProperty(Navigator.prototype, 'pdfViewerEnabled', { get: () => true });
+})();`,
+
+	// 与页面语言一致的 navigator.languages，{{languages}} 按 locale 替换
+	"languages": `Object.defineProperty(Navigator.prototype, 'languages', { get: () => {{languages}} });`,
+
+	// 无头浏览器的 WebGL 厂商为 Google SwiftShader
+	"webgl": `(() => {
+	const patch = (proto) => {
+		const getParameter = proto.getParameter;
+		proto.getParameter = function (parameter) {
+			if (parameter === 37445) return 'Google Inc. (Intel)';
+			if (parameter === 37446) return 'ANGLE (Intel, Intel(R) UHD Graphics 630 Direct3D11 vs_5_0 ps_5_0, D3D11)';
+			return getParameter.call(this, parameter);
+		};
+	};
+	patch(WebGLRenderingContext.prototype);
+	if (typeof WebGL2RenderingContext !== 'undefined') patch(WebGL2RenderingContext.prototype);
+})();`,
+
+	// 无头浏览器中通知权限的查询结果与 Notification.permission 不一致
+	"permissions": `(() => {
+	if (!navigator.permissions) return;
+	const query = navigator.permissions.query.bind(navigator.permissions);
+	navigator.permissions.query = (parameters) =>
+		parameters && parameters.name === 'notifications'
+			? Promise.resolve({ state: Notification.permission === 'default' ? 'prompt' : Notification.permission, onchange: null })
+			: query(parameters);
+})();`,
+
+	// 正常的 Chrome 有 window.chrome.runtime 等对象
+	"chrome_runtime": `(() => {
+	if (window.chrome && window.chrome.runtime) return;
+	const chrome = window.chrome || {};
+	chrome.runtime = chrome.runtime || {
+		OnInstalledReason: { CHROME_UPDATE: 'chrome_update', INSTALL: 'install', SHARED_MODULE_UPDATE: 'shared_module_update', UPDATE: 'update' },
+		PlatformOs: { ANDROID: 'android', CROS: 'cros', LINUX: 'linux', MAC: 'mac', OPENBSD: 'openbsd', WIN: 'win' },
+		connect: () => {},
+		sendMessage: () => {},
+	};
+	chrome.app = chrome.app || { isInstalled: false, InstallState: { DISABLED: 'disabled', INSTALLED: 'installed', NOT_INSTALLED: 'not_installed' } };
+	chrome.csi = chrome.csi || (() => ({ startE: Date.now(), onloadT: Date.now(), pageT: performance.now(), tran: 15 }));
+	chrome.loadTimes = chrome.loadTimes || (() => ({ requestTime: Date.now() / 1000, navigationType: 'Other', wasFetchedViaSpdy: true, connectionInfo: 'h2' }));
+	window.chrome = chrome;
+})();`,
+}
+
+// validateStealthSkip 校验要关闭的反检测脚本名称
+func validateStealthSkip(names []string) error {
+	for _, name := range names {
+		if _, ok := stealthScripts[name]; !ok {
+			return fmt.Errorf("未知的反检测脚本: %s(可选 %s)", name, strings.Join(stealthScriptNames(), "、"))
+		}
+	}
+	return nil
+}
+
+// stealthScriptNames 按名称排序的反检测脚本
+func stealthScriptNames() []string {
+	names := make([]string, 0, len(stealthScripts))
+	for name := range stealthScripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stealthScript 合并启用的反检测脚本，skip 中的脚本不注入
+func stealthScript(locale string, skip []string) string {
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	var scripts []string
+	for _, name := range stealthScriptNames() {
+		if skipped[name] {
+			continue
+		}
+		script := stealthScripts[name]
+		if name == "languages" {
+			script = strings.Replace(script, "{{languages}}", navigatorLanguages(locale), 1)
+		}
+		scripts = append(scripts, script)
+	}
+	return strings.Join(scripts, "\n")
+}
+
+// navigatorLanguages 按 locale 生成 navigator.languages，如 zh-CN 为 ["zh-CN","zh"]
+func navigatorLanguages(locale string) string {
+	languages := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		languages = append(languages, base)
+	}
+	if !strings.HasPrefix(locale, "en") {
+		languages = append(languages, "en")
+	}
+	return `["` + strings.Join(languages, `","`) + `"]`
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// stepSelectors 单步调试时暂停的步骤及其主要操作的元素，未列出的步骤不暂停
+var stepSelectors = map[string]string{
+	StepFileUpload:  "input[type='file']",
+	StepDescription: descriptionSelector,
+	StepLocation:    locationSelector,
+	StepCollection:  collectionSelector,
+	StepLink:        linkSelector,
+	StepActivity:    activitySelector,
+	StepSchedule:    "label:has(input[value='1'])",
+	StepShortTitle:  ".short-title-wrap input.weui-desktop-form__input",
+	StepMusic:       ".post-music-wrap .music-display",
+	StepCover:       ".post-cover-wrap .cover-edit",
+	StepSubmit:      ".form-btns button",
+}
+
+// 高亮和取消高亮当前步骤的元素
+const (
+	stepHighlightScript   = `(el) => { el.scrollIntoView({block: 'center'}); el.dataset.stepOutline = el.style.outline; el.style.outline = '3px solid red'; }`
+	stepUnhighlightScript = `(el) => { el.style.outline = el.dataset.stepOutline || ''; delete el.dataset.stepOutline; }`
+)
+
+// errStepAborted 单步调试中操作人员中止任务
+var errStepAborted = errors.New("单步调试中止任务")
+
+// StepDebugger 单步调试：每个主要步骤执行前高亮该步骤的元素、截图，并等待操作人员在终端确认，为 nil 时不暂停
+type StepDebugger struct {
+	mu      sync.Mutex
+	input   *bufio.Reader
+	dir     string // 截图目录
+	count   int    // 已暂停的次数，用于截图文件名排序
+	resumed bool   // 输入 c 后不再暂停
+}
+
+// NewStepDebugger 创建单步调试，截图保存到 output-dir/step/ 目录
+func NewStepDebugger(outputDir string) *StepDebugger {
+	return &StepDebugger{input: bufio.NewReader(os.Stdin), dir: filepath.Join(outputDir, "step")}
+}
+
+// wrap 包装步骤函数，步骤执行前暂停等待确认；d 为 nil 时原样返回
+func (d *StepDebugger) wrap(page playwright.Page, result *TaskResult, onStep StepFunc) StepFunc {
+	if d == nil {
+		return onStep
+	}
+	aborted := false
+	return func(name string, fn func() error) error {
+		// 中止后后续步骤都不再执行，提交按钮步骤的错误会被忽略
+		if aborted {
+			return errStepAborted
+		}
+		skip, err := d.pause(page, result, name)
+		if err != nil {
+			aborted = err == errStepAborted
+			return err
+		}
+		if skip {
+			log.Printf("⏭️ 已跳过步骤: %s", name)
+			return nil
+		}
+		return runStep(onStep, name, fn)
+	}
+}
+
+// pause 高亮步骤元素并截图，等待终端输入：回车执行该步骤，s 跳过，c 执行并不再暂停，q 中止任务
+func (d *StepDebugger) pause(page playwright.Page, result *TaskResult, name string) (bool, error) {
+	selector, ok := stepSelectors[name]
+	if !ok {
+		return false, nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.resumed {
+		return false, nil
+	}
+
+	element := page.Locator(selector).First()
+	found := false
+	if count, err := page.Locator(selector).Count(); err == nil && count > 0 {
+		_, err := element.Evaluate(stepHighlightScript, nil)
+		found = err == nil
+	}
+	if found {
+		defer element.Evaluate(stepUnhighlightScript, nil)
+	}
+
+	d.count++
+	screenshot, err := d.screenshot(page, result, name)
+	if err != nil {
+		screenshot = fmt.Sprintf("截图失败: %v", err)
+	}
+	elementState := "已高亮"
+	if !found {
+		elementState = "页面上未找到"
+	}
+	fmt.Fprintf(os.Stderr, "\n⏸️ 第%d行任务即将执行步骤: %s\n   选择器: %s (%s)\n   截图: %s\n   回车执行 | s 跳过该步骤 | c 执行并不再暂停 | q 中止任务: ",
+		result.Task.RowIndex, name, selector, elementState, screenshot)
+
+	line, err := d.input.ReadString('\n')
+	if err == io.EOF {
+		// 标准输入已关闭时无法再确认，之后不再暂停
+		fmt.Fprintln(os.Stderr)
+		log.Println("⚠️ 标准输入已关闭，单步调试不再暂停")
+		d.resumed = true
+	} else if err != nil {
+		return false, fmt.Errorf("读取单步调试输入失败: %v", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "s":
+		return true, nil
+	case "c":
+		d.resumed = true
+	case "q":
+		return false, errStepAborted
+	}
+	return false, nil
+}
+
+// screenshot 保存暂停时的页面截图
+func (d *StepDebugger) screenshot(page playwright.Page, result *TaskResult, name string) (string, error) {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(d.dir, fmt.Sprintf("%03d_row%d_%s.png", d.count, result.Task.RowIndex, name))
+	if _, err := page.Screenshot(playwright.PageScreenshotOptions{Path: playwright.String(path)}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
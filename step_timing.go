@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 任务步骤名称，用于统计各步骤耗时和失败次数
+const (
+	StepNavigation    = "navigation"
+	StepContentType   = "content_type"
+	StepFileUpload    = "file_upload"
+	StepDescription   = "description"
+	StepLocation      = "location"
+	StepCollection    = "collection"
+	StepLink          = "link"
+	StepActivity      = "activity"
+	StepSchedule      = "schedule"
+	StepShortTitle    = "short_title"
+	StepMusic         = "music"
+	StepCover         = "cover"
+	StepSubmit        = "submit"
+	StepVerification  = "verification"
+	StepScheduleCheck = "schedule_check" // 发表后在作品管理中核对定时时间
+	StepPublishCheck  = "publish_check"  // 重试时发表前在作品管理中查找是否已发表
+	StepOpenDraft     = "open_draft"     // 更新草稿时在草稿箱中找到草稿并进入编辑
+)
+
+// StepTiming 单个步骤的执行记录
+type StepTiming struct {
+	Name      string        `json:"name"`
+	Attempt   int           `json:"attempt"` // 第几次执行任务时记录（崩溃恢复后会重试）
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// StepFunc 执行一个步骤并记录耗时，返回步骤本身的错误
+type StepFunc func(name string, fn func() error) error
+
+// runStep 通过 onStep 执行步骤，onStep 为空时直接执行
+func runStep(onStep StepFunc, name string, fn func() error) error {
+	if onStep == nil {
+		return fn()
+	}
+	return onStep(name, fn)
+}
+
+// Step 执行步骤并将耗时记录到结果中，同时生成步骤 span
+func (r *TaskResult) Step(name string, fn func() error) error {
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracer.Start(ctx, "step "+name, trace.WithAttributes(attribute.Int("step.attempt", r.Attempts)))
+
+	start := time.Now()
+	r.step = name
+	err := fn()
+	r.step = ""
+	step := StepTiming{Name: name, Attempt: r.Attempts, StartedAt: start, Duration: time.Since(start)}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	r.Steps = append(r.Steps, step)
+	endSpan(span, err)
+	r.hooks.progress(r.Task, step)
+	return err
+}
+
+// StepTrace 最后一次执行中各步骤的顺序、开始时间和结果，如
+// "12:00:01 file_upload ok(35.2s) → 12:00:36 description ok(1.1s) → 12:00:38 schedule FAILED: ..."，
+// 用于排查失败的任务执行到了哪一步，没有步骤记录时返回空字符串
+func (r TaskResult) StepTrace() string {
+	var parts []string
+	for _, step := range r.Steps {
+		if step.Attempt != r.Attempts {
+			continue
+		}
+		status := fmt.Sprintf("ok(%v)", step.Duration.Round(100*time.Millisecond))
+		if step.Error != "" {
+			status = "FAILED: " + strings.Join(strings.Fields(step.Error), " ")
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", step.StartedAt.Format("15:04:05"), step.Name, status))
+	}
+	return strings.Join(parts, " → ")
+}
+
+// Event 在任务 span 上记录事件，如崩溃恢复
+func (r *TaskResult) Event(name string, err error) {
+	if r.ctx == nil {
+		return
+	}
+	var attributes []attribute.KeyValue
+	if err != nil {
+		attributes = append(attributes, attribute.String("error", err.Error()))
+	}
+	trace.SpanFromContext(r.ctx).AddEvent(name, trace.WithAttributes(attributes...))
+}
+
+// stepStats 某个步骤在所有任务中的汇总
+type stepStats struct {
+	name     string
+	count    int
+	failures int
+	total    time.Duration
+	max      time.Duration // 单次最长耗时
+}
+
+// collectStepStats 按步骤汇总耗时和失败次数，总耗时多的排在前面
+func collectStepStats(results []TaskResult) []*stepStats {
+	statsByName := make(map[string]*stepStats)
+	for _, result := range results {
+		for _, step := range result.Steps {
+			stats, ok := statsByName[step.Name]
+			if !ok {
+				stats = &stepStats{name: step.Name}
+				statsByName[step.Name] = stats
+			}
+			stats.count++
+			stats.total += step.Duration
+			stats.max = max(stats.max, step.Duration)
+			if step.Error != "" {
+				stats.failures++
+			}
+		}
+	}
+
+	summary := make([]*stepStats, 0, len(statsByName))
+	for _, stats := range statsByName {
+		summary = append(summary, stats)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].total > summary[j].total })
+	return summary
+}
+
+// printStepSummary 打印各步骤耗时和失败次数
+func printStepSummary(results []TaskResult) {
+	summary := collectStepStats(results)
+	if len(summary) == 0 {
+		return
+	}
+
+	log.Println("⏱️ ===== 步骤耗时统计 =====")
+	for _, stats := range summary {
+		log.Printf("⏱️ %-14s 次数: %d, 总耗时: %v, 平均: %v, 失败: %d",
+			stats.name, stats.count, stats.total.Round(time.Millisecond),
+			(stats.total / time.Duration(stats.count)).Round(time.Millisecond), stats.failures)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 任务前后命令的步骤名称
+const (
+	StepBeforeTask = "before_task"
+	StepAfterTask  = "after_task"
+)
+
+// defaultTaskCommandTimeout 任务前后命令的默认超时时间
+const defaultTaskCommandTimeout = 10 * time.Minute
+
+// TaskCommandConfig 每个任务前后执行的命令，任务字段通过 UPLOADER_ 开头的环境变量传入
+type TaskCommandConfig struct {
+	Before  string        `yaml:"before"`  // 任务开始前执行（如从素材库拉取视频），退出码非0时跳过任务
+	After   string        `yaml:"after"`   // 任务结束后执行（如归档视频），成功或失败都执行，退出码非0时任务标记为失败
+	Timeout time.Duration `yaml:"timeout"` // 单个命令的超时时间，默认10分钟
+}
+
+// withFlags 命令行 -before-task、-after-task 覆盖配置文件中的命令，为空时保留配置文件的命令
+func (c TaskCommandConfig) withFlags(before string, after string) TaskCommandConfig {
+	if before != "" {
+		c.Before = before
+	}
+	if after != "" {
+		c.After = after
+	}
+	return c
+}
+
+// checkVideosFor 解析任务时是否检查视频文件存在：配置了前置命令时视频可能在任务开始前才准备好，
+// 更新草稿时不上传视频，都不检查
+func checkVideosFor(commands TaskCommandConfig, updateDrafts bool) bool {
+	return commands.Before == "" && !updateDrafts
+}
+
+// runWithTaskCommands 在任务前后执行命令，各命令作为独立步骤记录耗时
+func runWithTaskCommands(result *TaskResult, commands TaskCommandConfig, run func(result *TaskResult)) {
+	if commands.Before != "" {
+		err := result.Step(StepBeforeTask, func() error {
+			return runTaskCommand(commands.Before, commands.Timeout, *result)
+		})
+		if err != nil {
+			result.Fail(fmt.Errorf("前置命令失败，跳过任务: %v", err))
+			result.ErrorCategory = ErrorCategoryTaskCommand
+			return
+		}
+	}
+
+	run(result)
+
+	if commands.After != "" {
+		// 先确定任务本身的失败分类和步骤，避免被后置命令覆盖
+		result.classify()
+		err := result.Step(StepAfterTask, func() error {
+			return runTaskCommand(commands.After, commands.Timeout, *result)
+		})
+		if err != nil && result.Success {
+			result.Fail(fmt.Errorf("后置命令失败: %v", err))
+			result.ErrorCategory = ErrorCategoryTaskCommand
+			result.FailedStep = StepAfterTask
+		} else if err != nil {
+			log.Printf("⚠️ 第%d行后置命令失败: %v", result.Task.RowIndex, err)
+		}
+	}
+}
+
+// runTaskCommand 通过系统 shell 执行命令，输出写入日志；退出码非0或超时时返回错误
+func runTaskCommand(command string, timeout time.Duration, result TaskResult) error {
+	if timeout <= 0 {
+		timeout = defaultTaskCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), taskCommandEnv(result)...)
+	// 超时后 shell 启动的子进程可能仍占用输出管道，不再等待
+	cmd.WaitDelay = 5 * time.Second
+	output, err := cmd.CombinedOutput()
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines {
+		if line != "" {
+			log.Printf("💲 第%d行: %s", result.Task.RowIndex, line)
+		}
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("命令执行超时(%v)", timeout)
+	}
+	if err != nil {
+		// 附上最后一行输出，便于在结果中看到原因
+		if last := lines[len(lines)-1]; last != "" {
+			return fmt.Errorf("%v: %s", err, last)
+		}
+		return err
+	}
+	return nil
+}
+
+// taskCommandEnv 任务字段和执行结果对应的环境变量（前置命令执行时结果字段为空）
+func taskCommandEnv(result TaskResult) []string {
+	task := result.Task
+	return []string{
+		"UPLOADER_ROW=" + strconv.Itoa(task.RowIndex),
+		"UPLOADER_VIDEO_PATH=" + task.VideoPath,
+		"UPLOADER_DESCRIPTION=" + task.Description,
+		"UPLOADER_LOCATION=" + task.Location,
+		"UPLOADER_COLLECTION=" + task.Collection,
+		"UPLOADER_LINK=" + task.Link,
+		"UPLOADER_ACTIVITY=" + task.Activity,
+		"UPLOADER_SCHEDULE=" + strconv.FormatBool(task.Schedule),
+		"UPLOADER_SCHEDULE_TIME=" + task.ScheduleTime,
+		"UPLOADER_SHORT_TITLE=" + task.ShortTitle,
+		"UPLOADER_ACTION=" + task.Action,
+		"UPLOADER_CONTENT_TYPE=" + task.ContentType,
+		"UPLOADER_MUSIC=" + task.Music,
+		"UPLOADER_COVER_CROP=" + task.CoverCrop,
+		"UPLOADER_COVER_IMAGE=" + task.CoverImage,
+		"UPLOADER_TAGS=" + strings.Join(task.Tags, " "),
+		"UPLOADER_NOTE=" + task.Note,
+		"UPLOADER_CAMPAIGN=" + task.Campaign,
+		"UPLOADER_ACCOUNT=" + task.Account,
+		"UPLOADER_CHANNEL_NAME=" + result.ChannelName,
+		"UPLOADER_SUCCESS=" + strconv.FormatBool(result.Success),
+		"UPLOADER_ERROR=" + result.Error,
+		"UPLOADER_ERROR_CATEGORY=" + result.ErrorCategory,
+		"UPLOADER_PUBLISHED_URL=" + result.PublishedURL,
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestTaskCommandFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        TaskCommandConfig
+		before, after string
+		updateDrafts  bool
+		want          TaskCommandConfig
+		checkVideos   bool
+	}{
+		{"都没有配置", TaskCommandConfig{}, "", "", false, TaskCommandConfig{}, true},
+		{"命令行 -before-task", TaskCommandConfig{}, "./fetch.sh", "", false, TaskCommandConfig{Before: "./fetch.sh"}, false},
+		{"命令行覆盖配置文件", TaskCommandConfig{Before: "a", After: "b"}, "c", "d", false, TaskCommandConfig{Before: "c", After: "d"}, false},
+		{"配置文件 before", TaskCommandConfig{Before: "a"}, "", "", false, TaskCommandConfig{Before: "a"}, false},
+		{"只有 -after-task", TaskCommandConfig{}, "", "./archive.sh", false, TaskCommandConfig{After: "./archive.sh"}, true},
+		{"更新草稿", TaskCommandConfig{}, "", "", true, TaskCommandConfig{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.withFlags(tt.before, tt.after)
+			if got != tt.want {
+				t.Errorf("withFlags() = %+v, want %+v", got, tt.want)
+			}
+			if check := checkVideosFor(got, tt.updateDrafts); check != tt.checkVideos {
+				t.Errorf("checkVideosFor() = %v, want %v", check, tt.checkVideos)
+			}
+		})
+	}
+}
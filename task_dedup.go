@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// 重复行处理策略
+const (
+	DuplicatePolicyError = "error" // 报错，列出重复的行
+	DuplicatePolicySkip  = "skip"  // 保留第一行，跳过后面的重复行
+	DuplicatePolicyMerge = "merge" // 合并到第一行：第一行为空的字段用重复行补充，取值冲突时报错
+)
+
+// duplicateKey 判断重复的依据：同一视频、同一账号、同一发表时间
+func duplicateKey(task VideoCreateTask) string {
+	path := filepath.Clean(task.VideoPath)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	// Windows 文件名不区分大小写
+	if runtime.GOOS == "windows" {
+		path = strings.ToLower(path)
+	}
+	schedule := ""
+	if task.Schedule {
+		schedule = strings.TrimSpace(task.ScheduleTime)
+	}
+	// 不同账号发表同一视频不算重复，未填写账号列的任务为 -login 登录的账号
+	return taskAccount(task) + "|" + path + "|" + schedule
+}
+
+// dedupeTasks 按策略处理表格中的重复行，避免复制粘贴导致同一视频重复发表
+func dedupeTasks(videoCreateTasks []VideoCreateTask, policy string) ([]VideoCreateTask, error) {
+	switch policy {
+	case DuplicatePolicyError, DuplicatePolicySkip, DuplicatePolicyMerge:
+	default:
+		return nil, fmt.Errorf("不支持的重复行处理方式: %s (可选 error/skip/merge)", policy)
+	}
+
+	var tasks []VideoCreateTask
+	firstByKey := make(map[string]int) // key -> tasks 中的下标
+	var duplicates []string
+	var conflicts []string
+	for _, task := range videoCreateTasks {
+		key := duplicateKey(task)
+		index, exists := firstByKey[key]
+		if !exists {
+			firstByKey[key] = len(tasks)
+			tasks = append(tasks, task)
+			continue
+		}
+
+		first := &tasks[index]
+		duplicates = append(duplicates, fmt.Sprintf("第%d行与第%d行重复: %s", task.RowIndex, first.RowIndex, filepath.Base(task.VideoPath)))
+		if policy == DuplicatePolicyMerge {
+			if err := mergeTask(first, task); err != nil {
+				conflicts = append(conflicts, fmt.Sprintf("第%d行合并到第%d行失败: %v", task.RowIndex, first.RowIndex, err))
+			}
+		}
+	}
+
+	if len(duplicates) == 0 {
+		return videoCreateTasks, nil
+	}
+	switch policy {
+	case DuplicatePolicyError:
+		return nil, fmt.Errorf("发现 %d 个重复行(同一视频和发表时间), 可使用 -duplicates=skip 或 merge:\n%s",
+			len(duplicates), strings.Join(duplicates, "\n"))
+	case DuplicatePolicyMerge:
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("重复行取值冲突，无法合并:\n%s", strings.Join(conflicts, "\n"))
+		}
+		log.Printf("⚠️ 已合并 %d 个重复行:\n%s", len(duplicates), strings.Join(duplicates, "\n"))
+	default:
+		log.Printf("⚠️ 已跳过 %d 个重复行:\n%s", len(duplicates), strings.Join(duplicates, "\n"))
+	}
+	return tasks, nil
+}
+
+// mergeTask 用重复行补充第一行中为空的字段，两行都有值且不同时返回错误
+func mergeTask(first *VideoCreateTask, duplicate VideoCreateTask) error {
+	fields := []struct {
+		name  string
+		first *string
+		value string
+	}{
+		{"视频描述", &first.Description, duplicate.Description},
+		{"位置", &first.Location, duplicate.Location},
+		{"合集", &first.Collection, duplicate.Collection},
+		{"链接", &first.Link, duplicate.Link},
+		{"活动", &first.Activity, duplicate.Activity},
+		{"短标题", &first.ShortTitle, duplicate.ShortTitle},
+		{"保存方式", &first.Action, duplicate.Action},
+	}
+	var conflicts []string
+	for name, value := range duplicate.Extra {
+		switch {
+		case first.Extra[name] == value:
+		case first.Extra[name] == "":
+			if first.Extra == nil {
+				first.Extra = make(map[string]string)
+			}
+			first.Extra[name] = value
+		default:
+			conflicts = append(conflicts, name)
+		}
+	}
+	for _, field := range fields {
+		switch {
+		case field.value == "" || field.value == *field.first:
+		case *field.first == "":
+			*field.first = field.value
+		default:
+			conflicts = append(conflicts, field.name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s 不一致", strings.Join(conflicts, "、"))
+	}
+	return nil
+}
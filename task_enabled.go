@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// enabledColumn 启用列的表头，放在J列之后：填写 否 时保留该行但不上传（计划中、暂停的发布），为空时启用
+const enabledColumn = "启用"
+
+// enabledValues 启用列可以写中文、英文或 1/0（数据库任务的布尔列）
+var enabledValues = map[string]bool{
+	"是": true, "y": true, "yes": true, "true": true, "1": true,
+	"否": false, "n": false, "no": false, "false": false, "0": false,
+}
+
+// parseEnabled 解析启用列，为空时启用
+func parseEnabled(value string) (bool, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return true, nil
+	}
+	enabled, ok := enabledValues[value]
+	if !ok {
+		return false, fmt.Errorf("启用列只能填写 是 或 否: %s", value)
+	}
+	return enabled, nil
+}
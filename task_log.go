@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 日志格式
+const (
+	LogFormatText = "text" // 与标准库 log 相同的格式，任务中的日志在时间后加 [row=3 account=xx task_id=xx]
+	LogFormatJSON = "json" // 每行一个 JSON 对象，任务字段为 row、account、task_id
+)
+
+// logOutput 设置 slog 前标准库 log 的输出（标准错误或 Windows 事件日志），设置后 log 的输出指向 slog
+var logOutput io.Writer
+
+// taskLogs 正在执行任务的 goroutine -> 任务结果，任务中输出的日志按 goroutine 找到所属任务
+var taskLogs sync.Map
+
+// setupLogging 将标准库 log 的输出转为 slog，任务中输出的每行日志带上行号、账号和任务 ID，
+// 并发执行时可以按任务过滤交错的日志；日志经日志管道写入，退出前调用 logs.Close 写完缓冲
+func setupLogging(format string) error {
+	if logOutput == nil {
+		logOutput = log.Writer()
+	}
+	logs = NewLogPipeline()
+	out := logs.Writer(logOutput)
+	var handler slog.Handler
+	switch format {
+	case LogFormatText, "":
+		handler = &plainLogHandler{mu: &sync.Mutex{}, out: out}
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(out, nil)
+	default:
+		return fmt.Errorf("不支持的日志格式: %s (可选 text、json)", format)
+	}
+	slog.SetDefault(slog.New(taskLogHandler{handler}))
+	return nil
+}
+
+// bindTaskLog 之后当前 goroutine 输出的日志属于该任务，返回解除绑定的函数
+func bindTaskLog(result *TaskResult) func() {
+	if result.TaskID == "" {
+		result.TaskID = newTaskID(result)
+	}
+	id := goroutineID()
+	taskLogs.Store(id, result)
+	return func() { taskLogs.Delete(id) }
+}
+
+// newTaskID 任务 ID：启用链路追踪时为 trace ID，便于与追踪数据对应，否则随机生成
+func newTaskID(result *TaskResult) string {
+	if result.ctx != nil {
+		if spanContext := trace.SpanContextFromContext(result.ctx); spanContext.HasTraceID() {
+			return spanContext.TraceID().String()
+		}
+	}
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// goroutineID 当前 goroutine 的编号，从调用栈的第一行 "goroutine 123 [running]:" 读取
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
+
+// currentTaskLog 当前 goroutine 所属的任务，不在任务中时返回 nil
+func currentTaskLog() *TaskResult {
+	value, ok := taskLogs.Load(goroutineID())
+	if !ok {
+		return nil
+	}
+	return value.(*TaskResult)
+}
+
+// taskLogAttrs 任务的日志字段，result 为 nil 时返回 nil
+func taskLogAttrs(result *TaskResult) []slog.Attr {
+	if result == nil {
+		return nil
+	}
+	attrs := []slog.Attr{slog.Int("row", result.Task.RowIndex)}
+	if result.ChannelName != "" {
+		attrs = append(attrs, slog.String("account", result.ChannelName))
+	}
+	return append(attrs, slog.String("task_id", result.TaskID))
+}
+
+// taskLogHandler 为任务中的日志加上任务字段
+type taskLogHandler struct {
+	slog.Handler
+}
+
+func (h taskLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	result := currentTaskLog()
+	if c := activeCSVLog.Load(); c != nil {
+		c.write(r, result)
+	}
+	if attrs := taskLogAttrs(result); attrs != nil {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h taskLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return taskLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h taskLogHandler) WithGroup(name string) slog.Handler {
+	return taskLogHandler{h.Handler.WithGroup(name)}
+}
+
+// plainLogHandler 保持原来的日志格式：时间 [字段] 消息
+type plainLogHandler struct {
+	mu    *sync.Mutex
+	out   io.Writer
+	attrs []slog.Attr
+}
+
+func (h *plainLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
+
+func (h *plainLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	buf.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+	attrs := append([]slog.Attr(nil), h.attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	if len(attrs) > 0 {
+		buf.WriteByte('[')
+		for i, attr := range attrs {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(&buf, "%s=%v", attr.Key, attr.Value)
+		}
+		buf.WriteString("] ")
+	}
+	buf.WriteString(r.Message)
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(buf.Bytes())
+	return err
+}
+
+func (h *plainLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &plainLogHandler{mu: h.mu, out: h.out, attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...)}
+}
+
+func (h *plainLogHandler) WithGroup(name string) slog.Handler {
+	return h
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestFile 任务清单：{"tasks": [...]}，也可以直接是任务数组
+type manifestFile struct {
+	Tasks []manifestTask `json:"tasks"`
+}
+
+// manifestTask 清单中的任务：字段同 VideoCreateTask 的 json 标签，封面和重试可写为嵌套的对象，
+// 话题写为数组；转换为任务表的行后与 Excel 任务统一校验
+type manifestTask struct {
+	VideoCreateTask
+	Cover *manifestCover `json:"cover,omitempty"`
+	Retry *manifestRetry `json:"retry,omitempty"`
+}
+
+// manifestCover 封面：{"image": "cover.jpg", "crop": "3:4 y=-10"}，写为字符串时为封面图片
+type manifestCover struct {
+	Image string `json:"image"`
+	Crop  string `json:"crop"`
+}
+
+// UnmarshalJSON 封面可以是对象或图片路径
+func (c *manifestCover) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		return json.Unmarshal(data, &c.Image)
+	}
+	type plain manifestCover
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// manifestRetry 重试：{"max_attempts": 3, "backoff": "30m", "max_backoff": "2h"}，
+// 写为字符串时与重试列相同（重试队列中保存的任务即为该格式）
+type manifestRetry struct {
+	MaxAttempts int    `json:"max_attempts"`
+	Backoff     string `json:"backoff"`
+	MaxBackoff  string `json:"max_backoff"`
+
+	text string
+}
+
+// UnmarshalJSON 重试可以是对象或重试列的文字
+func (r *manifestRetry) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		return json.Unmarshal(data, &r.text)
+	}
+	type plain manifestRetry
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// String 按重试列的格式输出，由 parseTaskRetry 校验
+func (r manifestRetry) String() string {
+	if r.text != "" {
+		return r.text
+	}
+	var fields []string
+	if r.MaxAttempts != 0 {
+		fields = append(fields, fmt.Sprintf("max_attempts=%d", r.MaxAttempts))
+	}
+	if r.Backoff != "" {
+		fields = append(fields, "backoff="+r.Backoff)
+	}
+	if r.MaxBackoff != "" {
+		fields = append(fields, "max_backoff="+r.MaxBackoff)
+	}
+	return strings.Join(fields, " ")
+}
+
+// task 清单中的任务转换为 VideoCreateTask，嵌套的字段写入对应的列
+func (t manifestTask) task() VideoCreateTask {
+	task := t.VideoCreateTask
+	if t.Cover != nil {
+		task.CoverImage = t.Cover.Image
+		if t.Cover.Crop != "" {
+			task.CoverCrop = t.Cover.Crop
+		}
+	}
+	if t.Retry != nil {
+		task.Retry = t.Retry.String()
+	}
+	return task
+}
+
+// decodeManifest 解析 JSON 任务清单：{"tasks": [...]} 或任务数组；不是清单时（JSON Lines）返回 false
+func decodeManifest(content []byte) ([]VideoCreateTask, bool, error) {
+	var items []manifestTask
+	switch {
+	case bytes.HasPrefix(content, []byte("[")):
+		if err := json.Unmarshal(content, &items); err != nil {
+			return nil, true, fmt.Errorf("解析JSON失败: %v", err)
+		}
+	case bytes.HasPrefix(content, []byte("{")) && json.Valid(content):
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(content, &keys); err != nil {
+			return nil, false, nil
+		}
+		if _, ok := keys["tasks"]; !ok {
+			return nil, false, nil
+		}
+		var file manifestFile
+		if err := json.Unmarshal(content, &file); err != nil {
+			return nil, true, fmt.Errorf("解析任务清单失败: %v", err)
+		}
+		items = file.Tasks
+	default:
+		return nil, false, nil
+	}
+	tasks := make([]VideoCreateTask, len(items))
+	for i, item := range items {
+		tasks[i] = item.task()
+	}
+	return tasks, true, nil
+}
+
+// yamlToJSON 将 YAML 任务清单转换为 JSON，之后与 JSON 清单相同处理
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析YAML失败: %v", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("YAML 任务清单为空")
+	}
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("转换YAML任务清单失败: %v", err)
+	}
+	return content, nil
+}
+
+// manifestFormat -manifest 指定的清单按扩展名确定格式：JSON 或 YAML
+func manifestFormat(path string, format string) (string, error) {
+	if format != TaskFormatAuto && format != "" {
+		if format != TaskFormatJSON && format != TaskFormatYAML {
+			return "", fmt.Errorf("-manifest 只支持 json、yaml 格式: %s", format)
+		}
+		return format, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl":
+		return TaskFormatJSON, nil
+	case ".yaml", ".yml":
+		return TaskFormatYAML, nil
+	}
+	return "", fmt.Errorf("任务清单只支持 .json、.yaml、.yml 文件: %s", path)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// 顺序模式下任务之间如何重置上传页面
+const (
+	PageResetReload   = "reload"   // 刷新当前页面（默认）
+	PageResetNavigate = "navigate" // 重新打开上传页面地址
+	PageResetNone     = "none"     // 不处理
+)
+
+// PacingOptions 任务节奏：最终操作后的停留时间和顺序模式下的任务间隔，
+// 间隔随机化可以提高稳定性，也更接近人工操作
+type PacingOptions struct {
+	PostActionWait time.Duration // 最终操作完成后在页面停留的时间
+	MinDelay       time.Duration // 任务间隔下限
+	MaxDelay       time.Duration // 任务间隔上限，大于下限时在区间内随机
+	Jitter         time.Duration // 在间隔之上额外随机增加 0~Jitter
+	PageReset      string        // reload | navigate | none
+}
+
+// parseDelayRange 解析任务间隔，支持单个时长（3s）或区间（3s-10s）
+func parseDelayRange(value string) (time.Duration, time.Duration, error) {
+	minText, maxText, isRange := strings.Cut(strings.TrimSpace(value), "-")
+	min, err := time.ParseDuration(strings.TrimSpace(minText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("任务间隔格式错误: %s(例: 3s 或 3s-10s)", value)
+	}
+	max := min
+	if isRange {
+		if max, err = time.ParseDuration(strings.TrimSpace(maxText)); err != nil {
+			return 0, 0, fmt.Errorf("任务间隔格式错误: %s(例: 3s 或 3s-10s)", value)
+		}
+	}
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("任务间隔区间无效: %s", value)
+	}
+	return min, max, nil
+}
+
+// Validate 检查页面重置方式
+func (p PacingOptions) Validate() error {
+	switch p.PageReset {
+	case PageResetReload, PageResetNavigate, PageResetNone:
+		return nil
+	default:
+		return fmt.Errorf("不支持的任务间页面处理方式: %s (可选 reload/navigate/none)", p.PageReset)
+	}
+}
+
+// waitAfterAction 最终操作完成后停留
+func (p PacingOptions) waitAfterAction() {
+	if p.PostActionWait > 0 {
+		time.Sleep(p.PostActionWait)
+	}
+}
+
+// delay 本次任务间隔：区间内随机，再加上随机抖动
+func (p PacingOptions) delay() time.Duration {
+	delay := p.MinDelay
+	if p.MaxDelay > p.MinDelay {
+		delay += rand.N(p.MaxDelay - p.MinDelay)
+	}
+	if p.Jitter > 0 {
+		delay += rand.N(p.Jitter)
+	}
+	return delay
+}
+
+// betweenTasks 顺序模式下一个任务结束后重置页面并等待
+func (p PacingOptions) betweenTasks(page *playwright.Page) {
+	if page != nil && !(*page).IsClosed() {
+		var err error
+		switch p.PageReset {
+		case PageResetNavigate:
+			err = navigateCreatePage(*page)
+		case PageResetReload, "":
+			_, err = (*page).Reload()
+		}
+		if err != nil {
+			log.Printf("⚠️ 重置上传页面失败: %v", err)
+		}
+		if p.PageReset != PageResetNone {
+			clearLeftoverForm(page)
+		}
+	}
+
+	delay := p.delay()
+	if delay > 0 {
+		log.Printf("⏳ 等待 %v 后开始下一个任务", delay.Round(100*time.Millisecond))
+		time.Sleep(delay)
+	}
+}
+
+// clearLeftoverForm 刷新较慢时页面上可能还是上一个任务填了一半的表单：仍有残留时重新打开上传页面，
+// 仍然不是空表单时关闭页面，由下一个任务重新生成
+func clearLeftoverForm(page *playwright.Page) {
+	// 等待表单渲染
+	time.Sleep(2 * time.Second)
+	leftover := leftoverForm(*page)
+	if leftover == "" {
+		return
+	}
+	log.Printf("🧹 重置后上传页面仍有上一个任务的%s，重新打开上传页面", leftover)
+	if err := navigateCreatePage(*page); err != nil {
+		log.Printf("⚠️ 重新打开上传页面失败: %v", err)
+	} else {
+		time.Sleep(2 * time.Second)
+		if leftover = leftoverForm(*page); leftover == "" {
+			return
+		}
+	}
+	log.Printf("⚠️ 上传页面仍有残留的%s，关闭页面，下一个任务使用新页面", leftover)
+	(*page).Close()
+}
+
+// leftoverForm 上传页面上残留的内容（已选择的文件、描述），没有残留时返回空字符串
+func leftoverForm(page playwright.Page) string {
+	var leftovers []string
+	if hasDeleteButton(page) {
+		leftovers = append(leftovers, "视频文件")
+	}
+	editor := page.Locator(descriptionSelector).First()
+	if count, _ := page.Locator(descriptionSelector).Count(); count > 0 {
+		if text, err := editor.TextContent(); err == nil && strings.TrimSpace(text) != "" {
+			leftovers = append(leftovers, "描述")
+		}
+	}
+	return strings.Join(leftovers, "、")
+}
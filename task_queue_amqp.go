@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 记录处理次数和重试时间（RFC3339）的消息头
+const (
+	amqpAttemptHeader   = "x-uploader-attempt"
+	amqpNotBeforeHeader = "x-uploader-not-before"
+)
+
+// amqpTaskQueue 基于 RabbitMQ 的任务队列，消息体为 JSON 任务。
+// 队列由运维预先声明，死信交换机(x-dead-letter-exchange)配置在队列上
+type amqpTaskQueue struct {
+	conn       *amqp.Connection
+	channel    *amqp.Channel
+	queue      string
+	deliveries <-chan amqp.Delivery
+}
+
+// newAMQPTaskQueue 连接 RabbitMQ 并开始消费，每次只预取一条消息
+func newAMQPTaskQueue(u *url.URL) (*amqpTaskQueue, error) {
+	query := u.Query()
+	q := &amqpTaskQueue{queue: query.Get("queue")}
+	if q.queue == "" {
+		q.queue = "uploader.tasks"
+	}
+	query.Del("queue")
+	clean := *u
+	clean.RawQuery = query.Encode()
+
+	conn, err := amqp.Dial(clean.String())
+	if err != nil {
+		return nil, fmt.Errorf("连接 RabbitMQ 失败: %v", err)
+	}
+	q.conn = conn
+	if q.channel, err = conn.Channel(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("打开 RabbitMQ 通道失败: %v", err)
+	}
+	if err := q.channel.Qos(1, 0, false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("设置 RabbitMQ 预取数量失败: %v", err)
+	}
+	if q.deliveries, err = q.channel.Consume(q.queue, "", false, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("订阅 RabbitMQ 队列 %s 失败: %v", q.queue, err)
+	}
+	return q, nil
+}
+
+// Receive 读取下一条消息
+func (q *amqpTaskQueue) Receive(ctx context.Context) (*QueueMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case delivery, ok := <-q.deliveries:
+		if !ok {
+			return nil, fmt.Errorf("RabbitMQ 连接已断开")
+		}
+		id := delivery.MessageId
+		if id == "" {
+			id = fmt.Sprintf("#%d", delivery.DeliveryTag)
+		}
+		return &QueueMessage{
+			ID:          id,
+			Body:        delivery.Body,
+			Attempt:     queueAttempt(delivery.Headers[amqpAttemptHeader]),
+			NotBefore:   queueNotBefore(delivery.Headers[amqpNotBeforeHeader]),
+			Redelivered: delivery.Redelivered,
+			tag:         delivery.DeliveryTag,
+		}, nil
+	}
+}
+
+// Ack 确认消息
+func (q *amqpTaskQueue) Ack(msg *QueueMessage) error {
+	if err := q.channel.Ack(msg.tag, false); err != nil {
+		return fmt.Errorf("确认 RabbitMQ 消息失败: %v", err)
+	}
+	return nil
+}
+
+// Requeue 带处理次数和重试时间重新发布到队列，并确认原消息
+func (q *amqpTaskQueue) Requeue(msg *QueueMessage) error {
+	headers := amqp.Table{amqpAttemptHeader: int32(msg.Attempt)}
+	if !msg.NotBefore.IsZero() {
+		headers[amqpNotBeforeHeader] = msg.NotBefore.Format(time.RFC3339)
+	}
+	err := q.channel.PublishWithContext(context.Background(), "", q.queue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.ID,
+		Headers:      headers,
+		Body:         msg.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("重新投递 RabbitMQ 消息失败: %v", err)
+	}
+	return q.Ack(msg)
+}
+
+// Reject 拒绝消息且不重新入队，由队列配置的死信交换机接收
+func (q *amqpTaskQueue) Reject(msg *QueueMessage, reason string) error {
+	log.Printf("📭 消息 %s 转入死信: %s", msg.ID, reason)
+	if err := q.channel.Nack(msg.tag, false, false); err != nil {
+		return fmt.Errorf("拒绝 RabbitMQ 消息失败: %v", err)
+	}
+	return nil
+}
+
+// Close 关闭连接
+func (q *amqpTaskQueue) Close() error {
+	return q.conn.Close()
+}
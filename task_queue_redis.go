@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTaskQueue 基于 Redis Stream 消费组的任务队列，消息字段 task 为 JSON 任务、attempt 为处理次数、
+// not_before 为重试时间
+type redisTaskQueue struct {
+	client   *redis.Client
+	stream   string
+	group    string
+	consumer string
+	pending  bool // 启动时先处理本消费者上次未确认的消息
+}
+
+// newRedisTaskQueue 连接 Redis 并创建消费组（已存在时忽略）
+func newRedisTaskQueue(u *url.URL) (*redisTaskQueue, error) {
+	query := u.Query()
+	q := &redisTaskQueue{
+		stream:   query.Get("stream"),
+		group:    query.Get("group"),
+		consumer: query.Get("consumer"),
+		pending:  true,
+	}
+	if q.stream == "" {
+		q.stream = "uploader:tasks"
+	}
+	if q.group == "" {
+		q.group = "uploader"
+	}
+	if q.consumer == "" {
+		q.consumer, _ = os.Hostname()
+	}
+	// 其余参数交给 go-redis 解析
+	for _, key := range []string{"stream", "group", "consumer"} {
+		query.Del(key)
+	}
+	clean := *u
+	clean.RawQuery = query.Encode()
+	options, err := redis.ParseURL(clean.String())
+	if err != nil {
+		return nil, fmt.Errorf("Redis 地址格式错误: %v", err)
+	}
+	q.client = redis.NewClient(options)
+
+	err = q.client.XGroupCreateMkStream(context.Background(), q.stream, q.group, "0").Err()
+	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+		q.client.Close()
+		return nil, fmt.Errorf("创建 Redis 消费组失败: %v", err)
+	}
+	return q, nil
+}
+
+// Receive 读取下一条消息
+func (q *redisTaskQueue) Receive(ctx context.Context) (*QueueMessage, error) {
+	for {
+		id := ">"
+		if q.pending {
+			id = "0"
+		}
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.group,
+			Consumer: q.consumer,
+			Streams:  []string{q.stream, id},
+			Count:    1,
+			Block:    5 * time.Second,
+		}).Result()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 Redis 队列失败: %v", err)
+		}
+		if len(streams) == 0 || len(streams[0].Messages) == 0 {
+			q.pending = false
+			continue
+		}
+
+		message := streams[0].Messages[0]
+		body, _ := message.Values["task"].(string)
+		return &QueueMessage{
+			ID:          message.ID,
+			Body:        []byte(body),
+			Attempt:     queueAttempt(message.Values["attempt"]),
+			NotBefore:   queueNotBefore(message.Values["not_before"]),
+			Redelivered: id == "0",
+		}, nil
+	}
+}
+
+// Ack 确认消息
+func (q *redisTaskQueue) Ack(msg *QueueMessage) error {
+	if err := q.client.XAck(context.Background(), q.stream, q.group, msg.ID).Err(); err != nil {
+		return fmt.Errorf("确认 Redis 消息失败: %v", err)
+	}
+	return nil
+}
+
+// Requeue 带处理次数和重试时间重新追加到队列末尾，并确认原消息
+func (q *redisTaskQueue) Requeue(msg *QueueMessage) error {
+	values := map[string]interface{}{"task": string(msg.Body), "attempt": strconv.Itoa(msg.Attempt)}
+	if !msg.NotBefore.IsZero() {
+		values["not_before"] = msg.NotBefore.Format(time.RFC3339)
+	}
+	err := q.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: q.stream,
+		Values: values,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("重新投递 Redis 消息失败: %v", err)
+	}
+	return q.Ack(msg)
+}
+
+// Reject 转入死信队列 <stream>:dead，并确认原消息
+func (q *redisTaskQueue) Reject(msg *QueueMessage, reason string) error {
+	err := q.client.XAdd(context.Background(), &redis.XAddArgs{
+		Stream: q.stream + ":dead",
+		Values: map[string]interface{}{
+			"task":    string(msg.Body),
+			"attempt": strconv.Itoa(msg.Attempt),
+			"error":   reason,
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("写入 Redis 死信队列失败: %v", err)
+	}
+	return q.Ack(msg)
+}
+
+// Close 关闭连接
+func (q *redisTaskQueue) Close() error {
+	return q.client.Close()
+}
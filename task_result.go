@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskResult 任务执行结果，与任务输入 VideoCreateTask 分开保存
+type TaskResult struct {
+	Task               VideoCreateTask `json:"task"`
+	TaskID             string          `json:"task_id,omitempty"` // 日志中的 task_id，启用链路追踪时为 trace ID
+	ChannelName        string          `json:"channel_name,omitempty"`
+	Success            bool            `json:"success"`
+	Error              string          `json:"error,omitempty"`
+	ErrorCategory      string          `json:"error_category,omitempty"`
+	PlatformMessage    string          `json:"platform_message,omitempty"` // 失败时页面上平台提示的原文
+	FailedStep         string          `json:"failed_step,omitempty"`      // 导致失败的步骤
+	StartedAt          time.Time       `json:"started_at"`
+	Duration           time.Duration   `json:"duration"`
+	Attempts           int             `json:"attempts"`                  // 执行次数（含崩溃恢复后的重试）
+	History            []TaskAttempt   `json:"attempt_history,omitempty"` // 每次执行的开始时间、耗时和错误
+	UploadBytes        int64           `json:"upload_bytes,omitempty"`    // 上传的视频或图片总大小
+	Steps              []StepTiming    `json:"steps,omitempty"`           // 各步骤耗时
+	Trace              string          `json:"trace,omitempty"`           // 失败时最后一次执行的步骤记录，见 StepTrace
+	Artifacts          []string        `json:"artifacts,omitempty"`       // 截图、DOM快照等文件路径
+	PublishedURL       string          `json:"published_url,omitempty"`
+	Location           string          `json:"location,omitempty"`            // 选择位置后页面上显示的位置
+	ScheduleAdjustment string          `json:"schedule_adjustment,omitempty"` // 定时发表的分钟不可选时的调整
+	ManualSchedule     string          `json:"manual_schedule,omitempty"`     // 定时发表失败后改为保存草稿的原因，需手动设置定时
+	AlreadyPublished   string          `json:"already_published,omitempty"`   // 重试时在作品管理中找到的已发表作品，没有再次发表
+	Staged             bool            `json:"staged,omitempty"`              // 仅上传不提交：表单已填写，等待手动发表
+	UploadOnly         bool            `json:"upload_only,omitempty"`         // 仅上传模式：已保存草稿，可选字段待补充
+	Draft              string          `json:"draft,omitempty"`               // 更新草稿模式：在草稿箱中匹配草稿的关键字
+	PreviewQR          string          `json:"preview_qr,omitempty"`          // 手机预览的二维码截图
+
+	ctx   context.Context // 任务 span 的上下文，步骤 span 挂在其下
+	hooks *Hooks          // 步骤完成时输出 progress 事件
+	step  string          // 正在执行的步骤，写入 CSV 日志
+}
+
+// TaskAttempt 任务的一次执行：崩溃恢复、重新登录后重试时一个任务会执行多次，
+// 用于区分"两次超时后第3次成功"和"一次成功"
+type TaskAttempt struct {
+	Attempt       int           `json:"attempt"`
+	StartedAt     time.Time     `json:"started_at"`
+	Duration      time.Duration `json:"duration"`
+	Error         string        `json:"error,omitempty"` // 为空表示该次执行成功
+	ErrorCategory string        `json:"error_category,omitempty"`
+}
+
+// String 执行记录的文字描述，如"第1次 10:02:03 35.2s 失败[timeout]: 等待上传完成超时"
+func (a TaskAttempt) String() string {
+	status := "成功"
+	if a.Error != "" {
+		status = fmt.Sprintf("失败[%s]: %s", a.ErrorCategory, strings.Join(strings.Fields(a.Error), " "))
+	}
+	return fmt.Sprintf("第%d次 %s %v %s", a.Attempt, a.StartedAt.Format("15:04:05"), a.Duration.Round(100*time.Millisecond), status)
+}
+
+// beginAttempt 开始新的一次执行，上一次执行尚未结束时按当前的错误结束
+func (r *TaskResult) beginAttempt() {
+	r.endAttempt()
+	r.Attempts++
+	r.History = append(r.History, TaskAttempt{Attempt: r.Attempts, StartedAt: time.Now()})
+}
+
+// endAttempt 结束正在进行的执行，记录耗时和错误；没有正在进行的执行时不处理
+func (r *TaskResult) endAttempt() {
+	if len(r.History) == 0 {
+		return
+	}
+	attempt := &r.History[len(r.History)-1]
+	if attempt.Duration > 0 {
+		return
+	}
+	attempt.Duration = time.Since(attempt.StartedAt)
+	if r.Success {
+		return
+	}
+	attempt.Error, attempt.ErrorCategory = r.Error, r.ErrorCategory
+	if attempt.ErrorCategory == "" {
+		attempt.ErrorCategory = classifyError(r.Error)
+	}
+}
+
+// attemptTrace 各次执行的记录，用于报告和日志
+func (r TaskResult) attemptTrace() string {
+	parts := make([]string, 0, len(r.History))
+	for _, attempt := range r.History {
+		parts = append(parts, attempt.String())
+	}
+	return strings.Join(parts, " → ")
+}
+
+// newTaskResults 为每个任务创建待填充的结果
+func newTaskResults(videoCreateTasks []VideoCreateTask) []TaskResult {
+	results := make([]TaskResult, len(videoCreateTasks))
+	for i, task := range videoCreateTasks {
+		results[i] = TaskResult{Task: task}
+	}
+	return results
+}
+
+// Fail 将结果标记为失败
+func (r *TaskResult) Fail(err error) {
+	r.Success = false
+	r.Error = err.Error()
+}
+
+// classify 为失败的结果补充失败分类和失败步骤
+func (r *TaskResult) classify() {
+	if r.Success {
+		return
+	}
+	if r.ErrorCategory == "" {
+		r.ErrorCategory = classifyError(r.Error)
+	}
+	if r.PlatformMessage == "" {
+		r.PlatformMessage = platformMessage(r.Error)
+	}
+	if r.FailedStep == "" {
+		// 可选步骤失败不会中断任务，最后一个出错的步骤才是导致失败的步骤
+		for i := len(r.Steps) - 1; i >= 0; i-- {
+			if r.Steps[i].Error != "" {
+				r.FailedStep = r.Steps[i].Name
+				break
+			}
+		}
+	}
+	r.Trace = r.StepTrace()
+}
+
+// allTasksSucceeded 检查是否所有任务都成功
+func allTasksSucceeded(results []TaskResult) bool {
+	if len(results) == 0 {
+		return false
+	}
+	for _, result := range results {
+		if !result.Success {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 任务来源格式
+const (
+	TaskFormatAuto  = "auto"  // 按扩展名（标准输入按内容）识别
+	TaskFormatExcel = "excel" // .xlsx / .xlsm / .xls
+	TaskFormatCSV   = "csv"   // 与Excel相同的列，第一行为表头（.csv / .tsv）
+	TaskFormatJSON  = "json"  // 任务对象数组、任务清单 {"tasks": [...]} 或每行一个对象(JSON Lines)
+	TaskFormatYAML  = "yaml"  // 与 JSON 相同的任务清单（.yaml / .yml）
+)
+
+// stdinSource -file 取该值时从标准输入读取任务
+const stdinSource = "-"
+
+// taskColumns 任务表的列（A-J），CSV/JSON 任务转换为同样的行后统一校验
+var taskColumns = []string{"视频描述", "位置", "添加到合集", "链接", "活动", "定时发表", "定时时间", "短标题", "保存方式", "视频位置"}
+
+// 任务表各列在行中的位置，与 taskColumns 对应
+const (
+	colDescription = iota
+	colLocation
+	colCollection
+	colLink
+	colActivity
+	colSchedule
+	colScheduleTime
+	colShortTitle
+	colAction
+	colVideoPath
+)
+
+// taskRowCells 任务表的一行，按列位置读取单元格；excelize 省略行尾的空单元格，行中没有的列读取为空
+type taskRowCells []string
+
+// get 读取单元格并去掉首尾空白，行中没有该列时返回空
+func (r taskRowCells) get(column int) string {
+	if column < 0 || column >= len(r) {
+		return ""
+	}
+	return strings.TrimSpace(r[column])
+}
+
+// optionalTaskColumns J列之后按表头识别的可选列：JSON 字段/数据库列名 -> 表头
+var optionalTaskColumns = map[string]string{
+	"content_type": contentTypeColumn,
+	"music":        musicColumn,
+	"note":         noteColumn,
+	"enabled":      enabledColumn,
+	"campaign":     campaignColumn,
+	"cover_crop":   coverCropColumn,
+	"account":      accountColumn,
+	"cover_image":  coverImageColumn,
+	"tags":         tagsColumn,
+	"retry":        retryColumn,
+}
+
+// isOptionalTaskColumn 表头是否为可选列，可选列不作为自定义表单步骤的列
+func isOptionalTaskColumn(name string) bool {
+	for _, header := range optionalTaskColumns {
+		if name == header {
+			return true
+		}
+	}
+	return false
+}
+
+// taskActionNames JSON 中保存方式可以写英文或中文
+var taskActionNames = map[string]string{
+	"save_draft": "保存草稿",
+	"preview":    "手机预览",
+	"publish":    "发表",
+	actionStage:  "仅上传不提交",
+}
+
+// TaskSource 任务来源：Excel、CSV、JSON 文件或标准输入
+type TaskSource struct {
+	Path    string         // 文件路径，"-" 表示标准输入
+	Format  string         // excel | csv | json
+	Rows    [][]string     // 所有行（含表头），CSV/JSON 已转换为任务表的列
+	BaseDir string         // 视频位置中的相对路径相对的目录：任务文件所在目录，为空时相对于当前目录
+	Notes   map[int]string // 行号 -> 单元格批注中的操作备注（仅 .xlsx/.xlsm）
+}
+
+// LoadTaskSource 按格式读取任务来源的所有行
+func LoadTaskSource(path string, format string) (*TaskSource, error) {
+	var data []byte
+	if path == stdinSource {
+		var err error
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, fmt.Errorf("读取标准输入失败: %v", err)
+		}
+	}
+
+	format, err := resolveTaskFormat(path, format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &TaskSource{Path: path, Format: format}
+	if path != stdinSource {
+		// 任务表和视频目录一起复制到其他位置时，相对路径仍然有效，与从哪个目录运行无关
+		if source.BaseDir, err = filepath.Abs(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("无法解析任务文件目录: %v", err)
+		}
+	}
+	switch format {
+	case TaskFormatExcel:
+		if path == stdinSource {
+			return nil, fmt.Errorf("标准输入只支持 csv、json 和 yaml 格式")
+		}
+		if source.Rows, err = readWorkbookRows(path); err == nil {
+			// 批注只是备注，读取失败不影响上传
+			if source.Notes, err = readCellNotes(path); err != nil {
+				log.Printf("⚠️ 读取单元格批注失败: %v", err)
+				err = nil
+			}
+		}
+	case TaskFormatCSV, TaskFormatJSON, TaskFormatYAML:
+		if path != stdinSource {
+			if data, err = os.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("读取任务文件失败: %v", err)
+			}
+		}
+		switch format {
+		case TaskFormatCSV:
+			source.Rows, err = parseCSVRows(data)
+		case TaskFormatYAML:
+			if data, err = yamlToJSON(data); err == nil {
+				source.Rows, err = parseJSONRows(data)
+			}
+		default:
+			source.Rows, err = parseJSONRows(data)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	source.Rows = normalizeRows(source.Rows)
+
+	// JSON 任务已按当前任务表的列转换，CSV 没有记录版本，按版本 1 的位置读取后按表头调整
+	version := taskSheetVersion
+	switch {
+	case format == TaskFormatCSV:
+		version = 1
+	case format == TaskFormatExcel:
+		if version, err = taskSheetFileVersion(path); err != nil {
+			return nil, err
+		}
+	}
+	if source.Rows, err = migrateTaskRows(source.Rows, version); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// resolveTaskFormat 确定任务来源格式：文件按扩展名，标准输入按第一个非空字符
+func resolveTaskFormat(path string, format string, data []byte) (string, error) {
+	switch format {
+	case TaskFormatExcel, TaskFormatCSV, TaskFormatJSON, TaskFormatYAML:
+		return format, nil
+	case TaskFormatAuto, "":
+	default:
+		return "", fmt.Errorf("不支持的任务格式: %s(可选 auto、excel、csv、json、yaml)", format)
+	}
+
+	if path == stdinSource {
+		content := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+		if len(content) > 0 && (content[0] == '[' || content[0] == '{') {
+			return TaskFormatJSON, nil
+		}
+		return TaskFormatCSV, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv":
+		return TaskFormatCSV, nil
+	case ".json", ".jsonl":
+		return TaskFormatJSON, nil
+	case ".yaml", ".yml":
+		return TaskFormatYAML, nil
+	default:
+		return TaskFormatExcel, nil
+	}
+}
+
+// csvDelimiters CSV 可用的分隔符：CMS 和不同地区的 Excel 导出时可能使用分号或制表符
+var csvDelimiters = []rune{',', ';', '\t'}
+
+// parseCSVRows 解析 CSV 任务，列与Excel任务表相同；不是 UTF-8 编码时（中文 Windows 上的 Excel 另存为 CSV）按 GB18030 读取，
+// 分隔符按表头行识别
+func parseCSVRows(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("CSV 不是 UTF-8 编码，按 GBK 读取失败: %v", err)
+		}
+		log.Println("📄 CSV 不是 UTF-8 编码，按 GBK(GB18030) 读取")
+		data = decoded
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = csvDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析CSV失败: %v", err)
+	}
+	return rows, nil
+}
+
+// csvDelimiter 按表头行中出现最多的分隔符确定 CSV 的分隔符，都没有时为逗号
+func csvDelimiter(data []byte) rune {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	delimiter, most := ',', 0
+	for _, candidate := range csvDelimiters {
+		if count := bytes.Count(header, []byte(string(candidate))); count > most {
+			delimiter, most = candidate, count
+		}
+	}
+	return delimiter
+}
+
+// parseJSONRows 解析 JSON 任务（字段同 VideoCreateTask 的 json 标签，任务清单中的嵌套字段见 manifestTask），转换为任务表的行
+func parseJSONRows(data []byte) ([][]string, error) {
+	content := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	tasks, manifest, err := decodeManifest(content)
+	if err != nil {
+		return nil, err
+	}
+	if !manifest {
+		// JSON Lines：逐个读取对象
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		for {
+			var task manifestTask
+			if err := decoder.Decode(&task); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("解析JSON第%d个任务失败: %v", len(tasks)+1, err)
+			}
+			tasks = append(tasks, task.task())
+		}
+	}
+	return tasksToRows(tasks), nil
+}
+
+// tasksToRows 将任务转换为任务表的行（含表头），可选字段和 extra 中的键按名称排序后作为J列之后的列
+func tasksToRows(tasks []VideoCreateTask) [][]string {
+	var names []string
+	extraIndex := make(map[string]int)
+	// 每个任务J列之后的列，不修改任务本身的 extra
+	extras := make([]map[string]string, len(tasks))
+	for i, task := range tasks {
+		extras[i] = make(map[string]string, len(task.Extra)+4)
+		for name, value := range task.Extra {
+			extras[i][name] = value
+		}
+		optional := map[string]string{
+			contentTypeColumn: task.ContentType, musicColumn: task.Music, noteColumn: task.Note, campaignColumn: task.Campaign,
+			coverCropColumn: task.CoverCrop, accountColumn: task.Account, coverImageColumn: task.CoverImage,
+			tagsColumn: strings.Join(task.Tags, " "), retryColumn: task.Retry,
+		}
+		for header, value := range optional {
+			if value != "" {
+				extras[i][header] = value
+			}
+		}
+	}
+	for _, extra := range extras {
+		for name := range extra {
+			if _, ok := extraIndex[name]; !ok {
+				extraIndex[name] = 0
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	header := append([]string(nil), taskColumns...)
+	for _, name := range names {
+		extraIndex[name] = len(header)
+		header = append(header, name)
+	}
+	rows := [][]string{header}
+	for i, task := range tasks {
+		row := taskRow(task)
+		for name, value := range extras[i] {
+			for len(row) <= extraIndex[name] {
+				row = append(row, "")
+			}
+			row[extraIndex[name]] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// taskRow 将任务转换为任务表的一行
+func taskRow(task VideoCreateTask) []string {
+	schedule := "不定时"
+	if task.Schedule {
+		schedule = "定时"
+	}
+	action := task.Action
+	if name, ok := taskActionNames[action]; ok {
+		action = name
+	}
+	return []string{
+		task.Description,
+		task.Location,
+		task.Collection,
+		task.Link,
+		task.Activity,
+		schedule,
+		task.ScheduleTime,
+		task.ShortTitle,
+		action,
+		task.VideoPath,
+	}
+}
+
+// Name 来源名称，用于结果文件命名
+func (s *TaskSource) Name() string {
+	switch {
+	case s.Path == stdinSource:
+		return "stdin"
+	case s.Format == TaskFormatSQL:
+		return "sql"
+	}
+	return strings.TrimSuffix(filepath.Base(s.Path), filepath.Ext(s.Path))
+}
+
+// relativeToBase 视频位置中的相对路径按 base 目录解析；相对于 base 找不到、相对于当前目录存在时仍使用当前目录（兼容以前从视频目录运行的用法），
+// 此时返回 true。base 为空或路径为绝对路径时原样返回
+func relativeToBase(path string, base string) (string, bool) {
+	if base == "" || filepath.IsAbs(path) || filepath.VolumeName(path) != "" ||
+		strings.HasPrefix(path, "/") || strings.HasPrefix(path, string(filepath.Separator)) {
+		return path, false
+	}
+	joined := filepath.Join(base, path)
+	if _, err := os.Stat(resolvePath(joined)); err != nil {
+		if _, err := os.Stat(resolvePath(path)); err == nil {
+			return path, true
+		}
+	}
+	return joined, false
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// tagsColumn 话题列的表头，放在J列之后，多个话题用空格、逗号或 # 分隔
+const tagsColumn = "话题"
+
+// parseTags 拆分话题列，去掉 # 和重复的话题
+func parseTags(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '#' || r == ',' || r == '，' || r == '、' || r == ';' || r == '；'
+	})
+	var tags []string
+	for _, tag := range fields {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// descriptionWithTags 把描述中还没有的话题以 #话题 追加到描述末尾
+func descriptionWithTags(description string, tags []string) string {
+	var missing []string
+	for _, tag := range tags {
+		if !strings.Contains(description, "#"+tag) {
+			missing = append(missing, "#"+tag)
+		}
+	}
+	if len(missing) == 0 {
+		return description
+	}
+	if description == "" {
+		return strings.Join(missing, " ")
+	}
+	return description + " " + strings.Join(missing, " ")
+}
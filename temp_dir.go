@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunTempDir 单次运行的临时目录，下载、转码、暂存文件统一放在此目录下
+type RunTempDir struct {
+	root string
+	keep bool // 调试用：运行结束后保留临时目录
+}
+
+// NewRunTempDir 在系统临时目录下创建本次运行的临时目录
+func NewRunTempDir(keep bool) (*RunTempDir, error) {
+	prefix := fmt.Sprintf("wechat-uploader-%s-", time.Now().Format("20060102_150405"))
+	root, err := os.MkdirTemp("", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	log.Printf("📂 本次运行临时目录: %s", root)
+	return &RunTempDir{root: root, keep: keep}, nil
+}
+
+// Path 临时目录根路径
+func (d *RunTempDir) Path() string {
+	return d.root
+}
+
+// SubDir 获取（必要时创建）临时目录下的子目录，如 downloads、transcode、staging
+func (d *RunTempDir) SubDir(name string) (string, error) {
+	dir := filepath.Join(d.root, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建临时子目录失败: %v", err)
+	}
+	return dir, nil
+}
+
+// CreateFile 在子目录下创建临时文件，pattern 规则同 os.CreateTemp
+func (d *RunTempDir) CreateFile(subDir, pattern string) (*os.File, error) {
+	dir, err := d.SubDir(subDir)
+	if err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+// Size 统计临时目录占用的字节数
+func (d *RunTempDir) Size() (int64, error) {
+	var total int64
+	err := filepath.WalkDir(d.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// Cleanup 运行结束时清理临时目录：成功或目录为空时删除，失败或指定保留时保留以便排查
+func (d *RunTempDir) Cleanup(success bool) {
+	size, err := d.Size()
+	if err != nil {
+		log.Printf("⚠️ 统计临时目录大小失败: %v", err)
+	}
+	log.Printf("📂 临时目录占用: %s", formatBytes(size))
+
+	switch {
+	case d.keep:
+		log.Printf("📂 已保留临时目录(-keep-temp): %s", d.root)
+		return
+	case !success && size > 0:
+		log.Printf("📂 运行存在失败，保留临时目录以便排查: %s", d.root)
+		return
+	}
+
+	if err := os.RemoveAll(d.root); err != nil {
+		log.Printf("⚠️ 清理临时目录失败: %v", err)
+		return
+	}
+	log.Println("🧹 临时目录已清理")
+}
+
+// formatBytes 格式化字节数
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingServiceName 上报到追踪后端的服务名
+const tracingServiceName = "wechat-uploader"
+
+// tracer 运行 → 任务 → 步骤 的追踪，未配置导出地址时为空实现
+var tracer = otel.Tracer(tracingServiceName)
+
+// initTracing 配置 OTLP/HTTP 导出；endpoint 为空且未设置 OTEL_EXPORTER_OTLP_* 环境变量时不启用追踪。
+// 返回的函数在程序退出前调用，用于上报剩余的 span
+func initTracing(endpoint string) (func(), error) {
+	if endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func() {}, nil
+	}
+
+	var options []otlptracehttp.Option
+	if strings.Contains(endpoint, "://") {
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	} else if endpoint != "" {
+		// 只有 host:port 时按内网 collector 处理，不使用 TLS
+		options = append(options, otlptracehttp.WithEndpoint(endpoint), otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("创建 OTLP 导出器失败: %v", err)
+	}
+
+	attributes := []attribute.KeyValue{attribute.String("service.name", tracingServiceName)}
+	if hostname, err := os.Hostname(); err == nil {
+		attributes = append(attributes, attribute.String("host.name", hostname))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attributes...)),
+	)
+	otel.SetTracerProvider(provider)
+	log.Printf("🔭 已启用链路追踪，导出到: %s", describeTracingEndpoint(endpoint))
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Printf("⚠️ 上报追踪数据失败: %v", err)
+		}
+	}, nil
+}
+
+// describeTracingEndpoint 日志中显示的导出地址
+func describeTracingEndpoint(endpoint string) string {
+	if endpoint != "" {
+		return endpoint
+	}
+	if traces := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); traces != "" {
+		return traces
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+}
+
+// endSpan 按错误设置 span 状态并结束
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// taskSpanAttributes 任务 span 的属性
+func taskSpanAttributes(result TaskResult) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int("task.row", result.Task.RowIndex),
+		attribute.String("task.video", result.Task.VideoPath),
+		attribute.String("task.action", result.Task.Action),
+		attribute.String("task.channel", result.ChannelName),
+		attribute.Bool("task.success", result.Success),
+		attribute.Int("task.attempts", result.Attempts),
+		attribute.String("task.error_category", result.ErrorCategory),
+	}
+}
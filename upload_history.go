@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HistoryRecord 一次成功上传的记录
+type HistoryRecord struct {
+	Key          string    `json:"key"` // 与重复行判断相同：视频 + 发表时间
+	Account      string    `json:"account,omitempty"`
+	Campaign     string    `json:"campaign,omitempty"`
+	VideoPath    string    `json:"video_path"`
+	Action       string    `json:"action"`
+	ScheduleTime string    `json:"schedule_time,omitempty"`
+	SourceFile   string    `json:"source_file,omitempty"`
+	RowIndex     int       `json:"row_index"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// UploadHistory 跨运行保存的上传历史（JSON Lines 文件，每次成功上传追加一行）
+type UploadHistory struct {
+	mu      sync.Mutex
+	path    string
+	records map[string][]HistoryRecord // key -> 各账号的记录
+}
+
+// OpenUploadHistory 打开上传历史，文件不存在时视为空历史
+func OpenUploadHistory(path string) (*UploadHistory, error) {
+	h := &UploadHistory{path: path, records: make(map[string][]HistoryRecord)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开上传历史失败: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// 写到一半的最后一行等损坏记录跳过，不影响其他记录
+			log.Printf("⚠️ 上传历史第%d行无法解析，已跳过: %v", line, err)
+			continue
+		}
+		h.records[record.Key] = append(h.records[record.Key], record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取上传历史失败: %v", err)
+	}
+	return h, nil
+}
+
+// Uploaded 查询任务是否已成功上传过；account 为空时不区分账号
+func (h *UploadHistory) Uploaded(task VideoCreateTask, account string) (HistoryRecord, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, record := range h.records[duplicateKey(task)] {
+		if account == "" || record.Account == "" || record.Account == account {
+			return record, true
+		}
+	}
+	return HistoryRecord{}, false
+}
+
+// Records 所有上传记录，按上传时间排序
+func (h *UploadHistory) Records() []HistoryRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var records []HistoryRecord
+	for _, list := range h.records {
+		records = append(records, list...)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].UploadedAt.Before(records[j].UploadedAt) })
+	return records
+}
+
+// Record 记录成功上传的任务，为 nil 或任务失败时不记录
+func (h *UploadHistory) Record(result TaskResult, sourceFile string) {
+	if h == nil || !result.Success {
+		return
+	}
+	record := HistoryRecord{
+		Key:          duplicateKey(result.Task),
+		Account:      result.ChannelName,
+		Campaign:     result.Task.Campaign,
+		VideoPath:    result.Task.VideoPath,
+		Action:       result.Task.Action,
+		ScheduleTime: result.Task.ScheduleTime,
+		SourceFile:   sourceFile,
+		RowIndex:     result.Task.RowIndex,
+		UploadedAt:   time.Now(),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("⚠️ 记录上传历史失败: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := appendLine(h.path, data); err != nil {
+		log.Printf("⚠️ 记录上传历史失败: %v", err)
+		return
+	}
+	h.records[record.Key] = append(h.records[record.Key], record)
+}
+
+// appendLine 向文件追加一行
+func appendLine(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// filterNewTasks 只保留历史中没有成功上传记录的任务
+func filterNewTasks(videoCreateTasks []VideoCreateTask, history *UploadHistory, account string) []VideoCreateTask {
+	var tasks []VideoCreateTask
+	for _, task := range videoCreateTasks {
+		if record, ok := history.Uploaded(task, account); ok {
+			log.Printf("⏭️ 第%d行: %s 已于 %s 上传成功，跳过", task.RowIndex, filepath.Base(task.VideoPath),
+				record.UploadedAt.Format("2006-01-02 15:04:05"))
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	log.Printf("🔍 对比上传历史: 共 %d 行, 新任务 %d 个", len(videoCreateTasks), len(tasks))
+	return tasks
+}
@@ -0,0 +1,16 @@
+package main
+
+// uploadOnlyTask 仅上传模式下实际执行的任务：只保留描述和媒体文件，清除位置、合集、链接、活动、定时、短标题、音乐、
+// 封面裁剪、封面图片和自定义列，保存为草稿，由编辑人员之后在网页中补充；描述为空时用视频文件名作为描述，
+// 之后可用 -update-drafts 按文件名找到草稿
+func uploadOnlyTask(task VideoCreateTask) VideoCreateTask {
+	if task.Description == "" {
+		task.Description = draftFileKey(task.VideoPath)
+	}
+	task.Action = "save_draft"
+	task.Schedule, task.ScheduleTime = false, ""
+	task.Location, task.Collection, task.Link, task.Activity = "", "", "", ""
+	task.ShortTitle, task.Music, task.CoverCrop, task.CoverImage = "", "", "", ""
+	task.Extra = nil
+	return task
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// bytesPerMB 计算上传速度使用的 MB
+const bytesPerMB = 1024 * 1024
+
+// UploadStats 文件上传的耗时和速度统计，用于比较网络、资源拦截、并发数等调整前后的上传效率
+type UploadStats struct {
+	Count   int           `json:"count"`   // 文件上传成功的任务数
+	Bytes   int64         `json:"bytes"`   // 上传的文件总大小
+	Total   time.Duration `json:"total"`   // 上传耗时之和
+	Average time.Duration `json:"average"` // 平均上传耗时
+	P95     time.Duration `json:"p95"`     // 95% 的任务上传耗时不超过该值
+	Speed   float64       `json:"speed"`   // 平均上传速度(MB/s)：总大小 / 上传耗时之和
+}
+
+// mediaSize 待上传文件的总大小，读取失败的文件不计入
+func mediaSize(paths []string) int64 {
+	var size int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// uploadDuration 任务最后一次成功的文件上传步骤耗时（含等待上传完成），未上传成功时返回 false
+func uploadDuration(result TaskResult) (time.Duration, bool) {
+	for i := len(result.Steps) - 1; i >= 0; i-- {
+		step := result.Steps[i]
+		if step.Name == StepFileUpload && step.Error == "" {
+			return step.Duration, true
+		}
+	}
+	return 0, false
+}
+
+// uploadSpeed 上传速度(MB/s)
+func uploadSpeed(bytes int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(bytes) / bytesPerMB / d.Seconds()
+}
+
+// collectUploadStats 汇总文件上传成功的任务，没有时返回 nil
+func collectUploadStats(results []TaskResult) *UploadStats {
+	var durations []time.Duration
+	stats := &UploadStats{}
+	for _, result := range results {
+		d, ok := uploadDuration(result)
+		if !ok {
+			continue
+		}
+		durations = append(durations, d)
+		stats.Bytes += result.UploadBytes
+		stats.Total += d
+	}
+	if len(durations) == 0 {
+		return nil
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.Count = len(durations)
+	stats.Average = stats.Total / time.Duration(stats.Count)
+	// 最近秩法：第 ceil(0.95*n) 个
+	stats.P95 = durations[(stats.Count*95+99)/100-1]
+	stats.Speed = uploadSpeed(stats.Bytes, stats.Total)
+	return stats
+}
+
+// formatUpload 单个任务的上传大小、耗时和速度，未上传成功时返回空字符串
+func formatUpload(result TaskResult) string {
+	d, ok := uploadDuration(result)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("上传 %.1fMB 用时 %v (%.2fMB/s)",
+		float64(result.UploadBytes)/bytesPerMB, d.Round(100*time.Millisecond), uploadSpeed(result.UploadBytes, d))
+}
@@ -0,0 +1,161 @@
+// Package uploader 供其他 Go 程序嵌入视频号上传器：以子进程执行上传命令（加 -events），
+// 把任务开始、步骤完成、任务结束和需要扫码登录的事件交给 Options 中的回调，调用方用回调驱动自己的界面，不需要解析日志
+package uploader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// DefaultBinary Options.Binary 为空时在 PATH 中查找的上传器程序
+const DefaultBinary = "channel_video_uploader"
+
+// stopTimeout ctx 取消后等待上传器退出（写完日志）的时间，超时后强制结束
+const stopTimeout = 30 * time.Second
+
+// 上传器 -events 输出的事件类型
+const (
+	EventTaskStart     = "task_start"     // 任务开始执行
+	EventProgress      = "progress"       // 任务的一个步骤执行完成
+	EventTaskComplete  = "task_complete"  // 任务结束，包括未能开始就被标记为失败的任务
+	EventLoginRequired = "login_required" // 需要扫码登录，带登录二维码截图；二维码刷新时再次发送
+)
+
+// Event 上传器 -events 时写入标准输出的一行 JSON
+type Event struct {
+	Type   string  `json:"type"`
+	Task   *Task   `json:"task,omitempty"`
+	Step   *Step   `json:"step,omitempty"`
+	Result *Result `json:"result,omitempty"`
+	QRCode []byte  `json:"qr_code,omitempty"` // 登录二维码截图(PNG)
+}
+
+// Task 任务表中的一行
+type Task struct {
+	Row         int    `json:"row"` // 任务表中的行号
+	Account     string `json:"account,omitempty"`
+	Description string `json:"description,omitempty"`
+	VideoPath   string `json:"video_path"`
+	Action      string `json:"action"` // publish | save_draft | preview | stage
+}
+
+// Step 任务的一个步骤（打开页面、上传文件、填写各字段、提交、结果确认）
+type Step struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Result 任务结果
+type Result struct {
+	Task          Task          `json:"task"`
+	Success       bool          `json:"success"`
+	Error         string        `json:"error,omitempty"`
+	ErrorCategory string        `json:"error_category,omitempty"`
+	FailedStep    string        `json:"failed_step,omitempty"`
+	PublishedURL  string        `json:"published_url,omitempty"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// Options 运行上传器的参数和回调。未设置的回调不会被调用；回调在读取事件的 goroutine 中依次调用，
+// 并发上传时各任务的事件交错到达
+type Options struct {
+	Binary string    // 上传器程序路径，为空时为 PATH 中的 DefaultBinary
+	Args   []string  // 上传命令参数，同命令行（如 -file=tasks.xlsx -headless=true），自动加上 -events
+	Dir    string    // 工作目录，为空时为当前目录
+	Stderr io.Writer // 上传器的日志，为 nil 时丢弃
+
+	// OnTaskStart 任务开始执行
+	OnTaskStart func(task Task)
+	// OnProgress 任务的一个步骤执行完成
+	OnProgress func(task Task, step Step)
+	// OnTaskComplete 任务结束
+	OnTaskComplete func(result Result)
+	// OnLoginRequired 第一次需要扫码登录时调用，返回的处理函数接收登录二维码截图（PNG，刷新后再次调用）；
+	// 返回 nil 表示不需要
+	OnLoginRequired func() func(png []byte)
+}
+
+// Run 执行上传命令直到结束，把事件交给回调；上传器退出码非 0 时返回错误。
+// ctx 取消时先发送中断信号，上传器写完日志后退出，超过 30 秒仍未退出时强制结束
+func Run(ctx context.Context, options Options) error {
+	binary := options.Binary
+	if binary == "" {
+		binary = DefaultBinary
+	}
+	cmd := exec.CommandContext(ctx, binary, append(append([]string(nil), options.Args...), "-events")...)
+	cmd.Dir = options.Dir
+	cmd.Stderr = options.Stderr
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = stopTimeout
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("创建上传器输出管道失败: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动上传器失败: %v", err)
+	}
+	dispatchErr := dispatch(stdout, options)
+	// 读取事件失败时仍读完输出，避免上传器写标准输出时阻塞
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("上传器运行失败: %v", err)
+	}
+	return dispatchErr
+}
+
+// dispatch 逐行读取事件并调用对应的回调，不是事件的行（如浏览器安装的输出）忽略
+func dispatch(r io.Reader, options Options) error {
+	var onQRCode func(png []byte)
+	asked := false
+	scanner := bufio.NewScanner(r)
+	// 登录二维码截图可能较大
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		switch event.Type {
+		case EventTaskStart:
+			if options.OnTaskStart != nil && event.Task != nil {
+				options.OnTaskStart(*event.Task)
+			}
+		case EventProgress:
+			if options.OnProgress != nil && event.Task != nil && event.Step != nil {
+				options.OnProgress(*event.Task, *event.Step)
+			}
+		case EventTaskComplete:
+			if options.OnTaskComplete != nil && event.Result != nil {
+				options.OnTaskComplete(*event.Result)
+			}
+		case EventLoginRequired:
+			if !asked && options.OnLoginRequired != nil {
+				onQRCode, asked = options.OnLoginRequired(), true
+			}
+			if onQRCode != nil && len(event.QRCode) > 0 {
+				onQRCode(event.QRCode)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取上传器事件失败: %v", err)
+	}
+	return nil
+}
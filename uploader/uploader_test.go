@@ -0,0 +1,56 @@
+package uploader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDispatch(t *testing.T) {
+	input := strings.Join([]string{
+		"Downloading Chromium...",
+		`{"type":"login_required","qr_code":"AQI="}`,
+		`{"type":"login_required","qr_code":"AwQ="}`,
+		`{"type":"task_start","task":{"row":2,"video_path":"a.mp4","action":"publish"}}`,
+		`{"type":"progress","task":{"row":2,"video_path":"a.mp4","action":"publish"},"step":{"name":"上传文件","duration":1000}}`,
+		`{not json`,
+		`{"type":"task_complete","result":{"task":{"row":2,"video_path":"a.mp4","action":"publish"},"success":true,"duration":2000}}`,
+	}, "\n")
+
+	var got []string
+	asked := 0
+	var codes [][]byte
+	err := dispatch(strings.NewReader(input), Options{
+		OnTaskStart: func(task Task) { got = append(got, "start "+task.VideoPath) },
+		OnProgress:  func(task Task, step Step) { got = append(got, "progress "+step.Name) },
+		OnTaskComplete: func(result Result) {
+			if !result.Success || result.Task.Row != 2 {
+				t.Errorf("result = %+v", result)
+			}
+			got = append(got, "complete "+result.Task.VideoPath)
+		},
+		OnLoginRequired: func() func(png []byte) {
+			asked++
+			return func(png []byte) { codes = append(codes, png) }
+		},
+	})
+	if err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+	want := []string{"start a.mp4", "progress 上传文件", "complete a.mp4"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("callbacks = %v, want %v", got, want)
+	}
+	if asked != 1 {
+		t.Errorf("OnLoginRequired called %d times, want 1", asked)
+	}
+	if len(codes) != 2 || string(codes[0]) != "\x01\x02" || string(codes[1]) != "\x03\x04" {
+		t.Errorf("qr codes = %v", codes)
+	}
+}
+
+func TestDispatchWithoutCallbacks(t *testing.T) {
+	input := `{"type":"login_required","qr_code":"AQI="}` + "\n" + `{"type":"task_start","task":{"row":1}}`
+	if err := dispatch(strings.NewReader(input), Options{}); err != nil {
+		t.Fatalf("dispatch() error = %v", err)
+	}
+}
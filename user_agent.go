@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// UserAgentBrand UA-CH 中的一个品牌及版本
+type UserAgentBrand struct {
+	Brand   string `json:"brand"`
+	Version string `json:"version"`
+}
+
+// UserAgentProfile 一组相互一致的 UA 和 UA-CH（Client Hints），保存在认证状态中，固定后同一账号每次运行的指纹相同
+type UserAgentProfile struct {
+	UserAgent       string           `json:"user_agent"`
+	Platform        string           `json:"platform"`         // UA-CH 平台：Windows、macOS、Linux
+	PlatformVersion string           `json:"platform_version"` // UA-CH 平台版本
+	Architecture    string           `json:"architecture"`
+	NavigatorPlat   string           `json:"navigator_platform"` // navigator.platform：Win32、MacIntel、Linux x86_64
+	Brands          []UserAgentBrand `json:"brands"`             // 主版本号
+	FullVersionList []UserAgentBrand `json:"full_version_list"`  // 完整版本号
+}
+
+// newUserAgentProfile 按浏览器实际版本和当前系统生成 UA 和 UA-CH
+func newUserAgentProfile(version string) *UserAgentProfile {
+	if version == "" {
+		version = "131.0.0.0"
+	}
+	major, _, _ := strings.Cut(version, ".")
+
+	profile := &UserAgentProfile{
+		Platform:        "Windows",
+		PlatformVersion: "10.0.0",
+		Architecture:    "x86",
+		NavigatorPlat:   "Win32",
+	}
+	uaPlatform := "Windows NT 10.0; Win64; x64"
+	switch runtime.GOOS {
+	case "darwin":
+		uaPlatform = "Macintosh; Intel Mac OS X 10_15_7"
+		profile.Platform, profile.PlatformVersion, profile.NavigatorPlat = "macOS", "10.15.7", "MacIntel"
+	case "linux":
+		uaPlatform = "X11; Linux x86_64"
+		profile.Platform, profile.PlatformVersion, profile.NavigatorPlat = "Linux", "", "Linux x86_64"
+	}
+	profile.UserAgent = fmt.Sprintf("Mozilla/5.0 (%s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s.0.0.0 Safari/537.36", uaPlatform, major)
+	profile.Brands = []UserAgentBrand{
+		{Brand: "Google Chrome", Version: major},
+		{Brand: "Chromium", Version: major},
+		{Brand: "Not_A Brand", Version: "24"},
+	}
+	profile.FullVersionList = []UserAgentBrand{
+		{Brand: "Google Chrome", Version: version},
+		{Brand: "Chromium", Version: version},
+		{Brand: "Not_A Brand", Version: "24.0.0.0"},
+	}
+	return profile
+}
+
+// headers 每个请求携带的 UA-CH 低熵请求头，替换无头浏览器自带的 HeadlessChrome 品牌
+func (p *UserAgentProfile) headers() map[string]string {
+	brands := make([]string, len(p.Brands))
+	for i, brand := range p.Brands {
+		brands[i] = fmt.Sprintf("%q;v=%q", brand.Brand, brand.Version)
+	}
+	return map[string]string{
+		"sec-ch-ua":          strings.Join(brands, ", "),
+		"sec-ch-ua-mobile":   "?0",
+		"sec-ch-ua-platform": fmt.Sprintf("%q", p.Platform),
+	}
+}
+
+// initScript 覆盖页面中的 navigator.userAgentData 和 navigator.platform，与 UA 和请求头保持一致
+func (p *UserAgentProfile) initScript() string {
+	data, _ := json.Marshal(p)
+	return `(() => {
+	const profile = ` + string(data) + `;
+	const lowEntropy = { brands: profile.brands, mobile: false, platform: profile.platform };
+	const highEntropy = Object.assign({}, lowEntropy, {
+		architecture: profile.architecture,
+		bitness: '64',
+		model: '',
+		platformVersion: profile.platform_version,
+		uaFullVersion: profile.full_version_list[0].version,
+		fullVersionList: profile.full_version_list,
+		wow64: false,
+	});
+	const userAgentData = {
+		brands: profile.brands,
+		mobile: false,
+		platform: profile.platform,
+		getHighEntropyValues: (hints) => Promise.resolve(Object.fromEntries(
+			Object.entries(highEntropy).filter(([key]) => key in lowEntropy || (hints || []).includes(key)))),
+		toJSON: () => lowEntropy,
+	};
+	Object.defineProperty(Navigator.prototype, 'userAgentData', { get: () => userAgentData });
+	Object.defineProperty(Navigator.prototype, 'platform', { get: () => profile.navigator_platform });
+})();`
+}
+
+// readUserAgentProfile 读取页面实际使用的 UA 和 UA-CH，保存到认证状态中用于固定指纹
+func readUserAgentProfile(page playwright.Page) *UserAgentProfile {
+	result, err := page.Evaluate(`async () => {
+		const data = navigator.userAgentData;
+		const high = data ? await data.getHighEntropyValues(['architecture', 'platformVersion', 'fullVersionList']) : {};
+		return JSON.stringify({
+			user_agent: navigator.userAgent,
+			platform: data ? data.platform : '',
+			platform_version: high.platformVersion || '',
+			architecture: high.architecture || '',
+			navigator_platform: navigator.platform,
+			brands: data ? data.brands : [],
+			full_version_list: high.fullVersionList || [],
+		});
+	}`)
+	if err != nil {
+		log.Printf("⚠️ 读取UA信息失败: %v", err)
+		return nil
+	}
+	text, _ := result.(string)
+	var profile UserAgentProfile
+	if err := json.Unmarshal([]byte(text), &profile); err != nil || profile.UserAgent == "" || len(profile.FullVersionList) == 0 {
+		return nil
+	}
+	return &profile
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// videoChapter 切分出的一段：开始时间、结束时间（0 表示到视频结尾）和章节标题
+type videoChapter struct {
+	Start time.Duration
+	End   time.Duration
+	Title string
+}
+
+// SplitOptions 长视频切分选项
+type SplitOptions struct {
+	Video       string        // 原视频
+	Duration    time.Duration // 按固定时长切分
+	Chapters    string        // 章节文件，每行 "00:12:30 标题"，与 Duration 二选一
+	OutDir      string        // 切分后的视频目录
+	FFmpeg      string        // ffmpeg 可执行文件
+	Reencode    bool          // 重新编码以精确切分，默认直接复制流（在关键帧处切分，速度快）
+	Description string        // 描述模板
+	ShortTitle  string        // 短标题模板
+	Collection  string        // 所有片段加入的合集
+	Action      string        // 保存方式
+}
+
+// runSplitCommand 处理 split 子命令：用 ffmpeg 把长视频切分为多个片段，并生成每个片段一个任务的 CSV 任务文件
+func runSplitCommand(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	var options SplitOptions
+	var output string
+	fs.StringVar(&options.Video, "video", "", "需要切分的长视频")
+	fs.DurationVar(&options.Duration, "duration", 0, "按固定时长切分(例如 10m)")
+	fs.StringVar(&options.Chapters, "chapters", "", "按章节文件切分, 每行 \"00:12:30 标题\"")
+	fs.StringVar(&options.OutDir, "out-dir", "", "切分后的视频目录(默认与原视频同目录下的 <视频名>_clips)")
+	fs.StringVar(&options.FFmpeg, "ffmpeg", "ffmpeg", "ffmpeg 可执行文件路径")
+	fs.BoolVar(&options.Reencode, "reencode", false, "重新编码以精确切分(较慢), 默认直接复制在关键帧处切分")
+	fs.StringVar(&options.Description, "description", "第{{n}}集", "描述模板, 可用 {{n}} 序号、{{total}} 总集数、{{title}} 章节标题")
+	fs.StringVar(&options.ShortTitle, "short-title", "第{{n}}集", "短标题模板, 变量同 -description")
+	fs.StringVar(&options.Collection, "collection", "", "所有片段加入的合集, 按集数顺序添加")
+	fs.StringVar(&options.Action, "action", "发表", "保存方式: 发表 | 保存草稿 | 手机预览 | 仅上传不提交")
+	fs.StringVar(&output, "o", "", "生成的任务文件(CSV), - 表示输出到标准输出(默认 <视频名>_tasks.csv)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if options.Video == "" {
+		return fmt.Errorf("必须指定 -video")
+	}
+	if (options.Duration > 0) == (options.Chapters != "") {
+		return fmt.Errorf("-duration 和 -chapters 必须且只能指定一个")
+	}
+	if name, ok := taskActionNames[options.Action]; ok {
+		options.Action = name
+	}
+	if options.Action != "发表" && options.Action != "保存草稿" && options.Action != "手机预览" && options.Action != "仅上传不提交" {
+		return fmt.Errorf("不支持的保存方式: %s", options.Action)
+	}
+	if exists, err := checkFileExists(options.Video, ""); !exists {
+		return fmt.Errorf("错误: %v", err)
+	}
+	base := strings.TrimSuffix(filepath.Base(options.Video), filepath.Ext(options.Video))
+	if options.OutDir == "" {
+		options.OutDir = filepath.Join(filepath.Dir(options.Video), base+"_clips")
+	}
+	if output == "" {
+		output = filepath.Join(filepath.Dir(options.Video), base+"_tasks.csv")
+	}
+
+	clips, err := SplitVideo(options)
+	if err != nil {
+		return err
+	}
+
+	if output == stdinSource {
+		return writeSplitTasks(os.Stdout, clips, options)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("创建任务文件失败: %v", err)
+	}
+	defer f.Close()
+	if err := writeSplitTasks(f, clips, options); err != nil {
+		return err
+	}
+	log.Printf("✅ 已生成 %d 个任务: %s", len(clips), output)
+	return nil
+}
+
+// splitClip 切分后的片段
+type splitClip struct {
+	Path  string
+	Title string
+}
+
+// SplitVideo 按固定时长或章节切分视频，返回按顺序排列的片段
+func SplitVideo(options SplitOptions) ([]splitClip, error) {
+	if _, err := exec.LookPath(options.FFmpeg); err != nil {
+		return nil, fmt.Errorf("未找到 ffmpeg(可用 -ffmpeg 指定路径): %v", err)
+	}
+	if err := os.MkdirAll(options.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建切分目录失败: %v", err)
+	}
+	video, err := filepath.Abs(options.Video)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析视频路径: %v", err)
+	}
+	base := strings.TrimSuffix(filepath.Base(video), filepath.Ext(video))
+	ext := filepath.Ext(video)
+
+	if options.Duration > 0 {
+		return splitByDuration(options, video, filepath.Join(options.OutDir, base+"_%03d"+ext))
+	}
+
+	chapters, err := loadChapters(options.Chapters)
+	if err != nil {
+		return nil, err
+	}
+	clips := make([]splitClip, len(chapters))
+	for i, chapter := range chapters {
+		clipPath := filepath.Join(options.OutDir, fmt.Sprintf("%s_%03d%s", base, i+1, ext))
+		log.Printf("✂️ 切分第 %d/%d 段: %s (%s)", i+1, len(chapters), chapter.Title, formatClock(chapter.Start))
+		args := []string{"-y", "-hide_banner", "-loglevel", "error", "-ss", formatClock(chapter.Start), "-i", video}
+		if chapter.End > 0 {
+			args = append(args, "-t", formatClock(chapter.End-chapter.Start))
+		}
+		args = append(args, codecArgs(options.Reencode)...)
+		args = append(args, "-avoid_negative_ts", "make_zero", clipPath)
+		if err := runFFmpeg(options.FFmpeg, args); err != nil {
+			return nil, fmt.Errorf("切分第 %d 段失败: %v", i+1, err)
+		}
+		clips[i] = splitClip{Path: clipPath, Title: chapter.Title}
+	}
+	return clips, nil
+}
+
+// splitByDuration 用 segment 按固定时长切分
+func splitByDuration(options SplitOptions, video string, pattern string) ([]splitClip, error) {
+	// 片段按文件名收集，目录中已有旧片段时会混入本次结果
+	clipPattern := strings.Replace(pattern, "%03d", "[0-9][0-9][0-9]", 1)
+	if existing, _ := filepath.Glob(clipPattern); len(existing) > 0 {
+		return nil, fmt.Errorf("切分目录中已有 %d 个旧片段，请先清理或用 -out-dir 指定其他目录", len(existing))
+	}
+
+	log.Printf("✂️ 按每段 %v 切分: %s", options.Duration, filepath.Base(video))
+	seconds := strconv.FormatFloat(options.Duration.Seconds(), 'f', -1, 64)
+	args := []string{"-y", "-hide_banner", "-loglevel", "error", "-i", video, "-map", "0"}
+	args = append(args, codecArgs(options.Reencode)...)
+	if options.Reencode {
+		// 在每段开始处强制关键帧，切分点才能精确
+		args = append(args, "-force_key_frames", "expr:gte(t,n_forced*"+seconds+")")
+	}
+	args = append(args, "-f", "segment", "-segment_time", seconds, "-reset_timestamps", "1", pattern)
+	if err := runFFmpeg(options.FFmpeg, args); err != nil {
+		return nil, fmt.Errorf("切分视频失败: %v", err)
+	}
+
+	paths, err := filepath.Glob(clipPattern)
+	if err != nil || len(paths) == 0 {
+		return nil, fmt.Errorf("切分后没有生成视频片段")
+	}
+	sort.Strings(paths)
+	clips := make([]splitClip, len(paths))
+	for i, path := range paths {
+		clips[i] = splitClip{Path: path}
+	}
+	return clips, nil
+}
+
+// codecArgs 直接复制流或重新编码
+func codecArgs(reencode bool) []string {
+	if reencode {
+		return []string{"-c:v", "libx264", "-c:a", "aac"}
+	}
+	return []string{"-c", "copy"}
+}
+
+// runFFmpeg 执行 ffmpeg，失败时带上 ffmpeg 的错误输出
+func runFFmpeg(ffmpeg string, args []string) error {
+	output, err := exec.Command(ffmpeg, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// loadChapters 读取章节文件：每行 "时间 标题"，时间为 1:02:03、02:03 或秒数，# 开头为注释；
+// 每段到下一章节开始为止，最后一段到视频结尾
+func loadChapters(path string) ([]videoChapter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取章节文件失败: %v", err)
+	}
+	defer f.Close()
+
+	var chapters []videoChapter
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		clock, title, _ := strings.Cut(strings.Replace(line, "\t", " ", 1), " ")
+		start, err := parseClock(clock)
+		if err != nil {
+			return nil, fmt.Errorf("章节文件第%d行: %v", lineNumber, err)
+		}
+		if n := len(chapters); n > 0 {
+			if start <= chapters[n-1].Start {
+				return nil, fmt.Errorf("章节文件第%d行: 开始时间必须递增", lineNumber)
+			}
+			chapters[n-1].End = start
+		}
+		chapters = append(chapters, videoChapter{Start: start, Title: strings.TrimSpace(title)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取章节文件失败: %v", err)
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("章节文件中没有章节")
+	}
+	return chapters, nil
+}
+
+// parseClock 解析 1:02:03、02:03、75 或 1:02:03.5 形式的时间
+func parseClock(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("时间格式错误: %s", value)
+	}
+	var seconds float64
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("时间格式错误: %s", value)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// formatClock 格式化为 ffmpeg 的 HH:MM:SS.mmm
+func formatClock(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// writeSplitTasks 按片段顺序写出 CSV 任务文件，列与Excel任务表相同
+func writeSplitTasks(w io.Writer, clips []splitClip, options SplitOptions) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taskColumns); err != nil {
+		return fmt.Errorf("写入任务文件失败: %v", err)
+	}
+	total := strconv.Itoa(len(clips))
+	for i, clip := range clips {
+		replacer := strings.NewReplacer("{{n}}", strconv.Itoa(i+1), "{{total}}", total, "{{title}}", clip.Title)
+		path, err := filepath.Abs(clip.Path)
+		if err != nil {
+			path = clip.Path
+		}
+		row := taskRow(VideoCreateTask{
+			Description: strings.TrimSpace(replacer.Replace(options.Description)),
+			Collection:  options.Collection,
+			ShortTitle:  strings.TrimSpace(replacer.Replace(options.ShortTitle)),
+			Action:      options.Action,
+			VideoPath:   path,
+		})
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("写入任务文件失败: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("写入任务文件失败: %v", err)
+	}
+	return nil
+}
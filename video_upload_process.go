@@ -1,34 +1,126 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// processUserLogin 用户扫码登录并保存认证状态
-func processUserLogin() (*PageState, error) {
+// LoginOptions 扫码登录选项
+type LoginOptions struct {
+	Mode     string // 登录方式：qr | auth-file | cdp，为空时为 qr
+	Browser  BrowserOptions
+	QRAddr   string         // 不为空时通过HTTP提供二维码（无界面环境扫码）
+	QRFile   string         // 不为空时把二维码写入该 PNG 文件
+	AuthFile string         // auth-file 方式读取的认证状态文件；扫码登录时保存认证状态，下次运行未过期时跳过扫码
+	Auth     AuthConfig     // 认证状态文件的加密密钥和最短剩余有效期
+	CDPURL   string         // cdp 方式连接的浏览器调试地址
+	Notifier *Notifications // 需要扫码时发送 on_login_required 通知
+	Hooks    *Hooks         // -events 时输出需要扫码登录事件
+	Refresh  bool           // 运行中登录失效时重新扫码，不使用已保存的认证状态
+	Account  string         // 按账号列分组上传时要登录的视频号，扫码提示和通知中带上
+}
+
+// relogin 运行中登录失效时重新登录的选项：扫码登录时不再读取已失效的认证状态文件
+func (o LoginOptions) relogin() LoginOptions {
+	o.Refresh = true
+	return o
+}
+
+// ProcessOptions 视频任务处理选项
+type ProcessOptions struct {
+	Concurrent   bool
+	Browser      BrowserOptions
+	Page         PageOptions
+	Browsers     int                  // 并发模式下启动的浏览器进程数
+	OutputDir    string               // 日志等输出文件的根目录
+	TempDir      *RunTempDir          // 本次运行的临时目录
+	Notifier     *Notifications       // 任务失败时发送 on_failure 通知
+	History      *UploadHistory       // 成功上传的任务记入上传历史
+	SourceFile   string               // 任务来源文件，记入上传历史
+	Status       *SQLTaskSource       // 任务来源为数据库时回写任务状态
+	Hooks        *Hooks               // -events 时输出任务开始、步骤完成、任务结束事件
+	Commands     TaskCommandConfig    // 每个任务前后执行的命令
+	Pacing       PacingOptions        // 最终操作后的停留时间和任务间隔
+	Relogin      ReloginFunc          // 运行中登录失效时重新登录，为空时登录失效的任务直接失败
+	Location     LocationConfig       // 默认位置和位置校验方式
+	Schedule     ScheduleConfig       // 定时发表的分钟调整方式
+	FormFields   FormFieldsConfig     // 可选表单字段的执行顺序和不执行的字段
+	Strict       bool                 // 严格模式：可选字段填写失败时任务失败
+	UploadOnly   bool                 // 仅上传模式：只上传视频并保存草稿，不填写可选字段
+	UpdateDrafts bool                 // 更新草稿模式：在草稿箱中找到已上传的草稿填写表单，不上传视频
+	LoginBrowser *browserSession      // 登录时的浏览器，不为空时作为第一个浏览器直接用于上传，处理结束后关闭
+	Accounts     *AccountLogins       // 不为空时按账号列分组，填写了账号的任务登录该账号后处理
+	Proxies      *ProxyPool           // 按任务轮换代理时每个任务使用下一个代理，为空时使用 Browser 中的代理
+	Captcha      CaptchaOptions       // 出现安全验证时的等待时间
+	LiveView     LiveViewOptions      // 服务器模式下查看和操作任务页面
+	Debugger     *StepDebugger        // 单步调试，不为空时每个主要步骤前暂停等待确认
+	Files        FileStabilityOptions // 上传前检查视频文件是否已写完
+	PublishCheck PublishCheckOptions  // 重试时发表前检查作品是否已发表
+	TaskLog      TaskLogOptions       // 任务日志文件的追加写入、轮转和 latest.log
+	Stop         <-chan struct{}      // 服务停止时关闭：不再开始新的任务，已开始的任务完成后返回；为 nil 时不会停止
+
+	staged       *stagedPages        // 仅上传不提交的任务页面，显示浏览器时保持打开
+	collections  *createdCollections // 当前账号本次运行中已创建的合集
+	capabilities *formCapabilities   // 当前账号上传页面有哪些可选控件
+	breaker      *accountBreaker     // 账号连续登录失效、安全验证失败时熔断，队列消费模式下由调用方创建并在各消息之间共用
+}
+
+// processUserLogin 按登录方式获取认证状态：扫码登录、读取认证状态文件或连接已打开的浏览器
+func processUserLogin(options LoginOptions) (*PageState, error) {
+	switch options.Mode {
+	case LoginModeAuthFile:
+		return loadAuthFileLogin(options)
+	case LoginModeCDP:
+		return cdpLogin(options)
+	}
+
+	if pageState := loadLoginCache(options); pageState != nil {
+		return pageState, nil
+	}
+	pageState, session, err := loginWithBrowser(options)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("✅ 认证状态已保存，关闭浏览器...")
+	session.Close()
+	saveLoginCache(options, pageState)
+	return pageState, nil
+}
+
+// loginWithBrowser 扫码登录并保存认证状态，返回仍在运行的登录浏览器，可直接用于上传阶段，由调用方关闭
+func loginWithBrowser(options LoginOptions) (*PageState, *browserSession, error) {
 	// 生成浏览器
-	pw, browser, context, err := GenerateBrowser(false)
+	pw, browser, context, err := GenerateBrowser(options.Browser)
 	if err != nil {
 		log.Printf("❌ 启动 Playwright 失败: %v", err)
-		return nil, fmt.Errorf("启动 Playwright 失败: %v", err)
+		return nil, nil, fmt.Errorf("启动 Playwright 失败: %v", err)
 	}
-	defer pw.Stop()
-	defer (*browser).Close()
-	defer (*context).Close()
+	session := &browserSession{options: options.Browser, pw: pw, browser: browser, context: context}
+
+	// 二维码通过HTTP或 PNG 文件提供，并通知操作人员扫码
+	onQRCode, stopQRCode := loginQRHandler(options)
+	defer stopQRCode()
 
 	// 生成扫码登录页面
-	page, _, err := GeneratePage(context, true)
-	// 等待用户扫码登录
-	log.Println("⏰ 页面已打开, 您有10分钟时间完成扫码...")
+	log.Println("⏰ 页面打开后, 您有10分钟时间完成扫码...")
+	page, err := GenerateLoginPage(context, onQRCode)
 	if err != nil {
-		return nil, fmt.Errorf("登录失败: %v", err)
+		session.Close()
+		return nil, nil, fmt.Errorf("登录失败: %v", err)
 	}
 	defer (*page).Close()
 
@@ -36,183 +128,724 @@ func processUserLogin() (*PageState, error) {
 	log.Println("✅ 登录成功！正在保存认证状态...")
 	pageState, err := SaveAuthState(*page, *context)
 	if err != nil {
-		return nil, fmt.Errorf("保存认证状态失败: %v", err)
+		session.Close()
+		return nil, nil, fmt.Errorf("保存认证状态失败: %v", err)
 	}
-
-	log.Println("✅ 认证状态已保存，关闭浏览器...")
-	return pageState, nil
+	// 浏览器崩溃后重新启动时恢复该认证状态
+	session.authState = pageState
+	return pageState, session, nil
 }
 
 // ProcessVideoCreateTask 处理视频创建任务
-func ProcessVideoCreateTask(videoCreateTasks []VideoCreateTask, authState *PageState, concurrent bool, headless bool) []VideoCreateTask {
+func ProcessVideoCreateTask(videoCreateTasks []VideoCreateTask, authState *PageState, options ProcessOptions) (results []TaskResult) {
 	log.Printf("🚀 开始处理视频上传任务，共 %d 个任务", len(videoCreateTasks))
+	results = newTaskResults(videoCreateTasks)
 
-	// 创建日志文件
-	logFile, err := createLogFile()
+	ctx, span := tracer.Start(context.Background(), "run", trace.WithAttributes(
+		attribute.Int("run.tasks", len(videoCreateTasks)),
+		attribute.Bool("run.concurrent", options.Concurrent),
+		attribute.Int("run.browsers", options.Browsers),
+	))
+	defer func() {
+		failed := 0
+		for _, result := range results {
+			if !result.Success {
+				failed++
+			}
+		}
+		span.SetAttributes(attribute.Int("run.failed", failed))
+		var err error
+		if failed > 0 {
+			err = fmt.Errorf("%d 个任务失败", failed)
+		}
+		endSpan(span, err)
+	}()
+
+	// 从运行开始观察视频文件，执行到任务时判断是否仍在写入
+	options.Files.Observe(videoCreateTasks)
+
+	// 任务状态文件，异常退出时保留进度
+	state := NewRunState(options.OutputDir, videoCreateTasks)
+	state.Flush()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("💥 处理任务时发生内部错误: %v\n%s", r, debug.Stack())
+			for i := range results {
+				if !results[i].Success && results[i].Error == "" {
+					results[i].Error = fmt.Sprintf("内部错误: %v", r)
+					results[i].ErrorCategory = ErrorCategoryInternal
+					state.Finish(i, results[i])
+				}
+			}
+			state.Flush()
+		}
+	}()
+
+	// 创建日志文件，并发任务的结果经日志管道顺序写入，返回前写完
+	file, err := createLogFile(options.OutputDir, options.TaskLog)
 	if err != nil {
 		log.Printf("❌ 创建日志文件失败: %v", err)
 		return nil
 	}
-	defer logFile.Close()
+	defer file.Close()
+	defer logs.Flush(file)
+	logFile := logs.Writer(file)
+	// 同时写入 CSV 日志，便于在 Excel 中按行号、账号、步骤筛选
+	if csvFile, err := createCSVLog(file.Name(), options.TaskLog.Name != ""); err != nil {
+		log.Printf("⚠️ %v", err)
+	} else {
+		defer csvFile.Close()
+		defer logs.Flush(csvFile)
+		defer startCSVLog(csvFile)()
+	}
 
-	// 创建共享pw, 浏览器、上下文
-	pw, browser, context, err := GenerateBrowser(headless)
-	if err != nil {
-		log.Printf("❌ 创建浏览器失败: %v", err)
-		return nil
+	// 浏览器下载的文件放到本次运行的临时目录
+	if options.TempDir != nil {
+		if downloads, err := options.TempDir.SubDir("downloads"); err == nil {
+			options.Browser.Downloads = downloads
+		}
+	}
+
+	// 服务器模式下通过页面查看服务查看正在执行的任务页面，卡住时可介入操作
+	if options.LiveView.Addr != "" {
+		if server, err := NewLiveViewServer(options.LiveView); err != nil {
+			log.Printf("⚠️ %v，不启动页面查看服务", err)
+		} else {
+			options.LiveView.server = server
+			server.Start()
+			defer server.Stop()
+		}
 	}
-	// 恢复从扫码登录获取的授权信息
-	restoreAuthState(*context, authState)
-	// 延迟关闭
-	defer pw.Stop()
-	defer (*browser).Close()
-	defer (*context).Close()
 
-	if !concurrent {
+	// 仅上传不提交的页面在显示浏览器时保持打开
+	options.staged = newStagedPages(options.Browser.Headless)
+	// 同一账号连续登录失效、安全验证失败时不再执行该账号剩余的任务
+	if options.breaker == nil {
+		options.breaker = newAccountBreaker()
+	}
+	// 仅上传模式不填写可选字段，不需要探测
+	if options.UploadOnly {
+		log.Println("📥 仅上传模式：只上传视频并保存草稿，可选字段之后在网页中补充")
+	}
+
+	// 填写了账号列时按账号分组，每个账号登录后在自己的浏览器上下文中依次处理；未填写账号的任务使用 -login 登录的账号
+	groups := []accountGroup{{Indexes: pendingTasks(len(results))}}
+	if options.Accounts != nil {
+		groups = groupResultsByTaskAccount(results)
+	}
+	for _, group := range groups {
+		if options.stopped() {
+			markTasksFailed(results, group.Indexes, errStopped, logFile, state, "", options)
+			continue
+		}
+		groupState, groupOptions := authState, options
+		if group.Account != "" {
+			log.Printf("👤 账号 %s: %d 个任务", group.Account, len(group.Indexes))
+			proxy, err := options.Accounts.Proxy(group.Account)
+			if err == nil {
+				groupState, err = options.Accounts.Login(group.Account, proxy)
+			}
+			if err != nil {
+				log.Printf("❌ %v", err)
+				markTasksFailed(results, group.Indexes, err, logFile, state, "", options)
+				continue
+			}
+			if proxy != nil {
+				groupOptions.Browser.Proxy = proxy
+			}
+			groupOptions.LoginBrowser = nil
+			groupOptions.Relogin = options.Accounts.Relogin(group.Account, proxy)
+		}
+		if err := processTaskGroup(ctx, group.Indexes, groupState, results, logFile, state, groupOptions); err != nil {
+			log.Printf("❌ %v", err)
+			markTasksFailed(results, group.Indexes, err, logFile, state, "", options)
+		}
+	}
+
+	if path, err := writePreviewSheet(results, options.OutputDir); err != nil {
+		log.Printf("⚠️ %v", err)
+	} else if path != "" {
+		log.Printf("📱 手机预览二维码已汇总到: %s（可发给审核人员依次扫码，或打印为 PDF）", path)
+	}
+
+	// 汇总写入日志文件和状态文件
+	selectorStats.Save()
+	summary := summarizeResults(results)
+	writeLogSummary(logFile, summary)
+	state.SetSummary(summary)
+	return results
+}
+
+// processTaskGroup 在同一账号的浏览器上下文中处理 indexes 中的任务，结果写入 results；
+// 无法创建浏览器时返回错误，由调用方将这些任务标记为失败
+func processTaskGroup(ctx context.Context, indexes []int, authState *PageState, results []TaskResult, logFile io.Writer, state *RunState, options ProcessOptions) error {
+	// 多行创建同一个合集时只创建一次
+	options.collections = newCreatedCollections()
+	// 第一个任务探测上传页面有哪些可选控件，之后的任务跳过没有的控件
+	if !options.UploadOnly {
+		options.capabilities = newFormCapabilities()
+	}
+
+	// 创建浏览器、上下文，并发模式下可分布到多个独立的浏览器进程
+	browserCount := 1
+	if options.Concurrent && options.Browsers > 1 {
+		browserCount = options.Browsers
+	}
+	var sessions []*browserSession
+	if options.LoginBrowser != nil {
+		// 沿用登录的浏览器上下文，不需要重新启动和恢复认证
+		log.Println("♻️ 沿用登录时的浏览器处理上传任务")
+		defer options.LoginBrowser.Close()
+		sessions = append(sessions, options.LoginBrowser)
+	}
+	if options.Proxies.PerTask() {
+		// 上传页面和第一个任务使用第一个代理，之后每个任务轮换
+		proxy, err := options.Proxies.NextTask()
+		if err != nil {
+			return fmt.Errorf("获取代理失败: %v", err)
+		}
+		options.Browser.Proxy = proxy
+	}
+	for i := len(sessions); i < browserCount; i++ {
+		session, err := newBrowserSession(options.Browser, authState)
+		if err != nil {
+			log.Printf("❌ 创建第 %d 个浏览器失败: %v", i+1, err)
+			continue
+		}
+		defer session.Close()
+		sessions = append(sessions, session)
+	}
+	if len(sessions) == 0 {
+		return fmt.Errorf("创建浏览器失败")
+	}
+	if browserCount > 1 {
+		log.Printf("🌐 已启动 %d/%d 个浏览器进程", len(sessions), browserCount)
+	}
+	// 登录失效时暂停任务、重新登录并恢复到所有浏览器
+	reauth := newReauthenticator(options.Relogin, sessions, authState)
+
+	if !options.Concurrent {
 		// 处理顺序上传
 		log.Printf("🚀 开始顺序处理视频上传任务")
-		videoCreateTasks = processTaskSequential(context, videoCreateTasks, logFile)
+		processTaskSequential(ctx, sessions[0], reauth, indexes, results, logFile, state, options)
 	} else {
-		// 并发上传
+		// 并发上传，带宽总上限平均分配到每个页面
 		log.Printf("🚀 开始并行处理视频上传任务")
-		videoCreateTasks = processTaskConcurrent(context, videoCreateTasks, logFile)
+		options.Page.MaxUploadMbps /= float64(concurrencyFor(len(indexes)))
+		processTaskConcurrent(ctx, sessions, reauth, indexes, results, logFile, state, options)
 	}
-	return videoCreateTasks
+
+	// 关闭浏览器前等待操作人员处理仅上传不提交的页面
+	options.staged.wait()
+	return nil
 }
 
-// processTaskSequential 处理顺序上传
-func processTaskSequential(context *playwright.BrowserContext, videoCreateTasks []VideoCreateTask, logFile *os.File) []VideoCreateTask {
+// processTaskSequential 处理顺序上传 indexes 中的任务，结果写入 results
+func processTaskSequential(ctx context.Context, session *browserSession, reauth *reauthenticator, indexes []int, results []TaskResult, logFile io.Writer, state *RunState, options ProcessOptions) {
 	// 生成视频上传页面
-	page, channelName, pageError := GeneratePage(context, false)
+	context, generation := session.Context()
+	loginGeneration := reauth.Generation()
+	page, channelName, pageError := GeneratePage(context, options.Page)
+	if pageError != nil && reauth.canRetry(pageError.Error()) {
+		// 登录失效时重新登录后再生成一次
+		if page != nil {
+			(*page).Close()
+		}
+		if err := reauth.Reauth(loginGeneration); err != nil {
+			pageError = fmt.Errorf("%v; %v", pageError, err)
+		} else {
+			page, channelName, pageError = GeneratePage(context, options.Page)
+		}
+	}
 	if pageError != nil {
 		log.Printf("❌ 创建上传页面失败或登录失效: %v", pageError)
 		// 保存上传处理结果
-		videoCreateTasks[1].Success = false
-		videoCreateTasks[1].Error = pageError.Error()
-		writeLogFile(logFile, videoCreateTasks[1], channelName)
-		return videoCreateTasks
+		markTasksFailed(results, indexes, pageError, logFile, state, channelName, options)
+		return
 	}
 
-	defer (*page).Close()
-	for i := range videoCreateTasks {
-		// 上传视频和填充值表单并保存
-		videoCreateTasks[i] = createVideo(page, videoCreateTasks[i])
+	defer func() {
+		if page != nil {
+			(*page).Close()
+		}
+	}()
+	skipped := make(map[int]bool) // 合集中前一集失败而跳过的任务
+	// 执行顺序：视频文件仍在写入的任务移到队列末尾，先执行其他任务
+	order := slices.Clone(indexes)
+	deferrals := make(map[int]int)
+	for n := 0; n < len(order); n++ {
+		i := order[n]
+		if skipped[i] {
+			continue
+		}
+		if options.stopped() {
+			markTasksFailed(results, notSkipped(order[n:], skipped), errStopped, logFile, state, channelName, options)
+			return
+		}
+		if deferTask(results[i].Task, n, len(order), deferrals[i], options) {
+			deferrals[i]++
+			order = append(order, i)
+			continue
+		}
+		if n > 0 && options.Proxies.PerTask() {
+			switchTaskProxy(session, &page, options.Proxies)
+		}
+		var abortErr error
+		results[i] = runTaskSafely(ctx, state, options, i, results[i].Task, func(result *TaskResult) {
+			loginGeneration := reauth.Generation()
+			abortErr = runSequentialTask(session, &page, &channelName, &generation, result, options)
+			if result.Success || !reauth.canRetry(result.Error) {
+				return
+			}
+
+			// 登录失效时重新登录后重试当前任务，避免后续任务全部失败
+			log.Printf("🔐 第%d行任务登录信息失效，重新登录后重试: %s", result.Task.RowIndex, result.Error)
+			result.Event("relogin", errors.New(result.Error))
+			if page != nil {
+				(*page).Close()
+				page = nil
+			}
+			if err := reauth.Reauth(loginGeneration); err != nil {
+				abortErr = err
+				result.Fail(fmt.Errorf("%s; %v", result.Error, err))
+				return
+			}
+			abortErr = runSequentialTask(session, &page, &channelName, &generation, result, options)
+		})
+
 		// 保存上传处理结果
-		writeLogFile(logFile, videoCreateTasks[i], channelName)
-		// 刷新页面重试
-		(*page).Reload()
-		time.Sleep(3 * time.Second)
+		finishTask(logFile, results[i], options)
+		if abortErr == nil && options.breaker.Record(results[i], options.Notifier) {
+			abortErr = options.breaker.Err(resultAccount(results[i]))
+		}
+		if abortErr != nil {
+			markTasksFailed(results, notSkipped(order[n+1:], skipped), abortErr, logFile, state, channelName, options)
+			return
+		}
+		// 合集中一集失败后不再发表后续各集，保证集数顺序
+		if !results[i].Success {
+			later := laterInCollection(results, i)
+			skipCollectionTasks(results, later, results[i], logFile, state, options)
+			for _, j := range later {
+				skipped[j] = true
+			}
+		}
+		// 重置页面并等待后开始下一个任务
+		if n < len(order)-1 {
+			options.Pacing.betweenTasks(page)
+		}
 	}
-	return videoCreateTasks
 }
 
-// processTaskConcurrent 视频并发上传
-func processTaskConcurrent(context *playwright.BrowserContext, videoCreateTasks []VideoCreateTask, logFile *os.File) []VideoCreateTask {
-	// 并发数
+// switchTaskProxy 按任务轮换代理时关闭当前页面并切换到下一个代理，由下一个任务重新生成页面；
+// 没有可用代理或切换失败时继续使用当前代理
+func switchTaskProxy(session *browserSession, page **playwright.Page, proxies *ProxyPool) {
+	proxy, err := proxies.NextTask()
+	if err == nil {
+		if *page != nil {
+			(**page).Close()
+			*page = nil
+		}
+		err = session.SwitchProxy(proxy)
+	}
+	if err != nil {
+		log.Printf("⚠️ 切换代理失败，继续使用当前代理: %v", err)
+	}
+}
+
+// runSequentialTask 顺序模式下在共用的页面中执行一个任务，页面已关闭时重新生成，
+// 页面或浏览器崩溃时恢复后重试；返回的错误表示无法继续处理后续任务
+func runSequentialTask(session *browserSession, page **playwright.Page, channelName *string, generation *int, result *TaskResult, options ProcessOptions) error {
+	var context *playwright.BrowserContext
+	result.beginAttempt()
+	// 上一个任务异常后页面可能已关闭，重新生成
+	if *page == nil || (**page).IsClosed() {
+		context, *generation = session.Context()
+		var pageError error
+		result.Step(StepNavigation, func() error {
+			*page, *channelName, pageError = GeneratePage(context, options.Page)
+			return pageError
+		})
+		if pageError != nil {
+			result.Fail(pageError)
+			return pageError
+		}
+	}
+	result.ChannelName = *channelName
+
+	// 上传视频和填充值表单并保存
+	err := createVideo(*page, result, options)
+
+	// 页面或浏览器崩溃时恢复后重试当前任务，避免后续任务全部失败
+	if err != nil && session.isCrashed(*page, err.Error()) {
+		log.Printf("💥 第%d行任务执行中页面或浏览器崩溃，恢复后重试: %v", result.Task.RowIndex, err)
+		result.Event("crash_recovery", err)
+		result.Fail(err)
+		(**page).Close()
+		*page = nil
+		if err := session.Recover(*generation); err != nil {
+			result.Fail(err)
+			return err
+		}
+		context, *generation = session.Context()
+		result.beginAttempt()
+		var pageError error
+		result.Step(StepNavigation, func() error {
+			*page, *channelName, pageError = GeneratePage(context, options.Page)
+			return pageError
+		})
+		if pageError != nil {
+			log.Printf("❌ 恢复后创建上传页面失败: %v", pageError)
+			result.Fail(pageError)
+			return pageError
+		}
+		err = createVideo(*page, result, options)
+	}
+	if err != nil {
+		result.Fail(err)
+		return nil
+	}
+	options.Pacing.waitAfterAction()
+	result.Success = true
+	result.Error = ""
+	// 保留的页面交给操作人员，下一个任务重新生成页面
+	if result.Staged && options.staged != nil {
+		*page = nil
+	}
+	return nil
+}
+
+// markTasksFailed 将未执行的任务标记为失败、写日志、回写数据库状态，并合并为一条失败通知
+func markTasksFailed(results []TaskResult, indexes []int, err error, logFile io.Writer, state *RunState, channelName string, options ProcessOptions) {
+	if len(indexes) == 0 {
+		return
+	}
+	failed := make([]TaskResult, 0, len(indexes))
+	for _, i := range indexes {
+		results[i].ChannelName = channelName
+		results[i].Fail(err)
+		results[i].classify()
+		state.Finish(i, results[i])
+		writeLogFile(logFile, results[i])
+		options.Status.WriteStatus(results[i])
+		options.Hooks.taskCompleted(results[i])
+		failed = append(failed, results[i])
+	}
+	options.Notifier.Notify(failureEvent(failed...))
+}
+
+// errStopped 服务停止时尚未开始执行的任务的错误
+var errStopped = errors.New("服务停止，任务未开始执行")
+
+// stopped 是否已收到服务停止请求
+func (o ProcessOptions) stopped() bool {
+	select {
+	case <-o.Stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// notSkipped indexes 中没有因合集前一集失败而跳过的任务
+func notSkipped(indexes []int, skipped map[int]bool) []int {
+	var remaining []int
+	for _, i := range indexes {
+		if !skipped[i] {
+			remaining = append(remaining, i)
+		}
+	}
+	return remaining
+}
+
+// pendingTasks 按表格顺序的任务序号 0..count-1
+func pendingTasks(count int) []int {
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// deferTask 顺序模式下视频文件仍在写入时是否将任务移到队列末尾：第 n 个执行、共 total 个，已移过 deferred 次；
+// 合集中的任务需按顺序发表、最后一个任务没有可先执行的任务，都在上传前等待写入完成
+func deferTask(task VideoCreateTask, n int, total int, deferred int, options ProcessOptions) bool {
+	if task.Collection != "" || n == total-1 || deferred >= maxFileDeferrals {
+		return false
+	}
+	path, reason := options.Files.Unstable(task)
+	if reason == "" {
+		return false
+	}
+	log.Printf("⏳ 第%d行的视频文件未写完(%s): %s，先执行后面的任务", task.RowIndex, reason, filepath.Base(path))
+	return true
+}
+
+// concurrencyFor 根据任务数量计算并发数
+func concurrencyFor(taskCount int) int {
 	maxConcurrency := 3
-	if len(videoCreateTasks) > 50 && len(videoCreateTasks) < 100 {
+	if taskCount > 50 && taskCount < 100 {
 		maxConcurrency = 5
 	}
-	if len(videoCreateTasks) > 100 {
+	if taskCount > 100 {
 		maxConcurrency = 10
 	}
+	return maxConcurrency
+}
+
+// processTaskConcurrent 视频并发上传，结果写入 results
+func processTaskConcurrent(ctx context.Context, sessions []*browserSession, reauth *reauthenticator, indexes []int, results []TaskResult, logFile io.Writer, state *RunState, options ProcessOptions) {
+	// 并发数
+	maxConcurrency := concurrencyFor(len(indexes))
 	// 并发处理
 	semaphore := make(chan struct{}, maxConcurrency)
 	var wg sync.WaitGroup
-	for i := range videoCreateTasks {
+	// 同一合集的任务在同一个并发槽位中按表格顺序串行执行
+	for _, lane := range collectionLanes(results, indexes) {
 		wg.Add(1)
 		semaphore <- struct{}{}
 
-		videoCreateTask := videoCreateTasks[i]
-		index := i
-
-		go func(videoCreateTask VideoCreateTask, index int) {
+		go func(lane []int) {
 			defer wg.Done()
 			defer func() { <-semaphore }()
-			log.Printf("🚀 开始执行第 %d 个任务: %s", index+1, filepath.Base(videoCreateTask.VideoPath))
-			// 生成上传视频页面 - 每一个协和生成一个页面
-			page, channelName, pageError := GeneratePage(context, false)
-			videoCreateTask.Page = page
-			videoCreateTask.ChannelName = channelName
-			defer (*page).Close()
-			if pageError == nil {
-				// 上传视频和填充值表单并保存
-				videoCreateTask = createVideo(page, videoCreateTasks[i])
-			} else {
-				videoCreateTask.Success = false
-				videoCreateTask.Error = pageError.Error()
+			for k, index := range lane {
+				if options.stopped() {
+					markTasksFailed(results, lane[k:], errStopped, logFile, state, "", options)
+					return
+				}
+				videoCreateTask := results[index].Task
+				log.Printf("🚀 开始执行第 %d 个任务: %s", index+1, filepath.Base(videoCreateTask.VideoPath))
+				// 生成上传视频页面 - 每一个协和生成一个页面，按任务序号轮流分配到各浏览器
+				// 页面或浏览器崩溃时恢复后重试，登录失效时重新登录后重试，不影响其他任务
+				// 任务内部panic时标记为内部错误，不影响其他任务
+				session := sessions[index%len(sessions)]
+				result := runTaskSafely(ctx, state, options, index, videoCreateTask, func(result *TaskResult) {
+					runTaskOnNewPage(session, result, options, reauth)
+				})
+				// 保存上传处理结果
+				finishTask(logFile, result, options)
+				options.breaker.Record(result, options.Notifier)
+				results[index] = result
+				if !result.Success {
+					skipCollectionTasks(results, lane[k+1:], result, logFile, state, options)
+					return
+				}
 			}
-			// 保存上传处理结果
-			writeLogFile(logFile, videoCreateTask, channelName)
-			videoCreateTasks[index] = videoCreateTask
-		}(videoCreateTask, index)
+		}(lane)
 	}
 	wg.Wait()
 	log.Println("✅ 所有上传任务完成")
-	return videoCreateTasks
 }
 
-func createVideo(page *playwright.Page, videoCreateTask VideoCreateTask) VideoCreateTask {
+// createVideo 上传视频并填写表单、执行最终操作，记录各步骤耗时、最终显示的位置和定时分钟的调整
+func createVideo(page *playwright.Page, result *TaskResult, options ProcessOptions) error {
+	// 账号已熔断时不再操作页面
+	if err := options.breaker.Err(resultAccount(*result)); err != nil {
+		return err
+	}
+	// 填写了账号列时不在其他账号下发表
+	if err := checkTaskAccount(taskAccount(result.Task), result.ChannelName); err != nil {
+		return err
+	}
+	location := options.Location
+	videoCreateTask := result.Task
+	// 崩溃恢复或重新登录后重试时清除上一次执行的记录
+	result.Location, result.ScheduleAdjustment, result.ManualSchedule, result.AlreadyPublished, result.Draft = "", "", "", "", ""
+	onStep := options.Debugger.wrap(*page, result, captchaStep(*page, result, options, snapshotStep(*page, result, options, result.Step)))
+	options.LiveView.server.Register(liveViewID(result), *page)
+	defer options.LiveView.server.Unregister(liveViewID(result))
+	if videoCreateTask.Location == "" {
+		videoCreateTask.Location = location.Default
+	}
+	// 仅上传模式只保留描述，其他字段由编辑人员之后在网页中补充
+	if options.UploadOnly {
+		videoCreateTask = uploadOnlyTask(videoCreateTask)
+	}
+	// 话题追加到描述末尾，之后的发表检查、定时校验都按完整的描述匹配
+	videoCreateTask.Description = descriptionWithTags(videoCreateTask.Description, videoCreateTask.Tags)
 
-	// 1. 上传视频文件
-	err := uploadVideo(*page, videoCreateTask.VideoPath)
+	// 队列和重试中的任务可能在校验很久之后才执行，执行前按定时规则再校验一次
+	if _, err := scheduleRules.Validate(videoCreateTask.Schedule, videoCreateTask.ScheduleTime, videoCreateTask.Action, time.Now()); err != nil {
+		return err
+	}
+
+	// 0. 进入内容类型对应的创建流程
+	contentType := videoCreateTask.ContentType
+	if contentType == "" {
+		contentType = ContentTypeVideo
+	}
+	if options.UpdateDrafts {
+		// 更新草稿：在草稿箱中按文件名或标题找到已上传的草稿进入编辑，不再上传视频
+		err := runStep(onStep, StepOpenDraft, func() error {
+			var err error
+			result.Draft, err = openDraft(*page, videoCreateTask)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := runStep(onStep, StepContentType, func() error { return enterContentFlow(*page, contentType) }); err != nil {
+			return err
+		}
+
+		// 1. 上传视频文件，图文动态按顺序上传图片；文件仍在写入时等待写完，避免上传截断的视频
+		if err := options.Files.WaitStable(videoCreateTask); err != nil {
+			return err
+		}
+		err := runStep(onStep, StepFileUpload, func() error {
+			if contentType == ContentTypeImage {
+				result.UploadBytes = mediaSize(videoCreateTask.ImagePaths)
+				return uploadImages(*page, videoCreateTask.ImagePaths)
+			}
+			result.UploadBytes = mediaSize([]string{videoCreateTask.VideoPath})
+			return uploadVideo(*page, videoCreateTask.VideoPath)
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	// 2. 填充页面其他字段, 包括点击保存
-	if err == nil {
-		uploadOptions := VideoUploadOptions{
-			Description:  videoCreateTask.Description,
-			Location:     videoCreateTask.Location,
-			Collection:   videoCreateTask.Collection,
-			Link:         videoCreateTask.Link,
-			Activity:     videoCreateTask.Activity,
-			Schedule:     videoCreateTask.Schedule,
-			ScheduleTime: videoCreateTask.ScheduleTime,
-			ShortTitle:   videoCreateTask.ShortTitle,
-			Action:       videoCreateTask.Action,
-		}
-		err = completeVideoUploadForm(*page, uploadOptions)
+	uploadOptions := VideoUploadOptions{
+		Description:    videoCreateTask.Description,
+		Location:       videoCreateTask.Location,
+		Collection:     videoCreateTask.Collection,
+		Collections:    options.collections,
+		Link:           videoCreateTask.Link,
+		Activity:       videoCreateTask.Activity,
+		Schedule:       videoCreateTask.Schedule,
+		ScheduleTime:   videoCreateTask.ScheduleTime,
+		ShortTitle:     videoCreateTask.ShortTitle,
+		Action:         videoCreateTask.Action,
+		Music:          videoCreateTask.Music,
+		CoverCrop:      videoCreateTask.CoverCrop,
+		CoverImage:     videoCreateTask.CoverImage,
+		Fields:         options.FormFields,
+		Absent:         options.capabilities.detect(*page, contentType, result.ChannelName),
+		Extra:          videoCreateTask.Extra,
+		OnStep:         onStep,
+		LocationStrict: location.Strict,
+		Strict:         options.Strict,
+		OnLocation:     func(displayed string) { result.Location = displayed },
+		Minutes: minuteSelection{
+			Rounding: options.Schedule.MinuteRounding,
+			OnAdjust: func(requested int, actual int) {
+				result.ScheduleAdjustment = fmt.Sprintf("分钟 %02d 调整为 %02d", requested, actual)
+			},
+		},
+		ScheduleFallback:   options.Schedule.Fallback,
+		OnScheduleFallback: func(err error) { result.ManualSchedule = err.Error() },
 	}
-	if err != nil {
-		videoCreateTask.Success = false
-		videoCreateTask.Error = err.Error()
+	var published string
+	if options.PublishCheck.enabled(result) {
+		uploadOptions.CheckPublished = func(scheduledAt time.Time) (string, error) {
+			var err error
+			published, err = findPublishedPost(*page, videoCreateTask.Description, videoCreateTask.ShortTitle, scheduledAt, options.PublishCheck.Window)
+			return published, err
+		}
+	}
+	err := completeVideoUploadForm(*page, uploadOptions)
+	// 上一次执行已发表的作品按成功处理
+	if errors.Is(err, errAlreadyPublished) {
+		result.AlreadyPublished = published
+		return nil
+	}
+	if err == nil && options.UploadOnly {
+		result.UploadOnly = true
+	}
+	if err == nil && videoCreateTask.Action == actionStage {
+		stageTask(*page, result, options)
+	}
+	// 手机预览的二维码汇总到一个页面，供审核人员依次扫码
+	if err == nil && videoCreateTask.Action == "preview" {
+		if path, qrErr := capturePreviewQR(*page, result, options.OutputDir); qrErr != nil {
+			log.Printf("⚠️ %v", qrErr)
+		} else {
+			log.Printf("📱 已保存预览二维码: %s", path)
+			result.PreviewQR = path
+			result.Artifacts = append(result.Artifacts, path)
+		}
+	}
+	return err
+}
+
+// stageTask 仅上传不提交：保存表单截图，显示浏览器时保留页面，由调用方不再关闭或复用该页面
+func stageTask(page playwright.Page, result *TaskResult, options ProcessOptions) {
+	result.Staged = true
+	if path, err := saveStagedScreenshot(page, result, options.OutputDir); err != nil {
+		log.Printf("⚠️ %v", err)
 	} else {
-		videoCreateTask.Success = true
+		log.Printf("📸 已保存填写完成的表单截图: %s", path)
+		result.Artifacts = append(result.Artifacts, path)
 	}
-	return videoCreateTask
+	options.staged.keep(result.Task.RowIndex, page)
 }
 
-// createLogFile 创建日志文件
-// createLogFile 创建日志文件
-func createLogFile() (*os.File, error) {
+// createLogFile 创建日志文件，固定名称时追加写入（超过大小先轮转）
+func createLogFile(outputDir string, options TaskLogOptions) (*os.File, error) {
 	// 确保log目录存在
-	logDir := "log"
+	logDir := filepath.Join(outputDir, "log")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("创建日志目录失败: %v", err)
 	}
 
 	// 创建日志文件
-	logFilename := filepath.Join(logDir, fmt.Sprintf("wechat_channel_uploader_%s.log",
-		time.Now().Format("20060102_150405")))
+	logFilename := options.logPath(logDir, time.Now().Format("20060102_150405"))
+	if options.Name != "" {
+		if err := rotateLogFile(logFilename, options.MaxSize); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}
 
-	logFile, err := os.Create(logFilename)
+	logFile, err := options.openFile(logFilename)
 	if err != nil {
 		return nil, fmt.Errorf("创建日志文件失败: %v", err)
 	}
+	if options.Latest {
+		linkLatestLog(logFilename)
+	}
 
 	log.Printf("✅ 日志文件创建成功: %s", logFilename)
 	return logFile, nil
 }
 
+// writeLogSummary 在日志文件末尾写入本次运行的汇总
+func writeLogSummary(logFile io.Writer, summary RunSummary) {
+	// 一次写入，避免与其他日志交错
+	lines := append([]string{"📊 ===== 上传结果统计 ====="}, summary.Lines()...)
+	io.WriteString(logFile, strings.Join(lines, "\n")+"\n")
+}
+
+// finishTask 任务结束后写日志、记录上传历史、回写数据库状态、输出任务结束事件，失败时发送通知
+func finishTask(logFile io.Writer, result TaskResult, options ProcessOptions) {
+	writeLogFile(logFile, result)
+	options.History.Record(result, options.SourceFile)
+	options.Status.WriteStatus(result)
+	options.Hooks.taskCompleted(result)
+	// 熔断后跳过的任务已在熔断通知中说明，不再逐个通知
+	if !result.Success && result.ErrorCategory != ErrorCategoryBreaker {
+		options.Notifier.Notify(failureEvent(result))
+	}
+}
+
 // writeLogFile 写日志文件
-func writeLogFile(logFile *os.File, videoCreateTask VideoCreateTask, channelName string) {
+func writeLogFile(logFile io.Writer, result TaskResult) {
 	// 记录到日志文件
 	logMessage := ""
-	if videoCreateTask.Success == false {
+	if !result.Success {
 		logMessage = fmt.Sprintf("❌ %s: 视频号：%s, 第%d行上传失败: %s - 错误: %v\n",
-			time.Now().Format("20060102_150405"), channelName, videoCreateTask.RowIndex, videoCreateTask.VideoPath, videoCreateTask.Error)
+			time.Now().Format("20060102_150405"), result.ChannelName, result.Task.RowIndex, result.Task.VideoPath, result.Error)
+		if result.Trace != "" {
+			logMessage += fmt.Sprintf("   步骤: %s\n", result.Trace)
+		}
 	} else {
 		logMessage = fmt.Sprintf("✅ %s: 视频号：%s, 第%d行上传成功: %s\n",
-			time.Now().Format("20060102_150405"), channelName, videoCreateTask.RowIndex, videoCreateTask.VideoPath)
+			time.Now().Format("20060102_150405"), result.ChannelName, result.Task.RowIndex, result.Task.VideoPath)
+	}
+	// 执行多次的任务记录每次执行的结果，便于发现逐渐变差的稳定性
+	if len(result.History) > 1 {
+		logMessage += fmt.Sprintf("   执行记录: %s\n", result.attemptTrace())
 	}
-	logFile.WriteString(logMessage)
+	io.WriteString(logFile, logMessage)
 }